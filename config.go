@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/koshiq/ggpoker/p2p"
+	"gopkg.in/yaml.v3"
+)
+
+// gameConfig is the game section of config.yaml.
+type gameConfig struct {
+	SmallBlind    int `yaml:"small_blind"`
+	BigBlind      int `yaml:"big_blind"`
+	StartingStack int `yaml:"starting_stack"`
+	MinBuyIn      int `yaml:"min_buy_in"`
+	MaxBuyIn      int `yaml:"max_buy_in"`
+	Rake          struct {
+		BasisPoints  int         `yaml:"basis_points"`
+		Increment    int         `yaml:"increment"`
+		PotCap       int         `yaml:"pot_cap"`
+		Caps         map[int]int `yaml:"caps"`
+		NoFlopNoDrop bool        `yaml:"no_flop_no_drop"`
+	} `yaml:"rake"`
+	// HandTimeout, TimeBank and TimeBankRefill are in seconds.
+	HandTimeout    int `yaml:"hand_timeout"`
+	TimeBank       int `yaml:"time_bank"`
+	TimeBankRefill int `yaml:"time_bank_refill"`
+	// SitOutAfterTimeOuts sits players out after this many timeouts in a
+	// row, zero never does.
+	SitOutAfterTimeOuts int `yaml:"sit_out_after_time_outs"`
+	// Tournament makes the table a Sit & Go, without it a cash game is
+	// played.
+	Tournament *tournamentConfig `yaml:"tournament"`
+}
+
+// tournamentConfig is the Sit & Go section of the game. It starts once
+// every seat is taken.
+type tournamentConfig struct {
+	Seats         int   `yaml:"seats"`
+	StartingStack int   `yaml:"starting_stack"`
+	BuyIn         int   `yaml:"buy_in"`
+	Payouts       []int `yaml:"payouts"`
+	// LateRegistration and the durations of the levels are in seconds.
+	LateRegistration int `yaml:"late_registration"`
+	Levels           []struct {
+		SmallBlind int `yaml:"small_blind"`
+		BigBlind   int `yaml:"big_blind"`
+		Ante       int `yaml:"ante"`
+		Duration   int `yaml:"duration"`
+	} `yaml:"levels"`
+}
+
+// loadGameConfig reads the game settings from the config file at path. The
+// server defaults are used when there is no such file.
+func loadGameConfig(path string) (gameConfig, error) {
+	file := struct {
+		Game gameConfig `yaml:"game"`
+	}{}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return file.Game, nil
+	}
+	if err != nil {
+		return file.Game, err
+	}
+	if err := yaml.Unmarshal(b, &file); err != nil {
+		return file.Game, err
+	}
+
+	return file.Game, nil
+}
+
+// apply sets the game settings on the server config.
+func (c gameConfig) apply(cfg *p2p.ServerConfig) {
+	cfg.SmallBlind = c.SmallBlind
+	cfg.BigBlind = c.BigBlind
+	cfg.StartingStack = c.StartingStack
+	cfg.BuyIn = p2p.BuyInConfig{Min: c.MinBuyIn, Max: c.MaxBuyIn}
+	cfg.Rake = p2p.RakeConfig{
+		BasisPoints:  c.Rake.BasisPoints,
+		Increment:    c.Rake.Increment,
+		PotCap:       c.Rake.PotCap,
+		Caps:         c.Rake.Caps,
+		NoFlopNoDrop: c.Rake.NoFlopNoDrop,
+	}
+	cfg.Timers = p2p.TimerConfig{
+		ActionTimeout:       time.Duration(c.HandTimeout) * time.Second,
+		TimeBank:            time.Duration(c.TimeBank) * time.Second,
+		TimeBankRefill:      time.Duration(c.TimeBankRefill) * time.Second,
+		SitOutAfterTimeOuts: c.SitOutAfterTimeOuts,
+	}
+	if c.Tournament != nil {
+		cfg.Tournament = c.Tournament.tournament()
+	}
+}
+
+func (c tournamentConfig) tournament() *p2p.TournamentConfig {
+	levels := make([]p2p.BlindLevel, len(c.Levels))
+	for i, level := range c.Levels {
+		levels[i] = p2p.BlindLevel{
+			SmallBlind: level.SmallBlind,
+			BigBlind:   level.BigBlind,
+			Ante:       level.Ante,
+			Duration:   time.Duration(level.Duration) * time.Second,
+		}
+	}
+
+	return &p2p.TournamentConfig{
+		Seats:            c.Seats,
+		StartingStack:    c.StartingStack,
+		Levels:           levels,
+		BuyIn:            c.BuyIn,
+		Payouts:          c.Payouts,
+		StartWhenFull:    true,
+		LateRegistration: time.Duration(c.LateRegistration) * time.Second,
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/koshiq/ggpoker/p2p"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadGameConfig(t *testing.T) {
+	game, err := loadGameConfig("config.yaml")
+	assert.Nil(t, err)
+
+	var cfg p2p.ServerConfig
+	game.apply(&cfg)
+	assert.Equal(t, 10, cfg.SmallBlind)
+	assert.Equal(t, 20, cfg.BigBlind)
+	assert.Equal(t, 1000, cfg.StartingStack)
+	assert.Equal(t, p2p.BuyInConfig{Min: 400, Max: 2000}, cfg.BuyIn)
+	assert.Equal(t, 20, cfg.Rake.Increment)
+	assert.True(t, cfg.Rake.NoFlopNoDrop)
+	assert.Equal(t, p2p.TimerConfig{
+		ActionTimeout:       30 * time.Second,
+		TimeBank:            60 * time.Second,
+		TimeBankRefill:      5 * time.Second,
+		SitOutAfterTimeOuts: 2,
+	}, cfg.Timers)
+
+	// Without a config file the server defaults are used.
+	game, err = loadGameConfig(filepath.Join(t.TempDir(), "config.yaml"))
+	assert.Nil(t, err)
+	assert.Equal(t, gameConfig{}, game)
+}
+
+func TestLoadTournamentConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.Nil(t, os.WriteFile(path, []byte(`
+game:
+  tournament:
+    seats: 4
+    starting_stack: 1500
+    buy_in: 100
+    payouts: [65, 35]
+    late_registration: 60
+    levels:
+      - {small_blind: 10, big_blind: 20, duration: 300}
+      - {small_blind: 20, big_blind: 40, ante: 5}
+`), 0o644))
+
+	game, err := loadGameConfig(path)
+	assert.Nil(t, err)
+
+	var cfg p2p.ServerConfig
+	game.apply(&cfg)
+	assert.Equal(t, &p2p.TournamentConfig{
+		Seats:         4,
+		StartingStack: 1500,
+		Levels: []p2p.BlindLevel{
+			{SmallBlind: 10, BigBlind: 20, Duration: 5 * time.Minute},
+			{SmallBlind: 20, BigBlind: 40, Ante: 5},
+		},
+		BuyIn:            100,
+		Payouts:          []int{65, 35},
+		StartWhenFull:    true,
+		LateRegistration: time.Minute,
+	}, cfg.Tournament)
+
+	// The example config plays a cash game.
+	game, err = loadGameConfig("config.yaml")
+	assert.Nil(t, err)
+	assert.Nil(t, game.Tournament)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math/rand"
 	"strconv"
+	"strings"
 )
 
 type Suit int
@@ -36,6 +37,10 @@ type Card struct {
 }
 
 func (c Card) String() string {
+	if c.IsFaceDown() {
+		return "FACE DOWN"
+	}
+
 	value := strconv.Itoa(c.Value)
 	if c.Value == 1 {
 		value = "ACE"
@@ -44,6 +49,22 @@ func (c Card) String() string {
 	return fmt.Sprintf("%s of %s %s", value, c.Suit, suitToUnicode(c.Suit))
 }
 
+// FaceDown returns a stand-in for the card at position i of a deck of
+// which the cards are not known yet.
+func FaceDown(i int) Card {
+	return Card{Value: -(i + 1)}
+}
+
+// IsFaceDown reports whether the card is a stand-in returned by FaceDown.
+func (c Card) IsFaceDown() bool {
+	return c.Value < 0
+}
+
+// FaceDownPos returns the position in the deck of a face down card.
+func (c Card) FaceDownPos() int {
+	return -c.Value - 1
+}
+
 func NewCard(s Suit, v int) Card {
 	if v > 13 {
 		panic("the value of the card cannot be higher then 13")
@@ -58,6 +79,12 @@ func NewCard(s Suit, v int) Card {
 type Deck [52]Card
 
 func New() Deck {
+	return NewSeeded(rand.Int63())
+}
+
+// NewSeeded returns a deck shuffled with the given seed. The same seed always
+// results in the same order of cards.
+func NewSeeded(seed int64) Deck {
 	var (
 		nSuits = 4
 		nCards = 13
@@ -72,12 +99,12 @@ func New() Deck {
 		}
 	}
 
-	return shuffle(d)
+	return shuffle(d, rand.New(rand.NewSource(seed)))
 }
 
-func shuffle(d Deck) Deck {
+func shuffle(d Deck, rnd *rand.Rand) Deck {
 	for i := 0; i < len(d); i++ {
-		r := rand.Intn(i + 1)
+		r := rnd.Intn(i + 1)
 
 		if r != i {
 			d[i], d[r] = d[r], d[i]
@@ -101,3 +128,41 @@ func suitToUnicode(s Suit) string {
 		panic("invalid card suit")
 	}
 }
+
+const shortRanks = "A23456789TJQK"
+
+var shortSuits = map[Suit]byte{
+	Spades:   's',
+	Harts:    'h',
+	Diamonds: 'd',
+	Clubs:    'c',
+}
+
+// Short returns the two character notation of the card used in hand
+// histories, e.g. "As" or "Td".
+func (c Card) Short() string {
+	if c.IsFaceDown() {
+		return "??"
+	}
+	return string([]byte{shortRanks[c.Value-1], shortSuits[c.Suit]})
+}
+
+// ParseCard parses the two character notation returned by Short.
+func ParseCard(s string) (Card, error) {
+	if len(s) != 2 {
+		return Card{}, fmt.Errorf("invalid card %q", s)
+	}
+
+	value := strings.IndexByte(shortRanks, strings.ToUpper(s[:1])[0]) + 1
+	if value == 0 {
+		return Card{}, fmt.Errorf("invalid card rank %q", s)
+	}
+
+	for suit, b := range shortSuits {
+		if b == strings.ToLower(s[1:])[0] {
+			return NewCard(suit, value), nil
+		}
+	}
+
+	return Card{}, fmt.Errorf("invalid card suit %q", s)
+}
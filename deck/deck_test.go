@@ -0,0 +1,35 @@
+package deck
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewSeeded(t *testing.T) {
+	if !reflect.DeepEqual(NewSeeded(1), NewSeeded(1)) {
+		t.Errorf("decks with the same seed should be equal")
+	}
+	if reflect.DeepEqual(NewSeeded(1), NewSeeded(2)) {
+		t.Errorf("decks with a different seed should not be equal")
+	}
+}
+
+func TestCardShort(t *testing.T) {
+	d := New()
+	for _, card := range d {
+		parsed, err := ParseCard(card.Short())
+		if err != nil {
+			t.Errorf("parse error %s\n", err)
+		}
+		if parsed != card {
+			t.Errorf("got %+v but want %+v", parsed, card)
+		}
+	}
+
+	if s := NewCard(Harts, 10).Short(); s != "Th" {
+		t.Errorf("got %s but want Th", s)
+	}
+	if _, err := ParseCard("Xx"); err == nil {
+		t.Errorf("expected error parsing invalid card")
+	}
+}
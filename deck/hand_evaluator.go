@@ -75,9 +75,33 @@ func getBestFiveCardHand(cards []Card) []Card {
 		return cards
 	}
 
-	// For now, return first 5 cards
-	// TODO: Implement proper 5-card selection for 7-card hands
-	return cards[:5]
+	// Try every 5-card combination and keep the strongest one.
+	var (
+		best     []Card
+		bestHand Hand
+	)
+	combo := make([]Card, 5)
+	var pick func(start, depth int)
+	pick = func(start, depth int) {
+		if depth == 5 {
+			candidate := make([]Card, 5)
+			copy(candidate, combo)
+			rank, value := evaluateFiveCardHand(candidate)
+			hand := Hand{Cards: candidate, Rank: rank, Value: value}
+			if best == nil || CompareHands(hand, bestHand) > 0 {
+				best = candidate
+				bestHand = hand
+			}
+			return
+		}
+		for i := start; i <= len(cards)-(5-depth); i++ {
+			combo[depth] = cards[i]
+			pick(i+1, depth+1)
+		}
+	}
+	pick(0, 0)
+
+	return best
 }
 
 func evaluateFiveCardHand(cards []Card) (HandRank, int) {
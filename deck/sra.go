@@ -0,0 +1,105 @@
+package deck
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// The 2048-bit MODP group of RFC 3526. It is a safe prime p = 2q+1, so the
+// quadratic residues the cards are encoded as form a group of prime order q.
+const sraPrimeHex = `
+	FFFFFFFF FFFFFFFF C90FDAA2 2168C234 C4C6628B 80DC1CD1
+	29024E08 8A67CC74 020BBEA6 3B139B22 514A0879 8E3404DD
+	EF9519B3 CD3A431B 302B0A6D F25F1437 4FE1356D 6D51C245
+	E485B576 625E7EC6 F44C42E9 A637ED6B 0BFF5CB6 F406B7ED
+	EE386BFB 5A899FA5 AE9F2411 7C4B1FE6 49286651 ECE45B3D
+	C2007CB8 A163BF05 98DA4836 1C55D39A 69163FA8 FD24CF5F
+	83655D23 DCA3AD96 1C62F356 208552BB 9ED52907 7096966D
+	670C354E 4ABC9804 F1746C08 CA18217C 32905E46 2E36CE3B
+	E39E772C 180E8603 9B2783A2 EC07A28F B5C55DF0 6F4C52C9
+	DE2BCBF6 95581718 3995497C EA956AE5 15D22618 98FA0510
+	15728E5A 8AACAA68 FFFFFFFF FFFFFFFF`
+
+var (
+	sraPrime, _ = new(big.Int).SetString(strings.Join(strings.Fields(sraPrimeHex), ""), 16)
+	// sraOrder is the order of the group the keys are taken modulo.
+	sraOrder = new(big.Int).Sub(sraPrime, big.NewInt(1))
+
+	encodedCards = map[string]Card{}
+)
+
+func init() {
+	for _, card := range New() {
+		encodedCards[string(EncodeCard(card))] = card
+	}
+}
+
+// SRAKey is a key of the SRA commutative cipher used for mental poker.
+// Cards encrypted by several players can be decrypted in any order, so no
+// player has to see the deck in the clear.
+type SRAKey struct {
+	E *big.Int
+	D *big.Int
+}
+
+// NewSRAKey returns a random key.
+func NewSRAKey() (*SRAKey, error) {
+	one := big.NewInt(1)
+	for {
+		e, err := rand.Int(rand.Reader, sraOrder)
+		if err != nil {
+			return nil, err
+		}
+		if e.Cmp(one) <= 0 || new(big.Int).GCD(nil, nil, e, sraOrder).Cmp(one) != 0 {
+			continue
+		}
+
+		return &SRAKey{E: e, D: new(big.Int).ModInverse(e, sraOrder)}, nil
+	}
+}
+
+// Encrypt encrypts an encoded card or a card encrypted by other players.
+func (k *SRAKey) Encrypt(c []byte) []byte {
+	return SRAExp(c, k.E)
+}
+
+// Decrypt takes off the encryption of this key.
+func (k *SRAKey) Decrypt(c []byte) []byte {
+	return SRAExp(c, k.D)
+}
+
+// SRAExp raises c to the power x. Encrypting with several keys at once is
+// done with the product of their exponents, see SRAProduct.
+func SRAExp(c []byte, x *big.Int) []byte {
+	return new(big.Int).Exp(new(big.Int).SetBytes(c), x, sraPrime).Bytes()
+}
+
+// SRAProduct returns the exponent that encrypts or decrypts with all given
+// exponents at once.
+func SRAProduct(xs ...*big.Int) *big.Int {
+	product := big.NewInt(1)
+	for _, x := range xs {
+		product.Mul(product, x)
+		product.Mod(product, sraOrder)
+	}
+	return product
+}
+
+// EncodeCard returns the card as a quadratic residue. Encrypting a residue
+// gives a residue again, which keeps the cipher from leaking anything about
+// the cards.
+func EncodeCard(c Card) []byte {
+	m := big.NewInt(int64(int(c.Suit)*13 + c.Value + 1))
+	return m.Exp(m, big.NewInt(2), sraPrime).Bytes()
+}
+
+// DecodeCard returns the card of a fully decrypted value.
+func DecodeCard(b []byte) (Card, error) {
+	card, ok := encodedCards[string(new(big.Int).SetBytes(b).Bytes())]
+	if !ok {
+		return Card{}, fmt.Errorf("invalid encoded card")
+	}
+	return card, nil
+}
@@ -0,0 +1,41 @@
+package deck
+
+import (
+	"testing"
+)
+
+func TestSRACommutes(t *testing.T) {
+	if !sraPrime.ProbablyPrime(20) {
+		t.Fatalf("sra prime is not a prime")
+	}
+
+	a, err := NewSRAKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewSRAKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	card := NewCard(Clubs, 12)
+	enc := b.Encrypt(a.Encrypt(EncodeCard(card)))
+
+	// The keys come off in any order, or all at once.
+	for _, dec := range [][]byte{
+		b.Decrypt(a.Decrypt(enc)),
+		SRAExp(enc, SRAProduct(a.D, b.D)),
+	} {
+		got, err := DecodeCard(dec)
+		if err != nil {
+			t.Fatalf("decode error %s", err)
+		}
+		if got != card {
+			t.Errorf("got %+v but want %+v", got, card)
+		}
+	}
+
+	if _, err := DecodeCard(a.Decrypt(enc)); err == nil {
+		t.Errorf("expected error decoding a card that is still encrypted")
+	}
+}
@@ -1,32 +1,50 @@
 package main
 
 import (
+	"fmt"
+	"log"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/koshiq/ggpoker/p2p"
 )
 
-func makeServerAndStart(addr, apiAddr string) *p2p.Server {
+func makeServerAndStart(addr, apiAddr string, game gameConfig) *p2p.Server {
 	cfg := p2p.ServerConfig{
-		Version:       "GGPOKER V0.2-alpha",
-		ListenAddr:    addr,
-		APIListenAddr: apiAddr,
-		GameVariant:   p2p.TexasHoldem,
+		Version:        "GGPOKER V0.2-alpha",
+		ListenAddr:     addr,
+		APIListenAddr:  apiAddr,
+		GameVariant:    p2p.TexasHoldem,
+		HandHistoryDir: "logs/hands",
 	}
+	game.apply(&cfg)
 	server := p2p.NewServer(cfg)
-	go server.Start()
-
-	time.Sleep(time.Millisecond * 200)
+	if err := server.Start(); err != nil {
+		log.Fatal(err)
+	}
 
 	return server
 }
 
 func main() {
-	playerA := makeServerAndStart(":3000", ":3001") // dealer
-	playerB := makeServerAndStart(":4000", ":4001") // sb
-	playerC := makeServerAndStart(":5000", ":5001") // bb
-	playerD := makeServerAndStart(":7000", ":7001") // bb + 2
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		if err := runReplay(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	game, err := loadGameConfig("config.yaml")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	playerA := makeServerAndStart(":3000", ":3001", game) // dealer
+	playerB := makeServerAndStart(":4000", ":4001", game) // sb
+	playerC := makeServerAndStart(":5000", ":5001", game) // bb
+	playerD := makeServerAndStart(":7000", ":7001", game) // bb + 2
 
 	go func() {
 		time.Sleep(time.Second * 2)
@@ -96,14 +114,17 @@ func main() {
 
 	}()
 
-	time.Sleep(time.Millisecond * 200)
-	playerB.Connect(playerA.ListenAddr)
-
-	time.Sleep(time.Millisecond)
-	playerC.Connect(playerB.ListenAddr)
-
-	time.Sleep(time.Millisecond * 200)
-	playerD.Connect(playerC.ListenAddr)
+	// Connect returns once the handshake is done, the peer lists connect
+	// the rest of the table.
+	if err := playerB.Connect(playerA.ListenAddr); err != nil {
+		log.Fatal(err)
+	}
+	if err := playerC.Connect(playerB.ListenAddr); err != nil {
+		log.Fatal(err)
+	}
+	if err := playerD.Connect(playerC.ListenAddr); err != nil {
+		log.Fatal(err)
+	}
 
 	select {}
 }
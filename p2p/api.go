@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
 )
 
 type MyError struct {
@@ -33,35 +36,101 @@ func JSON(w http.ResponseWriter, status int, v any) error {
 	return json.NewEncoder(w).Encode(v)
 }
 
+func Text(w http.ResponseWriter, status int, text string) error {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	_, err := w.Write([]byte(text))
+	return err
+}
+
 type APIServer struct {
 	listenAddr string
 	game       *GameState
+	histories  *HandHistoryWriter
 	upgrader   websocket.Upgrader
+
+	clientsLock sync.Mutex
+	clients     map[*websocket.Conn]chan any
 }
 
-func NewAPIServer(listenAddr string, game *GameState) *APIServer {
+func NewAPIServer(listenAddr string, game *GameState, histories *HandHistoryWriter) *APIServer {
 	return &APIServer{
 		game:       game,
+		histories:  histories,
 		listenAddr: listenAddr,
 		upgrader:   websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }},
+		clients:    make(map[*websocket.Conn]chan any),
+	}
+}
+
+// Publish sends v as JSON to every connected websocket client. Clients that
+// can't keep up miss messages instead of blocking the caller.
+func (s *APIServer) Publish(v any) {
+	s.clientsLock.Lock()
+	defer s.clientsLock.Unlock()
+
+	for conn, ch := range s.clients {
+		select {
+		case ch <- v:
+		default:
+			logrus.WithField("client", conn.RemoteAddr()).Warn("websocket client too slow, dropping message")
+		}
 	}
 }
 
+// stateUpdate pushes a snapshot of the game to the websocket clients.
+type stateUpdate struct {
+	Type string       `json:"type"`
+	Data GameSnapshot `json:"data"`
+}
+
+// PublishState sends the snapshot of the player to every connected
+// websocket client.
+func (s *APIServer) PublishState(snapshot GameSnapshot) {
+	s.Publish(stateUpdate{Type: "game_state", Data: snapshot})
+}
+
 func (s *APIServer) Run() {
 	r := mux.NewRouter()
 
-	// Serve static files if web/dist exists
-	r.PathPrefix("/").Handler(http.FileServer(http.Dir("web/dist"))).Methods(http.MethodGet)
-
+	r.HandleFunc("/state", makeHTTPHandleFunc(s.handleGameState)).Methods(http.MethodGet)
 	r.HandleFunc("/ready", makeHTTPHandleFunc(s.handlePlayerReady))
+	r.HandleFunc("/sitout", makeHTTPHandleFunc(s.handlePlayerSitOut))
+	r.HandleFunc("/sitout/bb", makeHTTPHandleFunc(s.handlePlayerSitOut))
+	r.HandleFunc("/sitin", makeHTTPHandleFunc(s.handlePlayerSitIn))
+	r.HandleFunc("/sitin/bb", makeHTTPHandleFunc(s.handlePlayerSitIn))
+	r.HandleFunc("/rebuy/{value}", makeHTTPHandleFunc(s.handlePlayerAddChips))
+	r.HandleFunc("/topup/{value}", makeHTTPHandleFunc(s.handlePlayerAddChips))
 	r.HandleFunc("/fold", makeHTTPHandleFunc(s.handlePlayerFold))
 	r.HandleFunc("/check", makeHTTPHandleFunc(s.handlePlayerCheck))
+	r.HandleFunc("/call", makeHTTPHandleFunc(s.handlePlayerCall))
 	r.HandleFunc("/bet/{value}", makeHTTPHandleFunc(s.handlePlayerBet))
+	r.HandleFunc("/raise/{value}", makeHTTPHandleFunc(s.handlePlayerBet))
+	r.HandleFunc("/show", makeHTTPHandleFunc(s.handlePlayerShow))
+	r.HandleFunc("/muck", makeHTTPHandleFunc(s.handlePlayerShow))
+	r.HandleFunc("/history", makeHTTPHandleFunc(s.handleHandHistories)).Methods(http.MethodGet)
+	r.HandleFunc("/history/{hand}", makeHTTPHandleFunc(s.handleHandHistory)).Methods(http.MethodGet)
+	r.HandleFunc("/history/{hand}/ohh", makeHTTPHandleFunc(s.handleOpenHandHistory)).Methods(http.MethodGet)
+	r.HandleFunc("/ohh/replay", makeHTTPHandleFunc(s.handleReplayOpenHandHistory)).Methods(http.MethodPost)
+	r.HandleFunc("/deal", makeHTTPHandleFunc(s.handleDeal)).Methods(http.MethodGet)
+	r.HandleFunc("/deal/accept", makeHTTPHandleFunc(s.handleAcceptDeal)).Methods(http.MethodPost)
+	r.HandleFunc("/deal/reject", makeHTTPHandleFunc(s.handleRejectDeal)).Methods(http.MethodPost)
+	r.HandleFunc("/deal/{kind}", makeHTTPHandleFunc(s.handleProposeDeal)).Methods(http.MethodPost)
 	r.HandleFunc("/ws", s.handleWebSocket)
 
+	// Serve static files if web/dist exists. This needs to be registered
+	// last, otherwise it catches every GET request.
+	r.PathPrefix("/").Handler(http.FileServer(http.Dir("web/dist"))).Methods(http.MethodGet)
+
 	http.ListenAndServe(s.listenAddr, r)
 }
 
+// handleGameState returns the game as seen by the player, without the hole
+// cards of the others.
+func (s *APIServer) handleGameState(w http.ResponseWriter, r *http.Request) error {
+	return JSON(w, http.StatusOK, s.game.game.SnapshotFor(s.game.listenAddr))
+}
+
 func (s *APIServer) handlePlayerBet(w http.ResponseWriter, r *http.Request) error {
 	valueStr := mux.Vars(r)["value"]
 	value, err := strconv.Atoi(valueStr)
@@ -69,13 +138,38 @@ func (s *APIServer) handlePlayerBet(w http.ResponseWriter, r *http.Request) erro
 		return err
 	}
 
-	if err := s.game.TakeAction(PlayerActionBet, value); err != nil {
+	action := PlayerActionBet
+	if strings.HasPrefix(r.URL.Path, "/raise") {
+		action = PlayerActionRaise
+	}
+
+	if err := s.game.TakeAction(action, value); err != nil {
 		return err
 	}
 
 	return JSON(w, http.StatusOK, fmt.Sprintf("value:%d", value))
 }
 
+func (s *APIServer) handlePlayerCall(w http.ResponseWriter, r *http.Request) error {
+	if err := s.game.TakeAction(PlayerActionCall, 0); err != nil {
+		return err
+	}
+	return JSON(w, http.StatusOK, "CALLED")
+}
+
+// handlePlayerShow shows the hole cards at showdown, or mucks them on /muck.
+func (s *APIServer) handlePlayerShow(w http.ResponseWriter, r *http.Request) error {
+	muck := r.URL.Path == "/muck"
+	if err := s.game.ShowHand(muck); err != nil {
+		return err
+	}
+
+	if muck {
+		return JSON(w, http.StatusOK, "MUCKED")
+	}
+	return JSON(w, http.StatusOK, "SHOWN")
+}
+
 func (s *APIServer) handlePlayerCheck(w http.ResponseWriter, r *http.Request) error {
 	if err := s.game.TakeAction(PlayerActionCheck, 0); err != nil {
 		return err
@@ -95,6 +189,143 @@ func (s *APIServer) handlePlayerReady(w http.ResponseWriter, r *http.Request) er
 	return JSON(w, http.StatusOK, "READY")
 }
 
+// handlePlayerSitOut sits out after the current hand, or at the next big
+// blind on /sitout/bb.
+func (s *APIServer) handlePlayerSitOut(w http.ResponseWriter, r *http.Request) error {
+	nextBigBlind := strings.HasSuffix(r.URL.Path, "/bb")
+	if err := s.game.SitOut(nextBigBlind); err != nil {
+		return err
+	}
+
+	return JSON(w, http.StatusOK, "SITTING OUT")
+}
+
+// handlePlayerSitIn returns to play by posting a big blind, or by waiting
+// for the big blind on /sitin/bb.
+func (s *APIServer) handlePlayerSitIn(w http.ResponseWriter, r *http.Request) error {
+	waitForBigBlind := strings.HasSuffix(r.URL.Path, "/bb")
+	if err := s.game.SitIn(waitForBigBlind); err != nil {
+		return err
+	}
+
+	return JSON(w, http.StatusOK, "SITTING IN")
+}
+
+func (s *APIServer) handlePlayerAddChips(w http.ResponseWriter, r *http.Request) error {
+	value, err := strconv.Atoi(mux.Vars(r)["value"])
+	if err != nil {
+		return err
+	}
+
+	reason := LedgerTopUp
+	if strings.HasPrefix(r.URL.Path, "/rebuy") {
+		reason = LedgerRebuy
+	}
+
+	if err := s.game.AddChips(value, reason); err != nil {
+		return err
+	}
+
+	return JSON(w, http.StatusOK, fmt.Sprintf("%s:%d", reason, value))
+}
+
+// handleHandHistories returns the most recent hands of the table in the
+// PokerStars text format, ready to be imported by trackers.
+func (s *APIServer) handleHandHistories(w http.ResponseWriter, r *http.Request) error {
+	return Text(w, http.StatusOK, strings.Join(s.histories.Histories(), "\n\n"))
+}
+
+func (s *APIServer) handleHandHistory(w http.ResponseWriter, r *http.Request) error {
+	hand, err := strconv.Atoi(mux.Vars(r)["hand"])
+	if err != nil {
+		return err
+	}
+
+	text, ok := s.histories.History(hand)
+	if !ok {
+		return JSON(w, http.StatusNotFound, map[string]any{"error": fmt.Sprintf("hand %d not found", hand)})
+	}
+
+	return Text(w, http.StatusOK, text)
+}
+
+func (s *APIServer) handleOpenHandHistory(w http.ResponseWriter, r *http.Request) error {
+	hand, err := strconv.Atoi(mux.Vars(r)["hand"])
+	if err != nil {
+		return err
+	}
+
+	h, ok := s.histories.OpenHandHistory(hand)
+	if !ok {
+		return JSON(w, http.StatusNotFound, map[string]any{"error": fmt.Sprintf("hand %d not found", hand)})
+	}
+
+	return JSON(w, http.StatusOK, h)
+}
+
+// handleReplayOpenHandHistory plays the posted hands through the rules
+// engine and reports every difference with the original outcome.
+func (s *APIServer) handleReplayOpenHandHistory(w http.ResponseWriter, r *http.Request) error {
+	hands, err := ParseOpenHandHistories(r.Body)
+	if err != nil {
+		return JSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+	}
+
+	results := make([]map[string]any, 0, len(hands))
+	for _, h := range hands {
+		result := map[string]any{"gameNumber": h.OHH.GameNumber}
+		replay, err := ReplayOpenHandHistory(h)
+		if err != nil {
+			result["error"] = err.Error()
+		} else {
+			result["divergences"] = replay.Divergences
+		}
+		results = append(results, result)
+	}
+
+	return JSON(w, http.StatusOK, results)
+}
+
+func (s *APIServer) handleDeal(w http.ResponseWriter, r *http.Request) error {
+	if s.game.tournament == nil {
+		return fmt.Errorf("not playing a tournament")
+	}
+
+	deal := s.game.tournament.Deal()
+	if deal == nil {
+		return JSON(w, http.StatusNotFound, map[string]any{"error": "there is no deal"})
+	}
+
+	return JSON(w, http.StatusOK, deal)
+}
+
+// handleProposeDeal proposes a CHIP_CHOP or ICM deal to the players left.
+func (s *APIServer) handleProposeDeal(w http.ResponseWriter, r *http.Request) error {
+	deal, err := s.game.ProposeDeal(DealKind(strings.ToUpper(mux.Vars(r)["kind"])))
+	if err != nil {
+		return err
+	}
+	s.Publish(deal)
+
+	return JSON(w, http.StatusOK, deal)
+}
+
+func (s *APIServer) handleAcceptDeal(w http.ResponseWriter, r *http.Request) error {
+	if err := s.game.AnswerDeal(true); err != nil {
+		return err
+	}
+
+	return JSON(w, http.StatusOK, "DEAL ACCEPTED")
+}
+
+func (s *APIServer) handleRejectDeal(w http.ResponseWriter, r *http.Request) error {
+	if err := s.game.AnswerDeal(false); err != nil {
+		return err
+	}
+
+	return JSON(w, http.StatusOK, "DEAL REJECTED")
+}
+
 func (s *APIServer) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	conn, err := s.upgrader.Upgrade(w, r, nil)
 	if err != nil {
@@ -102,6 +333,36 @@ func (s *APIServer) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	}
 	defer conn.Close()
 
-	// TODO: Implement WebSocket game state updates
-	// For now, just close the connection
+	ch := make(chan any, 64)
+	s.clientsLock.Lock()
+	s.clients[conn] = ch
+	s.clientsLock.Unlock()
+
+	done := make(chan struct{})
+	defer func() {
+		s.clientsLock.Lock()
+		delete(s.clients, conn)
+		s.clientsLock.Unlock()
+	}()
+
+	// Clients don't send anything, reading only detects the close.
+	go func() {
+		defer close(done)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case v := <-ch:
+			if err := conn.WriteJSON(v); err != nil {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
 }
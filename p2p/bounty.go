@@ -0,0 +1,114 @@
+package p2p
+
+// BountyConfig puts a bounty on every player joining the table. In
+// progressive knockouts half of a bounty won is added to the bounty of the
+// winner instead of being paid out.
+type BountyConfig struct {
+	Amount      int
+	Progressive bool
+}
+
+// SetBounty sets the bounty of the players that join from now on.
+func (pg *PokerGame) SetBounty(cfg BountyConfig) {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	pg.bounty = cfg
+}
+
+// bountiesOf returns the bounties the player collected so far.
+func bountiesOf(game *PokerGame, addr string) int {
+	game.mu.RLock()
+	defer game.mu.RUnlock()
+
+	return game.players[addr].BountiesWon
+}
+
+// bountyOf returns the bounty on the head of the player.
+func bountyOf(game *PokerGame, addr string) int {
+	game.mu.RLock()
+	defer game.mu.RUnlock()
+
+	return game.players[addr].Bounty
+}
+
+// awardBounties gives the bounty of every player knocked out in this hand
+// to the players that won the chips. When side pots have different winners
+// the bounty is split in proportion to what each of them won of the pots
+// the knocked out player played for.
+func (pg *PokerGame) awardBounties() error {
+	won := pg.potAwards()
+	seats := pg.seatOrder()
+
+	for _, addr := range seats {
+		player := pg.players[addr]
+		if player.Stack > 0 || player.Bounty == 0 {
+			continue
+		}
+
+		shares := make(map[string]int)
+		winners := []string{}
+		total := 0
+		for i, pot := range pg.pot {
+			if !containsAddr(pot.Players, addr) {
+				continue
+			}
+			for _, winner := range seats {
+				if amount := won[i][winner]; amount > 0 {
+					if shares[winner] == 0 {
+						winners = append(winners, winner)
+					}
+					shares[winner] += amount
+					total += amount
+				}
+			}
+		}
+		if total == 0 {
+			continue
+		}
+
+		// The first winner gets what can't be split.
+		bounty := player.Bounty
+		amounts := make([]int, len(winners))
+		left := bounty
+		for i, winner := range winners {
+			amounts[i] = bounty * shares[winner] / total
+			left -= amounts[i]
+		}
+		amounts[0] += left
+
+		for i, winner := range winners {
+			ev := EventBountyWon{Addr: winner, From: addr, Cash: amounts[i]}
+			if pg.bounty.Progressive {
+				ev.Bounty = amounts[i] / 2
+				ev.Cash -= ev.Bounty
+			}
+			if err := pg.record(ev); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// potAwards returns what every player won of each pot in the current hand.
+func (pg *PokerGame) potAwards() []map[string]int {
+	won := make([]map[string]int, len(pg.pot))
+	for i := range won {
+		won[i] = make(map[string]int)
+	}
+
+	for i := len(pg.events) - 1; i >= 0; i-- {
+		switch e := pg.events[i].Event.(type) {
+		case EventHandStarted:
+			return won
+		case EventPotAwarded:
+			if e.Pot < len(won) {
+				won[e.Pot][e.Addr] += e.Amount
+			}
+		}
+	}
+
+	return won
+}
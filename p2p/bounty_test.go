@@ -0,0 +1,82 @@
+package p2p
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// knockOutWithSidePot knocks out :1 in a hand where :2 and :3 split the main
+// pot 2:1 and :3 wins the side pot.
+func knockOutWithSidePot(t *testing.T, cfg BountyConfig) *PokerGame {
+	game := NewPokerGame(10, 20)
+	game.SetBounty(cfg)
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, game.AddPlayer(fmt.Sprintf(":%d", i+1), 1000, i))
+	}
+	assert.Nil(t, game.StartNewHand())
+
+	game.players[":1"].Stack = 0
+	game.pot = []Pot{
+		{Amount: 300, Players: []string{":1", ":2", ":3"}},
+		{Amount: 200, Players: []string{":2", ":3"}},
+	}
+	assert.Nil(t, game.record(EventPotAwarded{Pot: 0, Addr: ":2", Amount: 200}))
+	assert.Nil(t, game.record(EventPotAwarded{Pot: 0, Addr: ":3", Amount: 100}))
+	assert.Nil(t, game.record(EventPotAwarded{Pot: 1, Addr: ":3", Amount: 200}))
+	assert.Nil(t, game.awardBounties())
+	assert.Nil(t, game.record(EventHandEnded{}))
+
+	return game
+}
+
+func TestBountySplitByMainPot(t *testing.T) {
+	game := knockOutWithSidePot(t, BountyConfig{Amount: 100})
+
+	// The side pot :1 couldn't win doesn't count.
+	assert.Equal(t, 0, game.players[":1"].Bounty)
+	assert.Equal(t, 67, game.players[":2"].BountiesWon)
+	assert.Equal(t, 33, game.players[":3"].BountiesWon)
+	assert.Equal(t, 100, game.players[":2"].Bounty)
+}
+
+func TestProgressiveBounty(t *testing.T) {
+	game := knockOutWithSidePot(t, BountyConfig{Amount: 100, Progressive: true})
+
+	assert.Equal(t, 34, game.players[":2"].BountiesWon)
+	assert.Equal(t, 133, game.players[":2"].Bounty)
+	assert.Equal(t, 17, game.players[":3"].BountiesWon)
+	assert.Equal(t, 116, game.players[":3"].Bounty)
+
+	text, err := game.HandHistory("ggpoker", 1, "")
+	assert.Nil(t, err)
+	assert.Contains(t, text, ":2 wins the 67 bounty for eliminating :1 and their own bounty increases by 33 to 133\n")
+}
+
+func TestTournamentBounties(t *testing.T) {
+	now := time.Now()
+	tournament := newTestTournament(t, TournamentConfig{
+		Seats:         2,
+		StartingStack: 100,
+		Levels:        []BlindLevel{{SmallBlind: 5, BigBlind: 10}},
+		BuyIn:         10,
+		Payouts:       []int{100},
+		StartWhenFull: true,
+		Bounty:        BountyConfig{Amount: 5, Progressive: true},
+	}, &now)
+	assert.Nil(t, tournament.Register(":1"))
+	assert.Nil(t, tournament.Register(":2"))
+
+	// Knock outs are counted when the next hand is dealt.
+	for tournament.Status() == TournamentRunning {
+		assert.Nil(t, tournament.NextHand())
+		shoveOrCall(t, tournament.Game())
+	}
+
+	// The winner collects both bounties, the player knocked out none.
+	standings := tournament.Standings()
+	assert.Equal(t, 10, standings[0].Bounties)
+	assert.Equal(t, 0, standings[1].Bounties)
+}
@@ -0,0 +1,150 @@
+package p2p
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// canonicalBytes encodes a protobuf message in a form that only depends on
+// the values of its fields, unlike the protobuf wire format that is allowed to
+// change between library versions. Set fields are written in the order of
+// their numbers, each as the field number followed by the value:
+//
+//   - integers and enums as 8 bytes big endian, bools as a single byte
+//   - floats as the 8 bytes of their IEEE 754 bits
+//   - strings, bytes and messages prefixed with their length as 4 bytes
+//   - lists as the number of elements followed by the elements
+//   - maps as the number of entries followed by the key and the value of
+//     each entry, ordered by the encoded key
+func canonicalBytes(m proto.Message) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := writeCanonicalMessage(buf, m.ProtoReflect()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeCanonicalMessage(buf *bytes.Buffer, m protoreflect.Message) error {
+	fields := m.Descriptor().Fields()
+	set := []protoreflect.FieldDescriptor{}
+	for i := 0; i < fields.Len(); i++ {
+		if fd := fields.Get(i); m.Has(fd) {
+			set = append(set, fd)
+		}
+	}
+	sort.Slice(set, func(i, j int) bool {
+		return set[i].Number() < set[j].Number()
+	})
+
+	for _, fd := range set {
+		writeUint32(buf, uint32(fd.Number()))
+
+		v := m.Get(fd)
+		switch {
+		case fd.IsList():
+			list := v.List()
+			writeUint32(buf, uint32(list.Len()))
+			for i := 0; i < list.Len(); i++ {
+				if err := writeCanonicalValue(buf, fd, list.Get(i)); err != nil {
+					return err
+				}
+			}
+		case fd.IsMap():
+			if err := writeCanonicalMap(buf, fd, v.Map()); err != nil {
+				return err
+			}
+		default:
+			if err := writeCanonicalValue(buf, fd, v); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func writeCanonicalMap(buf *bytes.Buffer, fd protoreflect.FieldDescriptor, m protoreflect.Map) error {
+	type entry struct {
+		key   []byte
+		value protoreflect.Value
+	}
+	entries := []entry{}
+
+	var err error
+	m.Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+		key := new(bytes.Buffer)
+		if err = writeCanonicalValue(key, fd.MapKey(), k.Value()); err != nil {
+			return false
+		}
+		entries = append(entries, entry{key: key.Bytes(), value: v})
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+
+	writeUint32(buf, uint32(len(entries)))
+	for _, e := range entries {
+		buf.Write(e.key)
+		if err := writeCanonicalValue(buf, fd.MapValue(), e.value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeCanonicalValue(buf *bytes.Buffer, fd protoreflect.FieldDescriptor, v protoreflect.Value) error {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		if v.Bool() {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+	case protoreflect.EnumKind:
+		writeUint64(buf, uint64(v.Enum()))
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		writeUint64(buf, uint64(v.Int()))
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		writeUint64(buf, v.Uint())
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		writeUint64(buf, math.Float64bits(v.Float()))
+	case protoreflect.StringKind:
+		writeUint32(buf, uint32(len(v.String())))
+		buf.WriteString(v.String())
+	case protoreflect.BytesKind:
+		writeUint32(buf, uint32(len(v.Bytes())))
+		buf.Write(v.Bytes())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		nested := new(bytes.Buffer)
+		if err := writeCanonicalMessage(nested, v.Message()); err != nil {
+			return err
+		}
+		writeUint32(buf, uint32(nested.Len()))
+		buf.Write(nested.Bytes())
+	default:
+		return fmt.Errorf("no canonical encoding of %s field %s", fd.Kind(), fd.FullName())
+	}
+
+	return nil
+}
+
+func writeUint32(buf *bytes.Buffer, v uint32) {
+	buf.Write(binary.BigEndian.AppendUint32(nil, v))
+}
+
+func writeUint64(buf *bytes.Buffer, v uint64) {
+	buf.Write(binary.BigEndian.AppendUint64(nil, v))
+}
@@ -0,0 +1,43 @@
+package p2p
+
+import (
+	"testing"
+
+	pb "github.com/koshiq/ggpoker/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalBytes(t *testing.T) {
+	env := &pb.Envelope{
+		From:    "ab",
+		Payload: &pb.Envelope_PeerList{PeerList: &pb.PeerList{Peers: []string{"c"}}},
+	}
+	b, err := canonicalBytes(env)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{
+		0, 0, 0, 1, 0, 0, 0, 2, 'a', 'b',
+		0, 0, 0, 3, 0, 0, 0, 13,
+		0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0, 1, 'c',
+	}, b)
+
+	// Map entries are ordered by key, whatever order they were added in.
+	keys := func(order ...int32) []byte {
+		m := map[int32][]byte{}
+		for _, k := range order {
+			m[k] = []byte{byte(k)}
+		}
+		b, err := canonicalBytes(&pb.CardKeys{HandNumber: 1, Keys: m})
+		assert.Nil(t, err)
+		return b
+	}
+	assert.Equal(t, keys(3, 1, 2), keys(1, 2, 3))
+	assert.Equal(t, keys(-1, 7), keys(7, -1))
+	assert.NotEqual(t, keys(1, 2), keys(1, 3))
+
+	// The value of a field can't be moved to another field.
+	a, err := canonicalBytes(&pb.HandAction{Addr: "x", Amount: 1})
+	assert.Nil(t, err)
+	b, err = canonicalBytes(&pb.HandAction{Addr: "x", Action: pb.Action(1)})
+	assert.Nil(t, err)
+	assert.NotEqual(t, a, b)
+}
@@ -0,0 +1,187 @@
+package p2p
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+)
+
+const (
+	// frameVersion is the version of the payloads we write. Frames of other
+	// versions are refused.
+	frameVersion = 3
+	// frameHeaderSize is the length prefix, the type tag and the version.
+	frameHeaderSize = 4 + 2 + 1
+	// defaultMaxFrameSize is the largest frame we accept from a peer.
+	defaultMaxFrameSize = 1 << 20
+)
+
+// messageTags are the type tags of the messages on the wire. A tag is never
+// reused, new messages get a new one.
+var messageTags = map[uint16]any{
+	1:  Handshake{},
+	2:  MessagePeerList{},
+	3:  MessageEncDeck{},
+	4:  MessageReady{},
+	5:  MessageSitOut{},
+	6:  MessageSitIn{},
+	7:  MessageChipsAdded{},
+	8:  MessagePreFlop{},
+	9:  MessagePlayerAction{},
+	10: MessageShowHand{},
+	11: MessageDealer{},
+	12: MessageCardKeys{},
+	13: MessagePlayerDisconnected{},
+	14: MessageStateSync{},
+	15: MessageTimerStarted{},
+	16: MessageTimerStopped{},
+	17: MessageDealProposed{},
+	18: MessageDealAnswer{},
+}
+
+var (
+	tagTypes = map[uint16]reflect.Type{}
+	typeTags = map[reflect.Type]uint16{}
+)
+
+func init() {
+	for tag, msg := range messageTags {
+		t := reflect.TypeOf(msg)
+		tagTypes[tag] = t
+		typeTags[t] = tag
+	}
+}
+
+// Codec reads and writes the messages of a single connection as frames of
+// a length prefix, the type tag of the message and the payload version,
+// followed by the sender, the signature, the hand, the sequence number and
+// the payload. The gob encoder and decoder live as long as the connection,
+// so type information is sent only once.
+type Codec struct {
+	rw           io.ReadWriter
+	maxFrameSize int
+
+	encLock sync.Mutex
+	encBuf  bytes.Buffer
+	enc     *gob.Encoder
+
+	// frame is the payload of the frame being decoded. It is a byte reader,
+	// so the decoder never reads beyond the frame.
+	frame *bytes.Reader
+	dec   *gob.Decoder
+}
+
+func NewCodec(rw io.ReadWriter, maxFrameSize int) *Codec {
+	c := &Codec{
+		rw:           rw,
+		maxFrameSize: maxFrameSize,
+		frame:        bytes.NewReader(nil),
+	}
+	c.enc = gob.NewEncoder(&c.encBuf)
+	c.dec = gob.NewDecoder(c.frame)
+
+	return c
+}
+
+// Encode writes the message as a single frame. The encoder may have sent
+// type information before an error, so the connection has to be closed.
+func (c *Codec) Encode(msg *Message) error {
+	tag, ok := typeTags[reflect.TypeOf(msg.Payload)]
+	if !ok {
+		return fmt.Errorf("no type tag for message %T", msg.Payload)
+	}
+
+	c.encLock.Lock()
+	defer c.encLock.Unlock()
+
+	c.encBuf.Reset()
+	c.encBuf.Write(make([]byte, frameHeaderSize))
+	if err := c.enc.Encode(msg.From); err != nil {
+		return err
+	}
+	if err := c.enc.Encode(msg.Signature); err != nil {
+		return err
+	}
+	if err := c.enc.Encode(msg.Hand); err != nil {
+		return err
+	}
+	if err := c.enc.Encode(msg.Seq); err != nil {
+		return err
+	}
+	if err := c.enc.Encode(msg.Payload); err != nil {
+		return err
+	}
+
+	frame := c.encBuf.Bytes()
+	if len(frame)-4 > c.maxFrameSize {
+		return fmt.Errorf("frame of %d bytes exceeds the maximum of %d", len(frame)-4, c.maxFrameSize)
+	}
+	binary.BigEndian.PutUint32(frame[0:4], uint32(len(frame)-4))
+	binary.BigEndian.PutUint16(frame[4:6], tag)
+	frame[6] = frameVersion
+
+	_, err := c.rw.Write(frame)
+	return err
+}
+
+// Decode reads the next frame. Frames larger than the maximum frame size are
+// refused before anything is allocated for them.
+func (c *Codec) Decode() (*Message, error) {
+	header := make([]byte, frameHeaderSize)
+	if _, err := io.ReadFull(c.rw, header); err != nil {
+		return nil, err
+	}
+
+	size := int(binary.BigEndian.Uint32(header[0:4]))
+	if size > c.maxFrameSize {
+		return nil, fmt.Errorf("frame of %d bytes exceeds the maximum of %d", size, c.maxFrameSize)
+	}
+	if size < frameHeaderSize-4 {
+		return nil, fmt.Errorf("invalid frame of %d bytes", size)
+	}
+	tag := binary.BigEndian.Uint16(header[4:6])
+	t, ok := tagTypes[tag]
+	if !ok {
+		return nil, fmt.Errorf("unknown message type tag %d", tag)
+	}
+	if version := header[6]; version != frameVersion {
+		return nil, fmt.Errorf("unsupported version %d of message type tag %d", version, tag)
+	}
+
+	payload := make([]byte, size-(frameHeaderSize-4))
+	if _, err := io.ReadFull(c.rw, payload); err != nil {
+		return nil, err
+	}
+	c.frame.Reset(payload)
+
+	msg := new(Message)
+	if err := c.dec.Decode(&msg.From); err != nil {
+		return nil, err
+	}
+	if err := c.dec.Decode(&msg.Signature); err != nil {
+		return nil, err
+	}
+	if len(msg.Signature) == 0 {
+		msg.Signature = nil
+	}
+	if err := c.dec.Decode(&msg.Hand); err != nil {
+		return nil, err
+	}
+	if err := c.dec.Decode(&msg.Seq); err != nil {
+		return nil, err
+	}
+	v := reflect.New(t)
+	if err := c.dec.Decode(v.Interface()); err != nil {
+		return nil, err
+	}
+	if c.frame.Len() > 0 {
+		return nil, fmt.Errorf("%d bytes left in frame of message type tag %d", c.frame.Len(), tag)
+	}
+	msg.Payload = v.Elem().Interface()
+
+	return msg, nil
+}
@@ -0,0 +1,62 @@
+package p2p
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	buf := new(bytes.Buffer)
+	enc := NewCodec(buf, defaultMaxFrameSize)
+	dec := NewCodec(buf, defaultMaxFrameSize)
+
+	msgs := []*Message{
+		NewMessage(":3000", Handshake{Version: "v1", ListenAddr: ":3000"}),
+		NewMessage(":3000", MessageReady{}),
+		NewMessage(":3000", MessagePlayerAction{Action: PlayerActionRaise, Value: 40}),
+		NewMessage(":3000", MessagePlayerAction{Action: PlayerActionCall}),
+		NewMessage(":4000", MessageCardKeys{HandNumber: 2, Keys: map[int][]byte{7: {1, 2, 3}}}),
+		{From: ":4000", Payload: MessageReady{}, Signature: []byte{4, 5, 6}, Hand: 2, Seq: 7},
+	}
+	sizes := []int{}
+	for _, msg := range msgs {
+		before := buf.Len()
+		assert.Nil(t, enc.Encode(msg))
+		sizes = append(sizes, buf.Len()-before)
+	}
+
+	// Type information is only sent with the first message of a type.
+	assert.Less(t, sizes[3], sizes[2])
+
+	for _, msg := range msgs {
+		got, err := dec.Decode()
+		assert.Nil(t, err)
+		assert.Equal(t, msg, got)
+	}
+}
+
+func TestCodecRefusesBadFrames(t *testing.T) {
+	frame := func(size uint32, tag uint16, version byte) *bytes.Buffer {
+		header := make([]byte, frameHeaderSize)
+		binary.BigEndian.PutUint32(header[0:4], size)
+		binary.BigEndian.PutUint16(header[4:6], tag)
+		header[6] = version
+		return bytes.NewBuffer(header)
+	}
+
+	_, err := NewCodec(frame(1<<31, 1, frameVersion), 1024).Decode()
+	assert.ErrorContains(t, err, "exceeds the maximum")
+
+	_, err = NewCodec(frame(3, 999, frameVersion), 1024).Decode()
+	assert.ErrorContains(t, err, "unknown message type tag")
+
+	_, err = NewCodec(frame(3, 1, frameVersion+1), 1024).Decode()
+	assert.ErrorContains(t, err, "unsupported version")
+
+	small := NewCodec(new(bytes.Buffer), 16)
+	assert.NotNil(t, small.Encode(NewMessage(":3000", MessagePreFlop{Deck: [][]byte{make([]byte, 64)}})))
+	assert.NotNil(t, small.Encode(NewMessage(":3000", "not a message")))
+}
@@ -0,0 +1,70 @@
+package p2p
+
+import "fmt"
+
+// Disconnect handles a player that lost the connection. Players that are
+// not dealt in leave right away. Otherwise the hand is folded as soon as it
+// is the player's turn and the player leaves once the hand is over.
+func (pg *PokerGame) Disconnect(addr string) error {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	player, ok := pg.players[addr]
+	if !ok {
+		return fmt.Errorf("player %s not found", addr)
+	}
+	if player.Disconnected {
+		return fmt.Errorf("player %s is already disconnected", addr)
+	}
+
+	if !pg.gameStarted || player.SittingOut {
+		return pg.record(EventPlayerLeft{Addr: addr})
+	}
+
+	if err := pg.record(EventPlayerDisconnected{Addr: addr}); err != nil {
+		return err
+	}
+
+	return pg.startTimer()
+}
+
+// actForDisconnected acts on behalf of disconnected players that are on the
+// move. They fold, or at showdown muck a beaten hand and show otherwise. A
+// hand only the player knows can't be turned up anymore and is mucked. Once
+// the hand is over they leave the table.
+func (pg *PokerGame) actForDisconnected() error {
+	for pg.gameStarted && pg.actionOn != "" {
+		addr := pg.actionOn
+		if !pg.players[addr].Disconnected {
+			return nil
+		}
+
+		var err error
+		switch {
+		case pg.currentRound == Showdown && pg.mayMuck(addr):
+			err = pg.muckHand(addr)
+		case pg.currentRound == Showdown:
+			err = pg.showHand(addr)
+		default:
+			err = pg.playerAction(addr, PlayerActionFold, 0)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if pg.gameStarted {
+		return nil
+	}
+
+	for _, addr := range pg.allSeats() {
+		if !pg.players[addr].Disconnected {
+			continue
+		}
+		if err := pg.record(EventPlayerLeft{Addr: addr}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
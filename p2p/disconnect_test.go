@@ -0,0 +1,53 @@
+package p2p
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisconnectFoldsOnTurn(t *testing.T) {
+	// [:1] [:2 D] [:3 SB] [:4 BB]
+	game := newTestGame(t, 4)
+	assert.Nil(t, game.PlayerAction(":1", PlayerActionCall, 0))
+
+	// :3 keeps the hand until it is their turn.
+	assert.Nil(t, game.Disconnect(":3"))
+	assert.False(t, game.players[":3"].Folded)
+	assert.NotNil(t, game.Disconnect(":3"))
+
+	assert.Nil(t, game.PlayerAction(":2", PlayerActionCall, 0))
+	assert.True(t, game.players[":3"].Folded)
+	assert.Equal(t, ":4", game.actionOn)
+
+	// :3 leaves once the hand is over.
+	foldToBigBlind(t, game)
+	_, ok := game.players[":3"]
+	assert.False(t, ok)
+
+	text, err := game.HandHistory("ggpoker", 1, "")
+	assert.Nil(t, err)
+	assert.Contains(t, text, ":3 is disconnected\n:2: calls 20\n:3: folds\n")
+
+	// Between hands players leave right away.
+	assert.Nil(t, game.Disconnect(":1"))
+	assert.Equal(t, []string{":2", ":4"}, game.seatOrder())
+}
+
+func TestDisconnectAtShowdown(t *testing.T) {
+	game := newTestGame(t, 2)
+	assert.Nil(t, game.PlayerAction(game.actionOn, PlayerActionCall, 0))
+	for game.gameStarted && game.currentRound != Showdown {
+		assert.Nil(t, game.PlayerAction(game.actionOn, PlayerActionCheck, 0))
+	}
+
+	// The first hand at showdown can't be beaten yet, so it is shown.
+	first := game.actionOn
+	assert.Nil(t, game.Disconnect(first))
+	assert.NotEmpty(t, game.players[first].ShownCards)
+	assert.NotEqual(t, first, game.actionOn)
+
+	assert.Nil(t, game.ShowHand(game.actionOn))
+	assert.False(t, game.gameStarted)
+	assert.Len(t, game.players, 1)
+}
@@ -0,0 +1,537 @@
+package p2p
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/koshiq/ggpoker/deck"
+)
+
+// GameEvent is a single state change of a PokerGame. Every change goes
+// through the event log, so the state of a game can always be rebuilt by
+// applying its events in order.
+type GameEvent interface {
+	EventType() string
+}
+
+type EventPlayerJoined struct {
+	Addr     string
+	Stack    int
+	Position int
+	TimeBank time.Duration
+	// Bounty and BountiesWon are carried over when a tournament moves the
+	// player to another table.
+	Bounty      int
+	BountiesWon int
+	// WaitForBigBlind is set for players joining during a hand.
+	WaitForBigBlind bool
+}
+
+func (EventPlayerJoined) EventType() string { return "player_joined" }
+
+type EventPlayerLeft struct {
+	Addr string
+}
+
+func (EventPlayerLeft) EventType() string { return "player_left" }
+
+// EventPlayerDisconnected is a player dealt in that lost the connection
+// during a hand. The hand is folded and the player leaves once it is over.
+type EventPlayerDisconnected struct {
+	Addr string
+}
+
+func (EventPlayerDisconnected) EventType() string { return "player_disconnected" }
+
+type EventHandStarted struct {
+	HandNumber int
+	DealerPos  int
+	// Seed is the seed the deck of this hand is shuffled with.
+	Seed int64
+	// Deck is set when the hand is dealt from a known deck instead.
+	Deck       []deck.Card
+	SmallBlind int
+	BigBlind   int
+	Ante       int
+}
+
+func (EventHandStarted) EventType() string { return "hand_started" }
+
+type EventAntePosted struct {
+	Addr   string
+	Amount int
+}
+
+func (EventAntePosted) EventType() string { return "ante_posted" }
+
+type EventBlindPosted struct {
+	Addr   string
+	Amount int
+	// Extra is set for a big blind posted out of position by a player
+	// returning to play.
+	Extra bool
+}
+
+func (EventBlindPosted) EventType() string { return "blind_posted" }
+
+type EventCardsDealt struct {
+	Addr  string
+	Cards []deck.Card
+}
+
+func (EventCardsDealt) EventType() string { return "cards_dealt" }
+
+type EventActionTaken struct {
+	Addr   string
+	Action PlayerAction
+	// Amount is the amount as passed to PlayerAction.
+	Amount int
+}
+
+func (EventActionTaken) EventType() string { return "action_taken" }
+
+type EventStreetDealt struct {
+	Round BettingRound
+	Cards []deck.Card
+}
+
+func (EventStreetDealt) EventType() string { return "street_dealt" }
+
+// EventCardsRevealed turns cards dealt face down up, by their position in
+// the deck of the hand.
+type EventCardsRevealed struct {
+	Cards map[int]deck.Card
+}
+
+func (EventCardsRevealed) EventType() string { return "cards_revealed" }
+
+// EventRevealPending is recorded when the showdown waits for cards dealt
+// face down.
+type EventRevealPending struct {
+	AllIn bool
+}
+
+func (EventRevealPending) EventType() string { return "reveal_pending" }
+
+type EventShowdownStarted struct {
+	Order []string
+	// AllIn is set when the board was run out with players all-in, the
+	// hands are then shown without any action of the players.
+	AllIn bool
+}
+
+func (EventShowdownStarted) EventType() string { return "showdown_started" }
+
+type EventCardsShown struct {
+	Addr  string
+	Cards []deck.Card
+}
+
+func (EventCardsShown) EventType() string { return "cards_shown" }
+
+type EventHandMucked struct {
+	Addr string
+}
+
+func (EventHandMucked) EventType() string { return "hand_mucked" }
+
+type EventPotAwarded struct {
+	Pot    int
+	Addr   string
+	Amount int
+}
+
+func (EventPotAwarded) EventType() string { return "pot_awarded" }
+
+type EventHandEnded struct {
+	// FoldWinner is set when the hand was won without a showdown.
+	FoldWinner string
+}
+
+func (EventHandEnded) EventType() string { return "hand_ended" }
+
+type EventTimerStarted struct {
+	Addr    string
+	Started time.Time
+	// Timeout is the time to act before the time bank is used.
+	Timeout  time.Duration
+	TimeBank time.Duration
+}
+
+func (EventTimerStarted) EventType() string { return "timer_started" }
+
+type EventTimerStopped struct {
+	Addr         string
+	TimeBankUsed time.Duration
+	// TimedOut is set when the player did not act in time and the engine
+	// acted on behalf of the player.
+	TimedOut bool
+}
+
+func (EventTimerStopped) EventType() string { return "timer_stopped" }
+
+type EventTimeBanksRefilled struct {
+	Amount time.Duration
+	Max    time.Duration
+}
+
+func (EventTimeBanksRefilled) EventType() string { return "time_banks_refilled" }
+
+type EventSitOutRequested struct {
+	Addr string
+	// NextBigBlind is set to sit out at the next big blind instead of the
+	// next hand.
+	NextBigBlind bool
+}
+
+func (EventSitOutRequested) EventType() string { return "sit_out_requested" }
+
+type EventSitInRequested struct {
+	Addr            string
+	WaitForBigBlind bool
+}
+
+func (EventSitInRequested) EventType() string { return "sit_in_requested" }
+
+type EventSatOut struct {
+	Addr string
+}
+
+func (EventSatOut) EventType() string { return "sat_out" }
+
+type EventSatIn struct {
+	Addr string
+	// PostBigBlind is set when the player has to post a big blind to be
+	// dealt in.
+	PostBigBlind bool
+}
+
+func (EventSatIn) EventType() string { return "sat_in" }
+
+// EventChipsAdded is a rebuy or top-up of a player between hands.
+type EventChipsAdded struct {
+	Addr   string
+	Amount int
+	Reason LedgerReason
+}
+
+func (EventChipsAdded) EventType() string { return "chips_added" }
+
+type EventRakeTaken struct {
+	Pot    int
+	Amount int
+}
+
+func (EventRakeTaken) EventType() string { return "rake_taken" }
+
+// EventBountyWon is the bounty of a knocked out player, or a share of it,
+// won by another player. Cash is paid out, Bounty is added to the bounty of
+// the winner in progressive knockouts.
+type EventBountyWon struct {
+	Addr   string
+	From   string
+	Cash   int
+	Bounty int
+}
+
+func (EventBountyWon) EventType() string { return "bounty_won" }
+
+// EventRecord is an entry of the append-only event log of a PokerGame.
+type EventRecord struct {
+	Seq        uint64
+	HandNumber int
+	Time       time.Time
+	Event      GameEvent
+}
+
+func (r EventRecord) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Seq        uint64    `json:"seq"`
+		HandNumber int       `json:"handNumber"`
+		Time       time.Time `json:"time"`
+		Type       string    `json:"type"`
+		Event      GameEvent `json:"event"`
+	}{
+		Seq:        r.Seq,
+		HandNumber: r.HandNumber,
+		Time:       r.Time,
+		Type:       r.Event.EventType(),
+		Event:      r.Event,
+	})
+}
+
+// EventHandler gets called for every event appended to the log. Handlers are
+// called while the game is locked and must not call back into the game.
+type EventHandler func(EventRecord)
+
+// Subscribe registers a handler for all future events of the game. The
+// returned function removes the handler again.
+func (pg *PokerGame) Subscribe(h EventHandler) func() {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	id := pg.nextHandlerID
+	pg.nextHandlerID++
+	pg.handlers[id] = h
+
+	return func() {
+		pg.mu.Lock()
+		defer pg.mu.Unlock()
+
+		delete(pg.handlers, id)
+	}
+}
+
+// Events returns a copy of the event log.
+func (pg *PokerGame) Events() []EventRecord {
+	pg.mu.RLock()
+	defer pg.mu.RUnlock()
+
+	return append([]EventRecord{}, pg.events...)
+}
+
+// EventsSince returns all events with a sequence number higher than seq.
+func (pg *PokerGame) EventsSince(seq uint64) []EventRecord {
+	pg.mu.RLock()
+	defer pg.mu.RUnlock()
+
+	if seq >= uint64(len(pg.events)) {
+		return []EventRecord{}
+	}
+
+	return append([]EventRecord{}, pg.events[seq:]...)
+}
+
+// RebuildPokerGame creates a new game by folding the given events.
+func RebuildPokerGame(smallBlind, bigBlind int, records []EventRecord) (*PokerGame, error) {
+	pg := NewPokerGame(smallBlind, bigBlind)
+
+	for i, r := range records {
+		if r.Seq != uint64(i+1) {
+			return nil, fmt.Errorf("event log gap: expected seq %d got %d", i+1, r.Seq)
+		}
+		if err := pg.apply(r.Event); err != nil {
+			return nil, err
+		}
+		pg.events = append(pg.events, r)
+	}
+
+	return pg, nil
+}
+
+// record applies the event to the game, appends it to the log and notifies
+// all subscribers.
+func (pg *PokerGame) record(ev GameEvent) error {
+	if err := pg.apply(ev); err != nil {
+		return err
+	}
+
+	r := EventRecord{
+		Seq:        uint64(len(pg.events) + 1),
+		HandNumber: pg.handNumber,
+		Time:       time.Now(),
+		Event:      ev,
+	}
+	pg.events = append(pg.events, r)
+
+	for _, h := range pg.handlers {
+		h(r)
+	}
+
+	return nil
+}
+
+func (pg *PokerGame) apply(ev GameEvent) error {
+	switch e := ev.(type) {
+	case EventPlayerJoined:
+		pg.players[e.Addr] = &PlayerState{
+			Addr:        e.Addr,
+			Stack:       e.Stack,
+			Position:    e.Position,
+			HoleCards:   make([]deck.Card, 0),
+			LastAction:  PlayerActionNone,
+			TimeBank:    e.TimeBank,
+			Bounty:      e.Bounty,
+			BountiesWon: e.BountiesWon,
+		}
+		if e.WaitForBigBlind {
+			pg.players[e.Addr].SittingOut = true
+			pg.players[e.Addr].WaitForBigBlind = true
+		}
+
+	case EventPlayerLeft:
+		delete(pg.players, e.Addr)
+
+	case EventPlayerDisconnected:
+		pg.players[e.Addr].Disconnected = true
+
+	case EventHandStarted:
+		// Logs of older versions don't have the blinds of the hand.
+		if e.BigBlind > 0 {
+			pg.smallBlind, pg.bigBlind, pg.ante = e.SmallBlind, e.BigBlind, e.Ante
+		}
+		pg.resetHand()
+		if e.Deck != nil {
+			pg.deck = append([]deck.Card{}, e.Deck...)
+		} else {
+			deckArray := deck.NewSeeded(e.Seed)
+			pg.deck = deckArray[:]
+		}
+		pg.dealerPos = e.DealerPos
+		pg.buttonSeat = pg.players[pg.seatOrder()[e.DealerPos]].Position
+		pg.markButton()
+		pg.gameStarted = true
+		pg.handNumber = e.HandNumber
+
+	case EventAntePosted:
+		player := pg.players[e.Addr]
+		player.Stack -= e.Amount
+		player.TotalBet += e.Amount
+		if player.Stack == 0 {
+			player.AllIn = true
+		}
+
+	case EventBlindPosted:
+		player := pg.players[e.Addr]
+		pg.commitChips(player, e.Amount)
+		pg.currentBet = max(pg.currentBet, player.Bet)
+		pg.minRaise = pg.bigBlind
+		player.PostBigBlind = false
+		if !e.Extra {
+			pg.actionOn = pg.nextToAct(pg.seatIndex(e.Addr))
+		}
+
+	case EventCardsDealt:
+		player := pg.players[e.Addr]
+		player.HoleCards = append(player.HoleCards, e.Cards...)
+		pg.removeFromDeck(e.Cards)
+
+	case EventActionTaken:
+		pg.applyAction(e)
+
+	case EventStreetDealt:
+		pg.collectBets()
+		pg.removeFromDeck(e.Cards)
+		pg.communityCards = append(pg.communityCards, e.Cards...)
+		pg.currentRound = e.Round
+		pg.resetBettingRound()
+		pg.actionOn = ""
+		if pg.countCanAct() > 1 {
+			pg.actionOn = pg.nextToAct(pg.dealerPos)
+		}
+
+	case EventCardsRevealed:
+		pg.revealCards(e.Cards)
+
+	case EventRevealPending:
+		pending := e
+		pg.revealPending = &pending
+
+	case EventShowdownStarted:
+		pg.revealPending = nil
+		pg.collectBets()
+		pg.currentRound = Showdown
+		pg.showdownOrder = append([]string{}, e.Order...)
+		pg.showdownPos = 0
+		pg.actionOn = pg.showdownOrder[0]
+
+	case EventCardsShown:
+		player := pg.players[e.Addr]
+		for _, card := range e.Cards {
+			if !containsCard(player.ShownCards, card) {
+				player.ShownCards = append(player.ShownCards, card)
+			}
+		}
+		pg.advanceShowdown(e.Addr)
+
+	case EventHandMucked:
+		pg.players[e.Addr].Mucked = true
+		pg.advanceShowdown(e.Addr)
+
+	case EventPotAwarded:
+		pg.players[e.Addr].Stack += e.Amount
+
+	case EventHandEnded:
+		pg.gameStarted = false
+		pg.actionOn = ""
+		pg.foldWinner = e.FoldWinner
+
+	case EventTimerStarted:
+		pg.actionStarted = e.Started
+		pg.actionTimeout = e.Timeout
+
+	case EventTimerStopped:
+		player := pg.players[e.Addr]
+		player.TimeBank = max(player.TimeBank-e.TimeBankUsed, 0)
+		if e.TimedOut {
+			player.TimeOuts++
+		} else {
+			player.TimeOuts = 0
+		}
+		pg.actionStarted = time.Time{}
+		pg.actionTimeout = 0
+
+	case EventSitOutRequested:
+		player := pg.players[e.Addr]
+		player.SitOutNextHand = !e.NextBigBlind
+		player.SitOutNextBigBlind = e.NextBigBlind
+		player.SitInNextHand = false
+		player.WaitForBigBlind = false
+
+	case EventSitInRequested:
+		player := pg.players[e.Addr]
+		player.SitOutNextHand = false
+		player.SitOutNextBigBlind = false
+		if player.SittingOut {
+			player.SitInNextHand = !e.WaitForBigBlind
+			player.WaitForBigBlind = e.WaitForBigBlind
+		}
+
+	case EventSatOut:
+		player := pg.players[e.Addr]
+		player.SittingOut = true
+		player.SitOutNextHand = false
+		player.SitOutNextBigBlind = false
+		player.SitInNextHand = false
+		player.WaitForBigBlind = false
+		player.PostBigBlind = false
+		player.SatOutHand = pg.handNumber + 1
+
+	case EventSatIn:
+		player := pg.players[e.Addr]
+		player.SittingOut = false
+		player.SitInNextHand = false
+		player.WaitForBigBlind = false
+		player.PostBigBlind = e.PostBigBlind
+		player.TimeOuts = 0
+
+	case EventRakeTaken:
+		for len(pg.potRake) <= e.Pot {
+			pg.potRake = append(pg.potRake, 0)
+		}
+		pg.potRake[e.Pot] += e.Amount
+		pg.pot[e.Pot].Amount -= e.Amount
+
+	case EventBountyWon:
+		pg.players[e.From].Bounty -= e.Cash + e.Bounty
+		pg.players[e.Addr].Bounty += e.Bounty
+		pg.players[e.Addr].BountiesWon += e.Cash
+
+	case EventChipsAdded:
+		pg.players[e.Addr].Stack += e.Amount
+
+	case EventTimeBanksRefilled:
+		for _, player := range pg.players {
+			player.TimeBank += e.Amount
+			if player.TimeBank > e.Max {
+				player.TimeBank = e.Max
+			}
+		}
+
+	default:
+		return fmt.Errorf("unknown game event %T", ev)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package p2p
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPokerGameEventLog(t *testing.T) {
+	game := NewPokerGame(10, 20)
+
+	received := []EventRecord{}
+	unsubscribe := game.Subscribe(func(r EventRecord) {
+		received = append(received, r)
+	})
+
+	assert.Nil(t, game.AddPlayer(":1", 1000, 0))
+	assert.Nil(t, game.AddPlayer(":2", 1000, 1))
+	assert.Nil(t, game.AddPlayer(":3", 1000, 2))
+	assert.Nil(t, game.StartNewHandWithSeed(42))
+
+	events := game.Events()
+	assert.Equal(t, events, received)
+	for i, r := range events {
+		assert.Equal(t, uint64(i+1), r.Seq)
+	}
+
+	assert.IsType(t, EventPlayerJoined{}, events[0].Event)
+	assert.Equal(t, EventHandStarted{HandNumber: 1, DealerPos: 1, Seed: 42, SmallBlind: 10, BigBlind: 20}, events[3].Event)
+	assert.Equal(t, EventBlindPosted{Addr: ":3", Amount: 10}, events[4].Event)
+	assert.Equal(t, EventBlindPosted{Addr: ":1", Amount: 20}, events[5].Event)
+	assert.IsType(t, EventCardsDealt{}, events[6].Event)
+
+	assert.Nil(t, game.PlayerAction(":2", PlayerActionRaise, 40))
+	assert.Nil(t, game.PlayerAction(":3", PlayerActionCall, 0))
+	assert.Nil(t, game.PlayerAction(":1", PlayerActionFold, 0))
+
+	last := game.EventsSince(uint64(len(events)))
+	assert.Len(t, last, 4)
+	assert.Equal(t, EventActionTaken{Addr: ":2", Action: PlayerActionRaise, Amount: 40}, last[0].Event)
+	assert.IsType(t, EventStreetDealt{}, last[3].Event)
+	assert.Equal(t, Flop, last[3].Event.(EventStreetDealt).Round)
+
+	unsubscribe()
+	assert.Nil(t, game.PlayerAction(":3", PlayerActionCheck, 0))
+	assert.Equal(t, len(game.Events())-1, len(received))
+}
+
+func TestRebuildPokerGameFromEvents(t *testing.T) {
+	// [:1 BB] [:2 D] [:3 SB]
+	game := newTestGame(t, 3)
+
+	assert.Nil(t, game.PlayerAction(":2", PlayerActionRaise, 40))
+	assert.Nil(t, game.PlayerAction(":3", PlayerActionCall, 0))
+	assert.Nil(t, game.PlayerAction(":1", PlayerActionCall, 0))
+	for game.gameStarted && game.currentRound != Showdown {
+		assert.Nil(t, game.PlayerAction(game.actionOn, PlayerActionCheck, 0))
+	}
+	for game.gameStarted {
+		assert.Nil(t, game.ShowHand(game.actionOn))
+	}
+
+	rebuilt, err := RebuildPokerGame(10, 20, game.Events())
+	assert.Nil(t, err)
+	for _, viewer := range []string{"", ":1", ":2", ":3"} {
+		assert.Equal(t, game.SnapshotFor(viewer), rebuilt.SnapshotFor(viewer))
+	}
+
+	// The rebuilt game can keep on playing.
+	assert.Nil(t, rebuilt.StartNewHand())
+	assert.Equal(t, 2, rebuilt.SnapshotFor("").HandNumber)
+
+	_, err = RebuildPokerGame(10, 20, game.Events()[1:])
+	assert.NotNil(t, err)
+}
@@ -2,86 +2,108 @@ package p2p
 
 import (
 	"fmt"
-	"os"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
+// defaultDealDelay is how long the dealer waits before dealing a hand.
+const defaultDealDelay = 8 * time.Second
+
 type GameState struct {
 	listenAddr  string
 	broadcastch chan BroadcastTo
 
 	// currentStatus should be atomically accessable.
 	currentStatus *AtomicInt
-	// currentPlayerAction should be atomically accessable.
-	currentPlayerAction *AtomicInt
-	// currentDealer should be atomically accessable.
-	// NOTE: this will be -1 when the game is in a bootstrapped state.
-	currentDealer *AtomicInt
-	// currentPlayerTurn should be atomically accessable.
-	currentPlayerTurn *AtomicInt
 	// playersList is the list of connected players to the network
 	playersList *PlayersList
 
 	table *Table
+
+	// game is our copy of the hand. Every node applies the actions of all
+	// players to it, so all of them agree on stacks, pots and winners.
+	game *PokerGame
+	// stack is what players sit down with.
+	stack int
+	// tournament is the Sit & Go played at the table, if any.
+	tournament *Tournament
+
+	mentalLock sync.RWMutex
+	// mental is our part in dealing the current hand.
+	mental *mentalDeck
+
+	dealerLock sync.Mutex
+	// dealerClaims holds who every player says deals a hand, by hand number.
+	dealerClaims map[int]map[string]string
+
+	awayLock sync.Mutex
+	// away are the players that lost the connection, by the timer of their
+	// reconnect window.
+	away            map[string]*time.Timer
+	reconnectWindow time.Duration
+
+	syncLock sync.Mutex
+	// syncs are the state syncs players sent us for syncHand, by sender.
+	syncs    map[string]MessageStateSync
+	syncHand int
+
+	// dealDelay is how long the dealer waits before dealing a hand.
+	dealDelay time.Duration
+	// dealLock makes sure we deal a hand once when players get ready at the
+	// same time.
+	dealLock sync.Mutex
 }
 
-func NewGame(addr string, bc chan BroadcastTo) *GameState {
+func NewGame(addr string, bc chan BroadcastTo, game *PokerGame, stack int) *GameState {
 	g := &GameState{
-		listenAddr:          addr,
-		broadcastch:         bc,
-		currentStatus:       NewAtomicInt(int32(GameStatusConnected)),
-		playersList:         NewPlayersList(),
-		currentPlayerAction: NewAtomicInt(0),
-		currentDealer:       NewAtomicInt(0),
-		currentPlayerTurn:   NewAtomicInt(0),
-		table:               NewTable(6),
+		listenAddr:    addr,
+		broadcastch:   bc,
+		currentStatus: NewAtomicInt(int32(GameStatusConnected)),
+		playersList:   NewPlayersList(),
+		table:         NewTable(6),
+		game:          game,
+		stack:         stack,
+		dealerClaims:  make(map[int]map[string]string),
+		away:          make(map[string]*time.Timer),
+		syncs:         make(map[string]MessageStateSync),
+		dealDelay:     defaultDealDelay,
 	}
 
 	g.playersList.add(addr)
+	game.OnTimeout(g.handleTimeout)
 
 	go g.loop()
 
 	return g
 }
 
-func (g *GameState) canTakeAction(from string) bool {
-	currentPlayerAddr := g.playersList.get(g.currentPlayerTurn.Get())
-	return currentPlayerAddr == from
-}
-
 func (g *GameState) isFromCurrentDealer(from string) bool {
-	return g.playersList.get(g.currentDealer.Get()) == from
+	currentDealerAddr, _ := g.getCurrentDealerAddr()
+	return currentDealerAddr == from
 }
 
 func (g *GameState) handlePlayerAction(from string, action MessagePlayerAction) error {
-	if !g.canTakeAction(from) {
-		return fmt.Errorf("player (%s) taking action before his turn", from)
-	}
-
 	// If we receive a message from a peer that doenst have the same game status
-	// as ours, but is not the current dealer we return an error. Cannot proceed.
-	if action.CurrentGameStatus != GameStatus(g.currentStatus.Get()) && !g.isFromCurrentDealer(from) {
-		return fmt.Errorf("player (%s) has not the correct game status (%s)", from, action.CurrentGameStatus)
+	// as ours our copies of the game are out of sync. Cannot proceed.
+	if status := GameStatus(g.currentStatus.Get()); action.CurrentGameStatus != status {
+		return fmt.Errorf("player (%s) has not the correct game status (%s), ours is (%s)", from, action.CurrentGameStatus, status)
 	}
 
-	// Every player in this case will need to set the current game status to the next one (next round).
-	// NEXT
-	// 1. set the next player action to IDLE
-	// 2. set the next current game status
-	if g.playersList.get(g.currentDealer.Get()) == from {
-		g.advanceToNexRound()
+	if action.TimedOut {
+		taken, err := g.game.TimedOut(from, action.Action == PlayerActionFold)
+		if err != nil {
+			return fmt.Errorf("invalid timeout from player (%s): %s", from, err)
+		}
+		if taken != action.Action {
+			return fmt.Errorf("player (%s) timed out with %s, we did %s", from, action.Action, taken)
+		}
+	} else if err := g.game.PlayerAction(from, action.Action, action.Value); err != nil {
+		return fmt.Errorf("invalid action from player (%s): %s", from, err)
 	}
-
-	// TODO:(@anthdm)
-	// This still not fixed!
-	// This still not fixed!
-	// This function should be handle the logic of picking the next player
-	// internally. Cause maybe the next player in the list in not the next index, hence not
-	// g.currentPlayerTurn + 1, due to the fact that his status can be just "connected"
-	g.incNextPlayer()
+	g.syncStatus()
 
 	logrus.WithFields(logrus.Fields{
 		"we":     g.listenAddr,
@@ -92,73 +114,170 @@ func (g *GameState) handlePlayerAction(from string, action MessagePlayerAction)
 	return nil
 }
 
+// TakeAction plays our action, it is only sent to the other players when
+// the rules allow it.
 func (g *GameState) TakeAction(action PlayerAction, value int) error {
-	if !g.canTakeAction(g.listenAddr) {
-		return fmt.Errorf("taking action before its my turn %s", g.listenAddr)
+	status := GameStatus(g.currentStatus.Get())
+	if err := g.game.PlayerAction(g.listenAddr, action, value); err != nil {
+		return err
+	}
+	g.syncStatus()
+
+	a := MessagePlayerAction{
+		Action:            action,
+		CurrentGameStatus: status,
+		Value:             value,
 	}
+	g.sendToPlayers(a, g.getOtherPlayers()...)
 
-	g.currentPlayerAction.Set((int32)(action))
+	return nil
+}
 
-	g.incNextPlayer()
+// handleTimeout is called when the time of a player runs out. We only act
+// for ourselves and send the action like TakeAction does, the other peers
+// wait for it instead of acting on their own timers.
+func (g *GameState) handleTimeout(addr string) {
+	if addr != g.listenAddr {
+		return
+	}
 
-	// If we are the dealer that just took an action, we can go to the next round.
-	if g.listenAddr == g.playersList.get(g.currentDealer.Get()) {
-		// NEXT
-		g.advanceToNexRound()
+	status := GameStatus(g.currentStatus.Get())
+	action, err := g.game.TimeOut(g.listenAddr)
+	if err != nil {
+		logrus.Errorf("timeout error: %s", err)
+		return
 	}
+	g.syncStatus()
 
 	a := MessagePlayerAction{
 		Action:            action,
-		CurrentGameStatus: GameStatus(g.currentStatus.Get()),
-		Value:             value,
+		CurrentGameStatus: status,
+		TimedOut:          true,
 	}
 	g.sendToPlayers(a, g.getOtherPlayers()...)
+}
+
+// ShowHand shows or mucks our hole cards when it is our turn at showdown.
+// The keys of a hand shown follow the message.
+func (g *GameState) ShowHand(muck bool) error {
+	if err := g.showHand(g.listenAddr, muck); err != nil {
+		return err
+	}
+
+	g.sendToPlayers(MessageShowHand{Muck: muck}, g.getOtherPlayers()...)
+	g.syncStatus()
 
 	return nil
 }
 
-func (g *GameState) getNextGameStatus() GameStatus {
-	status := GameStatus(g.currentStatus.Get())
-	switch status {
-	case GameStatusPreFlop:
-		return GameStatusFlop
-	case GameStatusFlop:
-		return GameStatusTurn
-	case GameStatusTurn:
-		return GameStatusRiver
-	case GameStatusRiver:
-		return GameStatusPlayerReady
-	default:
-		fmt.Printf("invalid status => %+v\n", status)
-		panic("invalid game status")
+// handleShowHand is getting called when a player in the network shows or
+// mucks at showdown.
+func (g *GameState) handleShowHand(from string, msg MessageShowHand) error {
+	if err := g.showHand(from, msg.Muck); err != nil {
+		return fmt.Errorf("invalid showdown from player (%s): %s", from, err)
 	}
+	g.syncStatus()
+
+	return nil
 }
 
-func (g *GameState) advanceToNexRound() {
-	g.currentPlayerAction.Set(int32(PlayerActionNone))
+func (g *GameState) showHand(addr string, muck bool) error {
+	show := g.game.ShowHand
+	if muck {
+		show = g.game.MuckHand
+	}
+	return show(addr)
+}
 
-	if GameStatus(g.currentStatus.Get()) == GameStatusRiver {
-		g.SetReady()
-		return
+// syncStatus sets the status to the betting round of the game. Once the
+// hand is over the players are ready for the next one.
+func (g *GameState) syncStatus() {
+	started, round, _ := g.game.handStatus()
+	status := GameStatusPlayerReady
+	if started {
+		switch round {
+		case PreFlop:
+			status = GameStatusPreFlop
+		case Flop:
+			status = GameStatusFlop
+		case Turn:
+			status = GameStatusTurn
+		case River:
+			status = GameStatusRiver
+		case Showdown:
+			status = GameStatusShowdown
+		}
+	}
+
+	prev := GameStatus(g.currentStatus.Get())
+	g.SetStatus(status)
+
+	// A hand shown last may end the hand, its keys are still due.
+	g.revealKeys()
+	if prev >= GameStatusPreFlop && status == GameStatusPlayerReady {
+		g.nextTournamentHand()
+		g.rotateDealer()
 	}
-	g.currentStatus.Set(int32(g.getNextGameStatus()))
 }
 
-func (g *GameState) incNextPlayer() {
-	player, err := g.table.GetPlayerAfter(g.listenAddr)
-	if err != nil {
-		panic(err)
+// rotateDealer is called once a hand is over. Every node works out the next
+// dealer from its own copy of the game and tells the others, so nodes that
+// disagree find out right away.
+func (g *GameState) rotateDealer() {
+	dealer, hand := g.game.NextButton()
+	if err := g.claimDealer(hand, g.listenAddr, dealer); err != nil {
+		logrus.Errorf("dealer mismatch: %s", err)
 	}
 
-	if g.playersList.len()-1 == int(g.currentPlayerTurn.Get()) {
-		g.currentPlayerTurn.Set(0)
-		return
+	logrus.WithFields(logrus.Fields{
+		"we":     g.listenAddr,
+		"hand":   hand,
+		"dealer": dealer,
+	}).Info("dealer button moved")
+
+	g.sendToPlayers(MessageDealer{HandNumber: hand, Dealer: dealer}, g.getOtherPlayers()...)
+
+	if dealer == g.listenAddr {
+		g.dealLater()
 	}
-	g.currentPlayerTurn.Inc()
+}
 
-	fmt.Println("the next player on the table is:", player.tablePos)
-	fmt.Println("old wrong value => ", g.currentPlayerTurn)
-	os.Exit(0)
+// handleDealer is getting called when a player in the network tells who
+// deals the next hand.
+func (g *GameState) handleDealer(from string, msg MessageDealer) error {
+	return g.claimDealer(msg.HandNumber, from, msg.Dealer)
+}
+
+// claimDealer records who the player says deals the hand and checks it
+// against our own view, as soon as we have one.
+func (g *GameState) claimDealer(hand int, from, dealer string) error {
+	g.dealerLock.Lock()
+	defer g.dealerLock.Unlock()
+
+	for h := range g.dealerClaims {
+		if h < hand-1 {
+			delete(g.dealerClaims, h)
+		}
+	}
+
+	claims, ok := g.dealerClaims[hand]
+	if !ok {
+		claims = make(map[string]string)
+		g.dealerClaims[hand] = claims
+	}
+	claims[from] = dealer
+
+	ours, ok := claims[g.listenAddr]
+	if !ok {
+		return nil
+	}
+	for addr, claim := range claims {
+		if claim != ours {
+			return fmt.Errorf("player (%s) has (%s) dealing hand %d, we have (%s)", addr, claim, hand, ours)
+		}
+	}
+
+	return nil
 }
 
 func (g *GameState) SetStatus(s GameStatus) {
@@ -167,76 +286,153 @@ func (g *GameState) SetStatus(s GameStatus) {
 }
 
 func (g *GameState) setStatus(s GameStatus) {
-	if s == GameStatusPreFlop {
-		g.incNextPlayer()
-	}
-
 	// Only update the status when the status is different.
 	if GameStatus(g.currentStatus.Get()) != s {
 		g.currentStatus.Set(int32(s))
 	}
 }
 
+// getCurrentDealerAddr returns the player dealing the next hand, which is
+// the one getting the button.
 func (g *GameState) getCurrentDealerAddr() (string, bool) {
-	currentDealerAddr := g.playersList.get(g.currentDealer.Get())
+	currentDealerAddr, _ := g.game.NextButton()
 	return currentDealerAddr, g.listenAddr == currentDealerAddr
 }
 
-func (g *GameState) ShuffleAndEncrypt(from string, deck [][]byte) error {
+// ShuffleAndEncrypt is getting called when the deck is passed to us. The
+// deck goes around the table twice: first every player encrypts and
+// shuffles it, then every player swaps their encryption for a key per card.
+// Once it is back with the dealer the hand is dealt.
+func (g *GameState) ShuffleAndEncrypt(from string, msg MessageEncDeck) error {
 	if g.table.LenPlayers() < 2 {
 		return fmt.Errorf("need at least 2 players to start dealing, got %d", g.table.LenPlayers())
 	}
 
-	fmt.Println("addr", g.listenAddr)
-	fmt.Printf("%+v\n", g.table)
 	prevPlayer, err := g.table.GetPlayerBefore(g.listenAddr)
 	if err != nil {
-		panic(err)
+		return err
 	}
-	fmt.Println("prev pos", prevPlayer)
-	// [3000] == dealer
-	// [5000] == small blind
-	// [7000] == big blind
 	if from != prevPlayer.addr {
 		return fmt.Errorf("[%s] received encrypted deck from the wrong player (%s) should be (%s)", g.listenAddr, from, prevPlayer.addr)
 	}
 
 	// If we are the dealer and we received a message from
-	// the previous player on the table we advance to the next round.
+	// the previous player on the table the deck went around.
 	_, isDealer := g.getCurrentDealerAddr()
-	if isDealer && from == prevPlayer.addr {
-		g.setStatus(GameStatusPreFlop)
-		g.table.SetPlayerStatus(g.listenAddr, GameStatusPreFlop)
-		g.sendToPlayers(MessagePreFlop{}, g.getOtherPlayers()...)
-		return nil
+	if isDealer && msg.Locked {
+		return g.dealHand(msg.Deck)
+	}
+
+	var cards [][]byte
+	switch {
+	case isDealer:
+		md := g.mentalDeck()
+		if md == nil {
+			return fmt.Errorf("received a shuffled deck we did not start")
+		}
+		cards, err = md.lock(msg.Deck)
+		msg.Locked = true
+	case msg.Locked:
+		md := g.mentalDeck()
+		if md == nil {
+			return fmt.Errorf("received a locked deck we did not shuffle")
+		}
+		cards, err = md.lock(msg.Deck)
+	default:
+		var md *mentalDeck
+		if md, err = g.newMentalDeck(); err == nil {
+			cards, err = md.shuffle(msg.Deck)
+		}
+	}
+	if err != nil {
+		return err
 	}
 
 	dealToPlayer, err := g.table.GetPlayerAfter(g.listenAddr)
 	if err != nil {
-		panic(err)
+		return err
 	}
 
 	logrus.WithFields(logrus.Fields{
 		"recvFromPlayer":  from,
 		"we":              g.listenAddr,
 		"dealingToPlayer": dealToPlayer.addr,
+		"locked":          msg.Locked,
 	}).Info("received cards and going to shuffle")
 
-	// TODO:(@anthdm) encryption and shuffle
-	g.sendToPlayers(MessageEncDeck{Deck: [][]byte{}}, dealToPlayer.addr)
+	g.sendToPlayers(MessageEncDeck{Deck: cards, Locked: msg.Locked}, dealToPlayer.addr)
 	g.setStatus(GameStatusDealing)
 
 	return nil
 }
 
+// dealHand starts the hand on our copy of the game and gives the other
+// players the deck to deal from.
+func (g *GameState) dealHand(cards [][]byte) error {
+	if err := g.startHand(cards); err != nil {
+		return err
+	}
+
+	g.sendToPlayers(MessagePreFlop{Deck: cards}, g.getOtherPlayers()...)
+
+	return nil
+}
+
+// StartHand is getting called when the dealer started a new hand.
+func (g *GameState) StartHand(from string, cards [][]byte) error {
+	if !g.isFromCurrentDealer(from) {
+		return fmt.Errorf("player (%s) starting a hand is not the dealer", from)
+	}
+
+	if err := g.startHand(cards); err != nil {
+		return err
+	}
+
+	return g.turnUpCards()
+}
+
+// startHand deals the hand face down, the cards are turned up as the
+// players reveal their keys.
+func (g *GameState) startHand(cards [][]byte) error {
+	md := g.mentalDeck()
+	if md == nil {
+		return fmt.Errorf("no deck for the hand, we did not shuffle")
+	}
+	if _, hand := g.game.NextButton(); md.handNumber != hand {
+		return fmt.Errorf("deck is for hand %d, next hand is %d", md.handNumber, hand)
+	}
+	if err := md.setCards(cards); err != nil {
+		return err
+	}
+
+	if err := g.game.StartNewHandWithDeck(faceDownDeck()); err != nil {
+		return err
+	}
+	g.syncStatus()
+
+	return nil
+}
+
 func (g *GameState) InitiateShuffleAndDeal() {
 	dealToPlayer, err := g.table.GetPlayerAfter(g.listenAddr)
 	if err != nil {
-		panic(err)
+		logrus.Errorf("cannot deal: %s", err)
+		return
+	}
+
+	md, err := g.newMentalDeck()
+	if err != nil {
+		logrus.Errorf("cannot deal: %s", err)
+		return
+	}
+	cards, err := md.shuffle(encodeDeck())
+	if err != nil {
+		logrus.Errorf("cannot deal: %s", err)
+		return
 	}
 
 	g.setStatus(GameStatusDealing)
-	g.sendToPlayers(MessageEncDeck{Deck: [][]byte{}}, dealToPlayer.addr)
+	g.sendToPlayers(MessageEncDeck{Deck: cards}, dealToPlayer.addr)
 
 	logrus.WithFields(logrus.Fields{
 		"we": g.listenAddr,
@@ -244,7 +440,118 @@ func (g *GameState) InitiateShuffleAndDeal() {
 	}).Info("dealing cards")
 }
 
+// newMentalDeck starts our part in dealing the next hand.
+func (g *GameState) newMentalDeck() (*mentalDeck, error) {
+	players := []string{}
+	for _, player := range g.table.Players() {
+		players = append(players, player.addr)
+	}
+
+	_, hand := g.game.NextButton()
+	md, err := newMentalDeck(hand, players)
+	if err != nil {
+		return nil, err
+	}
+
+	g.mentalLock.Lock()
+	g.mental = md
+	g.mentalLock.Unlock()
+
+	return md, nil
+}
+
+func (g *GameState) mentalDeck() *mentalDeck {
+	g.mentalLock.RLock()
+	defer g.mentalLock.RUnlock()
+
+	return g.mental
+}
+
+// revealKeys hands out our keys for the cards that can be turned up: hole
+// cards to the player they are dealt to, the board and the hands shown to
+// everybody. Mucked hands stay known to their player only.
+func (g *GameState) revealKeys() {
+	md := g.mentalDeck()
+	if md == nil {
+		return
+	}
+
+	holes, public := g.game.cardsToReveal()
+	for addr, positions := range holes {
+		if addr == g.listenAddr {
+			continue
+		}
+		if keys := md.keysFor(addr, positions); len(keys) > 0 {
+			g.sendToPlayers(MessageCardKeys{HandNumber: md.handNumber, Keys: keys}, addr)
+		}
+	}
+	if keys := md.keysFor("", md.positionsOf(public)); len(keys) > 0 {
+		g.sendToPlayers(MessageCardKeys{HandNumber: md.handNumber, Keys: keys}, g.getOtherPlayers()...)
+	}
+}
+
+// handleCardKeys is getting called when a player in the network reveals
+// keys to us.
+func (g *GameState) handleCardKeys(from string, msg MessageCardKeys) error {
+	md := g.mentalDeck()
+	if md == nil || md.handNumber != msg.HandNumber {
+		return fmt.Errorf("player (%s) revealed keys for hand %d we are not dealing", from, msg.HandNumber)
+	}
+
+	if err := md.addKeys(from, msg.Keys); err != nil {
+		return err
+	}
+
+	return g.turnUpCards()
+}
+
+// turnUpCards reveals the cards we have all keys for to our copy of the
+// game.
+func (g *GameState) turnUpCards() error {
+	md := g.mentalDeck()
+	if md == nil {
+		return nil
+	}
+	if started, _, _ := g.game.handStatus(); !started {
+		return nil
+	}
+
+	cards, err := md.turnUp()
+	if err != nil {
+		return fmt.Errorf("deck check failed: %s", err)
+	}
+	if len(cards) > 0 {
+		if err := g.game.RevealCards(cards); err != nil {
+			return err
+		}
+		g.syncStatus()
+	}
+
+	return nil
+}
+
+// SetDealDelay sets how long the dealer waits before dealing a hand. It has
+// to be set before players connect.
+func (g *GameState) SetDealDelay(d time.Duration) {
+	g.dealDelay = d
+}
+
+// dealLater deals the next hand after the deal delay, if we are still the
+// dealer by then.
+func (g *GameState) dealLater() {
+	go func() {
+		time.Sleep(g.dealDelay)
+		g.maybeDeal()
+	}()
+}
+
 func (g *GameState) maybeDeal() {
+	g.dealLock.Lock()
+	defer g.dealLock.Unlock()
+
+	if _, isDealer := g.getCurrentDealerAddr(); !isDealer || g.isAnyoneAway() || !g.tournamentDeals() {
+		return
+	}
 	if GameStatus(g.currentStatus.Get()) == GameStatusPlayerReady {
 		g.InitiateShuffleAndDeal()
 	}
@@ -255,6 +562,7 @@ func (g *GameState) maybeDeal() {
 func (g *GameState) SetPlayerReady(addr string) {
 	tablePos := g.playersList.getIndex(addr)
 	g.table.AddPlayerOnPosition(addr, tablePos)
+	g.seatPlayer(addr, tablePos)
 
 	// TODO(@anthdm): This potentially going to cause an issue!
 	// If we don't have enough players the round cannot be started.
@@ -264,12 +572,7 @@ func (g *GameState) SetPlayerReady(addr string) {
 
 	// we need to check if we are the dealer of the current round.
 	if _, areWeDealer := g.getCurrentDealerAddr(); areWeDealer {
-		go func() {
-			// if the game can start we will wait another
-			// N amount of seconds to actually start dealing
-			time.Sleep(8 * time.Second)
-			g.maybeDeal()
-		}()
+		g.dealLater()
 	}
 }
 
@@ -277,15 +580,138 @@ func (g *GameState) SetPlayerReady(addr string) {
 func (g *GameState) SetReady() {
 	tablePos := g.playersList.getIndex(g.listenAddr)
 	g.table.AddPlayerOnPosition(g.listenAddr, tablePos)
+	g.seatPlayer(g.listenAddr, tablePos)
 
 	g.sendToPlayers(MessageReady{}, g.getOtherPlayers()...)
 	g.setStatus(GameStatusPlayerReady)
+
+	// The other players may have been ready before us.
+	if g.table.LenPlayers() < 2 {
+		return
+	}
+	if _, areWeDealer := g.getCurrentDealerAddr(); areWeDealer {
+		g.dealLater()
+	}
+}
+
+// seatPlayer sits a ready player down in our copy of the game.
+func (g *GameState) seatPlayer(addr string, tablePos int) {
+	var err error
+	if g.tournament != nil {
+		err = g.tournament.Seat(addr, tablePos)
+	} else {
+		err = g.game.AddPlayer(addr, g.stack, tablePos)
+	}
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"we":     g.listenAddr,
+			"player": addr,
+		}).Warnf("cannot seat player: %s", err)
+	}
+}
+
+// SitOut is being called when we step away from the table.
+func (g *GameState) SitOut(nextBigBlind bool) error {
+	if err := g.game.SitOut(g.listenAddr, nextBigBlind); err != nil {
+		return err
+	}
+	if err := g.table.SetPlayerSittingOut(g.listenAddr, true); err != nil {
+		return err
+	}
+
+	g.sendToPlayers(MessageSitOut{NextBigBlind: nextBigBlind}, g.getOtherPlayers()...)
+
+	return nil
+}
+
+// SitIn is being called when we return to the table.
+func (g *GameState) SitIn(waitForBigBlind bool) error {
+	if err := g.game.SitIn(g.listenAddr, waitForBigBlind); err != nil {
+		return err
+	}
+	if err := g.table.SetPlayerSittingOut(g.listenAddr, false); err != nil {
+		return err
+	}
+
+	g.sendToPlayers(MessageSitIn{WaitForBigBlind: waitForBigBlind}, g.getOtherPlayers()...)
+
+	return nil
+}
+
+// SetPlayerSittingOut is getting called when a player in the network steps
+// away from or returns to the table. bigBlind is the next big blind or wait
+// for big blind choice of the player.
+func (g *GameState) SetPlayerSittingOut(addr string, sittingOut, bigBlind bool) error {
+	logrus.WithFields(logrus.Fields{
+		"we":         g.listenAddr,
+		"player":     addr,
+		"sittingOut": sittingOut,
+	}).Info("player sit out changed")
+
+	sit := g.game.SitIn
+	if sittingOut {
+		sit = g.game.SitOut
+	}
+	if err := sit(addr, bigBlind); err != nil {
+		return err
+	}
+
+	return g.table.SetPlayerSittingOut(addr, sittingOut)
+}
+
+// AddChips is being called when we rebuy or top up our stack. Chips can
+// only be added between hands.
+func (g *GameState) AddChips(amount int, reason LedgerReason) error {
+	if amount <= 0 {
+		return fmt.Errorf("invalid amount %d", amount)
+	}
+
+	if status := GameStatus(g.currentStatus.Get()); status >= GameStatusDealing {
+		return fmt.Errorf("cannot add chips during a hand (%s)", status)
+	}
+
+	if err := g.addChips(g.listenAddr, amount, reason); err != nil {
+		return err
+	}
+
+	g.sendToPlayers(MessageChipsAdded{Amount: amount, Reason: reason}, g.getOtherPlayers()...)
+
+	return nil
+}
+
+// handleChipsAdded is getting called when a player in the network rebuys
+// or tops up.
+func (g *GameState) handleChipsAdded(from string, msg MessageChipsAdded) error {
+	if status := GameStatus(g.currentStatus.Get()); status >= GameStatusDealing {
+		return fmt.Errorf("player (%s) adding chips during a hand (%s)", from, status)
+	}
+
+	if err := g.addChips(from, msg.Amount, msg.Reason); err != nil {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"we":     g.listenAddr,
+		"player": from,
+		"amount": msg.Amount,
+		"reason": msg.Reason,
+	}).Info("player added chips")
+
+	return nil
+}
+
+func (g *GameState) addChips(addr string, amount int, reason LedgerReason) error {
+	if reason == LedgerRebuy {
+		return g.game.Rebuy(addr, amount)
+	}
+	return g.game.TopUp(addr, amount)
 }
 
 func (g *GameState) sendToPlayers(payload any, addr ...string) {
 	g.broadcastch <- BroadcastTo{
 		To:      addr,
 		Payload: payload,
+		Hand:    g.game.currentHand(),
 	}
 }
 
@@ -296,6 +722,43 @@ func (g *GameState) AddPlayer(from string) {
 	sort.Sort(g.playersList)
 }
 
+// RemovePlayer is getting called when a player in the network did not
+// reconnect in time. The hand of the player is folded and the player leaves
+// the table after it, see PokerGame.Disconnect. Cards that still need the
+// keys of the player can't be turned up anymore.
+func (g *GameState) RemovePlayer(addr string) {
+	g.stopAway(addr)
+	if addr == g.listenAddr || !g.playersList.remove(addr) {
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"we":     g.listenAddr,
+		"player": addr,
+	}).Info("player left the table")
+
+	if err := g.table.RemovePlayerByAddr(addr); err == nil {
+		if err := g.game.Disconnect(addr); err != nil {
+			logrus.Errorf("disconnect error for %s: %s", addr, err)
+		}
+	}
+
+	g.sendToPlayers(MessagePlayerDisconnected{Addr: addr}, g.getOtherPlayers()...)
+
+	switch status := GameStatus(g.currentStatus.Get()); {
+	case status >= GameStatusPreFlop:
+		g.syncStatus()
+	case status == GameStatusDealing:
+		// The deck can't go around the table anymore, it is dealt again.
+		g.setStatus(GameStatusPlayerReady)
+		fallthrough
+	case status == GameStatusPlayerReady:
+		if _, isDealer := g.getCurrentDealerAddr(); isDealer {
+			g.dealLater()
+		}
+	}
+}
+
 func (g *GameState) loop() {
 	ticker := time.NewTicker(time.Second * 5)
 
@@ -303,12 +766,13 @@ func (g *GameState) loop() {
 		<-ticker.C
 
 		currentDealerAddr, _ := g.getCurrentDealerAddr()
+		_, _, actionOn := g.game.handStatus()
 		logrus.WithFields(logrus.Fields{
-			"we":  g.listenAddr,
-			"pl":  g.playersList.List(),
-			"gs":  GameStatus(g.currentStatus.Get()),
-			"cd":  currentDealerAddr,
-			"npt": g.currentPlayerTurn,
+			"we": g.listenAddr,
+			"pl": g.playersList.List(),
+			"gs": GameStatus(g.currentStatus.Get()),
+			"cd": currentDealerAddr,
+			"ao": actionOn,
 		}).Info()
 	}
 }
@@ -337,6 +801,19 @@ func (g *GameState) getPositionOnTable() int {
 	panic("player does not exist in the playersList; that should not happen!!!")
 }
 
-func (g *GameState) getNextDealer() int {
-	panic("TODO")
+// handStatus returns whether a hand is being played, its betting round and
+// whose turn it is.
+func (pg *PokerGame) handStatus() (bool, BettingRound, string) {
+	pg.mu.RLock()
+	defer pg.mu.RUnlock()
+
+	return pg.gameStarted, pg.currentRound, pg.actionOn
+}
+
+// currentHand returns the number of the current or last hand.
+func (pg *PokerGame) currentHand() int {
+	pg.mu.RLock()
+	defer pg.mu.RUnlock()
+
+	return pg.handNumber
 }
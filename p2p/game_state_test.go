@@ -0,0 +1,389 @@
+package p2p
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/koshiq/ggpoker/deck"
+	"github.com/stretchr/testify/assert"
+)
+
+// testNetwork passes the messages of the nodes to each other, like the
+// server would.
+type testNetwork map[string]*GameState
+
+func newTestNetwork(t *testing.T, addrs ...string) testNetwork {
+	network := testNetwork{}
+	for _, addr := range addrs {
+		network[addr] = NewGame(addr, make(chan BroadcastTo, 100), NewPokerGame(10, 20), 1000)
+	}
+	for _, g := range network {
+		for _, addr := range addrs {
+			if addr != g.listenAddr {
+				g.AddPlayer(addr)
+			}
+		}
+	}
+
+	return network
+}
+
+func (n testNetwork) deliver(t *testing.T) {
+	for delivered := true; delivered; {
+		delivered = false
+		for from, g := range n {
+			select {
+			case msg := <-g.broadcastch:
+				delivered = true
+				for _, to := range msg.To {
+					if g, ok := n[to]; ok {
+						assert.Nil(t, n.handle(from, g, msg.Payload))
+					}
+				}
+			default:
+			}
+		}
+	}
+}
+
+func (n testNetwork) handle(from string, g *GameState, payload any) error {
+	switch v := payload.(type) {
+	case MessageReady:
+		g.SetPlayerReady(from)
+	case MessageEncDeck:
+		return g.ShuffleAndEncrypt(from, v)
+	case MessagePreFlop:
+		return g.StartHand(from, v.Deck)
+	case MessagePlayerAction:
+		return g.handlePlayerAction(from, v)
+	case MessageShowHand:
+		return g.handleShowHand(from, v)
+	case MessageDealer:
+		return g.handleDealer(from, v)
+	case MessageCardKeys:
+		return g.handleCardKeys(from, v)
+	case MessagePlayerDisconnected:
+		g.RemovePlayer(v.Addr)
+	case MessageStateSync:
+		return g.handleStateSync(from, v)
+	}
+	return nil
+}
+
+func TestGameStateReplicatesHand(t *testing.T) {
+	network := newTestNetwork(t, ":3000", ":4000", ":5000")
+	for _, addr := range []string{":3000", ":4000", ":5000"} {
+		network[addr].SetReady()
+		network.deliver(t)
+	}
+	dealer, _ := network[":3000"].getCurrentDealerAddr()
+	assert.Equal(t, ":4000", dealer)
+	network[dealer].InitiateShuffleAndDeal()
+	network.deliver(t)
+
+	// Players only know their own hole cards.
+	for addr, g := range network {
+		assert.Equal(t, GameStatusPreFlop, GameStatus(g.currentStatus.Get()))
+		for other, player := range g.game.players {
+			assert.Len(t, player.HoleCards, 2)
+			for _, card := range player.HoleCards {
+				assert.Equal(t, other != addr, card.IsFaceDown())
+			}
+		}
+	}
+
+	// Actions out of turn are not sent to anybody.
+	_, _, actionOn := network[":3000"].game.handStatus()
+	for addr, g := range network {
+		if addr != actionOn {
+			assert.NotNil(t, g.TakeAction(PlayerActionCheck, 0))
+			assert.Len(t, g.broadcastch, 0)
+			break
+		}
+	}
+	assert.NotNil(t, network[":3000"].handlePlayerAction(actionOn, MessagePlayerAction{
+		CurrentGameStatus: GameStatusFlop,
+		Action:            PlayerActionCall,
+	}))
+
+	raised := false
+	for {
+		started, _, _ := network[":3000"].game.handStatus()
+		if !started {
+			break
+		}
+		for addr, g := range network {
+			actions := g.game.LegalActions(addr)
+			if len(actions) == 0 {
+				continue
+			}
+			switch {
+			case actions[0].Action == "SHOW":
+				assert.Nil(t, g.ShowHand(false))
+			case !raised && actions[len(actions)-1].Action == PlayerActionRaise.String():
+				assert.Nil(t, g.TakeAction(PlayerActionRaise, 40))
+				raised = true
+			case actions[1].Action == PlayerActionCall.String():
+				assert.Nil(t, g.TakeAction(PlayerActionCall, 0))
+			default:
+				assert.Nil(t, g.TakeAction(PlayerActionCheck, 0))
+			}
+			network.deliver(t)
+			break
+		}
+	}
+
+	// Every node ends the hand with the same stacks and winners.
+	var stacks map[string]int
+	var awards []EventPotAwarded
+	for _, g := range network {
+		assert.Equal(t, GameStatusPlayerReady, GameStatus(g.currentStatus.Get()))
+		assert.Len(t, g.game.communityCards, 5)
+		for _, card := range g.game.communityCards {
+			assert.False(t, card.IsFaceDown())
+		}
+		for _, player := range g.game.players {
+			for _, card := range player.HoleCards {
+				assert.False(t, card.IsFaceDown())
+			}
+		}
+
+		nodeStacks := map[string]int{}
+		total := 0
+		for addr, player := range g.game.players {
+			nodeStacks[addr] = player.Stack
+			total += player.Stack
+		}
+		assert.Equal(t, 3000, total)
+
+		nodeAwards := []EventPotAwarded{}
+		for _, r := range g.game.Events() {
+			if e, ok := r.Event.(EventPotAwarded); ok {
+				nodeAwards = append(nodeAwards, e)
+			}
+		}
+		assert.NotEmpty(t, nodeAwards)
+
+		if stacks == nil {
+			stacks, awards = nodeStacks, nodeAwards
+			continue
+		}
+		assert.Equal(t, stacks, nodeStacks)
+		assert.Equal(t, awards, nodeAwards)
+	}
+
+	// The button moves on and every node agrees who deals next.
+	for _, g := range network {
+		dealer, _ := g.getCurrentDealerAddr()
+		assert.Equal(t, ":5000", dealer)
+	}
+	assert.NotNil(t, network[":3000"].handleDealer(":4000", MessageDealer{HandNumber: 2, Dealer: ":3000"}))
+}
+
+func TestGameStateKeepsMuckedHandsSecret(t *testing.T) {
+	network := newTestNetwork(t, ":3000", ":4000", ":5000")
+	for _, addr := range []string{":3000", ":4000", ":5000"} {
+		// The test deals every hand itself.
+		network[addr].SetDealDelay(time.Hour)
+		network[addr].SetReady()
+		network.deliver(t)
+	}
+
+	// The best hand bets the river and shows first, a hand it beats can be
+	// mucked. A board that plays for everybody is dealt again.
+	mucked := ""
+	for hand := 0; mucked == "" && hand < 5; hand++ {
+		dealer, _ := network[":3000"].getCurrentDealerAddr()
+		network[dealer].InitiateShuffleAndDeal()
+		network.deliver(t)
+
+		best := ""
+		for {
+			started, round, _ := network[":3000"].game.handStatus()
+			if !started {
+				break
+			}
+			if round == River && best == "" {
+				best = bestHand(network)
+			}
+			for addr, g := range network {
+				actions := g.game.LegalActions(addr)
+				if len(actions) == 0 {
+					continue
+				}
+				last := actions[len(actions)-1].Action
+				switch {
+				case actions[0].Action == "SHOW" && last == "MUCK":
+					assert.Nil(t, g.ShowHand(true))
+					mucked = addr
+				case actions[0].Action == "SHOW":
+					assert.Nil(t, g.ShowHand(false))
+				case addr == best && last == PlayerActionRaise.String():
+					assert.Nil(t, g.TakeAction(PlayerActionRaise, 40))
+					best = "done"
+				case actions[1].Action == PlayerActionCall.String():
+					assert.Nil(t, g.TakeAction(PlayerActionCall, 0))
+				default:
+					assert.Nil(t, g.TakeAction(PlayerActionCheck, 0))
+				}
+				network.deliver(t)
+				break
+			}
+		}
+	}
+	assert.NotEqual(t, "", mucked)
+
+	// Only the player knows the mucked hand, nobody got the keys for it.
+	owner := network[mucked]
+	positions := owner.mental.positionsOf(owner.game.players[mucked].HoleCards)
+	assert.Len(t, positions, 2)
+	for addr, g := range network {
+		assert.Equal(t, GameStatusPlayerReady, GameStatus(g.currentStatus.Get()))
+		for _, card := range g.game.players[mucked].HoleCards {
+			assert.Equal(t, addr != mucked, card.IsFaceDown())
+		}
+		if addr == mucked {
+			continue
+		}
+		for _, pos := range positions {
+			_, ok, err := g.mental.card(pos)
+			assert.Nil(t, err)
+			assert.False(t, ok)
+		}
+	}
+}
+
+// bestHand returns the player with the best hand on the board, each node
+// only knows its own cards.
+func bestHand(network testNetwork) string {
+	best := ""
+	var bestHand deck.Hand
+	for addr, g := range network {
+		cards := append([]deck.Card{}, g.game.players[addr].HoleCards...)
+		hand := deck.EvaluateHand(append(cards, g.game.communityCards...))
+		if best == "" || deck.CompareHands(hand, bestHand) > 0 {
+			best, bestHand = addr, hand
+		}
+	}
+	return best
+}
+
+func TestGameStateRemovesDisconnectedPlayer(t *testing.T) {
+	network := newTestNetwork(t, ":3000", ":4000", ":5000")
+	for _, addr := range []string{":3000", ":4000", ":5000"} {
+		network[addr].SetReady()
+		network.deliver(t)
+	}
+	network[":4000"].InitiateShuffleAndDeal()
+	network.deliver(t)
+
+	// One node loses a player that is not on the move, the other one hears
+	// about it.
+	_, _, actionOn := network[":3000"].game.handStatus()
+	lost := ""
+	for _, addr := range []string{":4000", ":5000"} {
+		if addr != actionOn {
+			lost = addr
+		}
+	}
+	delete(network, lost)
+	for _, g := range network {
+		g.RemovePlayer(lost)
+		break
+	}
+	network.deliver(t)
+
+	for _, g := range network {
+		assert.Equal(t, -1, g.playersList.getIndex(lost))
+		assert.Equal(t, 2, g.table.LenPlayers())
+		assert.True(t, g.game.players[lost].Disconnected)
+		assert.False(t, g.game.players[lost].Folded)
+	}
+
+	// The hand is folded once it is the turn of the lost player, who leaves
+	// after the hand.
+	assert.Nil(t, network[actionOn].TakeAction(PlayerActionFold, 0))
+	network.deliver(t)
+	for _, g := range network {
+		assert.Equal(t, GameStatusPlayerReady, GameStatus(g.currentStatus.Get()))
+		_, ok := g.game.players[lost]
+		assert.False(t, ok)
+		assert.Len(t, g.game.players, 2)
+	}
+}
+
+func TestGameStateTimesOutOnPlayersNode(t *testing.T) {
+	network := newTestNetwork(t, ":3000", ":4000", ":5000")
+	for _, addr := range []string{":3000", ":4000", ":5000"} {
+		network[addr].game.SetTimers(TimerConfig{ActionTimeout: 20 * time.Millisecond})
+		network[addr].SetReady()
+		network.deliver(t)
+	}
+	dealer, _ := network[":3000"].getCurrentDealerAddr()
+	network[dealer].InitiateShuffleAndDeal()
+	network.deliver(t)
+
+	// The timers of all nodes run out, only the node of the player acts.
+	_, _, actionOn := network[":3000"].game.handStatus()
+	assert.Eventually(t, func() bool {
+		return len(network[actionOn].broadcastch) > 0
+	}, time.Second, time.Millisecond)
+	for _, g := range network {
+		g.game.SetTimers(TimerConfig{})
+	}
+	time.Sleep(50 * time.Millisecond)
+	for addr, g := range network {
+		if addr != actionOn {
+			_, _, on := g.game.handStatus()
+			assert.Equal(t, actionOn, on)
+			assert.Len(t, g.broadcastch, 0)
+		}
+	}
+
+	network.deliver(t)
+	for _, g := range network {
+		player := g.game.players[actionOn]
+		assert.True(t, player.Folded)
+		assert.Equal(t, 1, player.TimeOuts)
+		assert.Equal(t, time.Duration(0), player.TimeBank)
+	}
+}
+
+func TestNextButtonSkipsPlayersLeaving(t *testing.T) {
+	game := newTestGame(t, 4)
+	button, hand := game.NextButton()
+	assert.Equal(t, ":3", button)
+	assert.Equal(t, 2, hand)
+
+	foldToBigBlind(t, game)
+	assert.Nil(t, game.RemovePlayer(":3"))
+	button, _ = game.NextButton()
+	assert.Equal(t, ":4", button)
+
+	assert.Nil(t, game.AddPlayer(":5", 1000, 2))
+	button, _ = game.NextButton()
+	assert.Equal(t, ":5", button)
+}
+
+func TestDealerDealsOnce(t *testing.T) {
+	network := newTestNetwork(t, ":3000", ":4000")
+	network[":3000"].SetReady()
+	network.deliver(t)
+	network[":4000"].SetReady()
+	network.deliver(t)
+
+	// The dealer may be asked to deal by several players at once.
+	dealer := network[":4000"]
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dealer.maybeDeal()
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, dealer.broadcastch, 1)
+}
@@ -48,6 +48,8 @@ func (g GameStatus) String() string {
 		return "TURN"
 	case GameStatusRiver:
 		return "RIVER"
+	case GameStatusShowdown:
+		return "SHOWDOWN"
 	default:
 		return "unknown"
 	}
@@ -61,4 +63,5 @@ const (
 	GameStatusFlop
 	GameStatusTurn
 	GameStatusRiver
+	GameStatusShowdown
 )
@@ -0,0 +1,211 @@
+package p2p
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"sync"
+
+	pb "github.com/koshiq/ggpoker/proto"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// grpcStream is the side of a Gossip stream we hold, the client or the
+// server one.
+type grpcStream interface {
+	Send(*pb.Envelope) error
+	Recv() (*pb.Envelope, error)
+}
+
+// grpcConn is a Gossip stream carrying protobuf envelopes.
+type grpcConn struct {
+	stream grpcStream
+	remote net.Addr
+	// authenticated is set when the stream is secured with TLS, peerKey is
+	// the identity key the peer authenticated with then.
+	authenticated bool
+	peerKey       ed25519.PublicKey
+
+	// sendLock serializes sends, a stream may not be written concurrently.
+	sendLock  sync.Mutex
+	closeOnce sync.Once
+	close     func()
+}
+
+func (c *grpcConn) Send(msg *Message) error {
+	env, err := toEnvelope(msg)
+	if err != nil {
+		return err
+	}
+
+	c.sendLock.Lock()
+	defer c.sendLock.Unlock()
+
+	return c.stream.Send(env)
+}
+
+// Receive returns io.EOF once the stream was closed by either side.
+func (c *grpcConn) Receive() (*Message, error) {
+	env, err := c.stream.Recv()
+	if err != nil {
+		if errors.Is(err, io.EOF) || status.Code(err) == codes.Canceled {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+
+	return fromEnvelope(env)
+}
+
+func (c *grpcConn) RemoteAddr() net.Addr { return c.remote }
+
+func (c *grpcConn) Authenticated() bool { return c.authenticated }
+
+func (c *grpcConn) PeerKey() ed25519.PublicKey { return c.peerKey }
+
+// setPeer takes the address and the identity key of the peer from the
+// context of the stream.
+func (c *grpcConn) setPeer(ctx context.Context) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return
+	}
+	c.remote = p.Addr
+	if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+		c.peerKey = certificateKey(info.State)
+	}
+}
+
+func (c *grpcConn) Close() error {
+	c.closeOnce.Do(c.close)
+	return nil
+}
+
+// GRPCTransport accepts peers on the Gossip stream of the GossipServer
+// service. Every stream becomes a peer, just like a TCP connection.
+type GRPCTransport struct {
+	pb.UnimplementedGossipServerServer
+
+	listenAddr string
+	server     *grpc.Server
+	addPeer    chan<- *Peer
+	// creds encrypt and authenticate the streams when TLS is used.
+	creds credentials.TransportCredentials
+}
+
+// NewGRPCTransport creates a gRPC transport. When cfg is not nil the
+// streams are encrypted and authenticated with it, see NewTLSConfig.
+func NewGRPCTransport(addr string, cfg *tls.Config) *GRPCTransport {
+	t := &GRPCTransport{
+		listenAddr: addr,
+		creds:      insecure.NewCredentials(),
+	}
+	if cfg != nil {
+		t.creds = credentials.NewTLS(cfg)
+	}
+
+	return t
+}
+
+func (t *GRPCTransport) Listen(addPeer chan<- *Peer) error {
+	ln, err := net.Listen("tcp", t.listenAddr)
+	if err != nil {
+		return err
+	}
+
+	t.addPeer = addPeer
+	t.server = grpc.NewServer(
+		grpc.Creds(t.creds),
+		grpc.ConnectionTimeout(tlsHandshakeTimeout),
+		grpc.MaxRecvMsgSize(defaultMaxFrameSize),
+	)
+	pb.RegisterGossipServerServer(t.server, t)
+
+	go func() {
+		if err := t.server.Serve(ln); err != nil {
+			logrus.Errorf("grpc server error: %s", err)
+		}
+	}()
+
+	return nil
+}
+
+// Dial opens a Gossip stream to the GossipServer at addr.
+func (t *GRPCTransport) Dial(addr string) (MessageConn, error) {
+	cc, err := grpc.NewClient("passthrough:///"+addr,
+		grpc.WithTransportCredentials(t.creds),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(defaultMaxFrameSize)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := pb.NewGossipServerClient(cc).Gossip(ctx)
+	if err != nil {
+		cancel()
+		cc.Close()
+		return nil, err
+	}
+
+	conn := &grpcConn{
+		stream:        stream,
+		authenticated: t.secure(),
+		close: func() {
+			cancel()
+			if err := cc.Close(); err != nil {
+				logrus.Errorf("close grpc connection error: %s", err)
+			}
+		},
+	}
+	// The stream is opened on a connection that finished the TLS handshake,
+	// so the peer is known right away.
+	conn.setPeer(stream.Context())
+	conn.remote = NetAddr(addr)
+
+	return conn, nil
+}
+
+// secure reports whether the streams are secured with TLS.
+func (t *GRPCTransport) secure() bool {
+	return t.creds.Info().SecurityProtocol == "tls"
+}
+
+// Close stops the server, which ends the streams of all peers.
+func (t *GRPCTransport) Close() error {
+	if t.server != nil {
+		t.server.Stop()
+	}
+	return nil
+}
+
+// Gossip serves a stream opened by a peer. The stream ends when we close
+// the peer or the peer goes away.
+func (t *GRPCTransport) Gossip(stream pb.GossipServer_GossipServer) error {
+	done := make(chan struct{})
+	conn := &grpcConn{
+		stream:        stream,
+		remote:        NetAddr("unknown"),
+		authenticated: t.secure(),
+		close:         func() { close(done) },
+	}
+	conn.setPeer(stream.Context())
+
+	t.addPeer <- NewPeer(conn, false)
+
+	select {
+	case <-done:
+	case <-stream.Context().Done():
+	}
+
+	return nil
+}
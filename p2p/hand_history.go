@@ -0,0 +1,479 @@
+package p2p
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/koshiq/ggpoker/deck"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultHandHistoryMaxBytes = 10 << 20
+	maxRecentHandHistories     = 100
+)
+
+// HandHistory renders the given hand in the PokerStars hand history text
+// format. Only the hole cards of hero and the cards shown at showdown are
+// included.
+func (pg *PokerGame) HandHistory(table string, handNumber int, hero string) (string, error) {
+	pg.mu.RLock()
+	defer pg.mu.RUnlock()
+
+	var (
+		tmp      = NewPokerGame(pg.smallBlind, pg.bigBlind)
+		b        = new(strings.Builder)
+		started  bool
+		awarded  = make(map[string]int)
+		foldedOn = make(map[string]BettingRound)
+		uncalled = -1
+		seats    []string
+	)
+
+	for _, r := range pg.events {
+		ev, isStart := r.Event.(EventHandStarted)
+		if isStart && ev.HandNumber == handNumber {
+			started = true
+		}
+		if !started {
+			if err := tmp.apply(r.Event); err != nil {
+				return "", err
+			}
+			continue
+		}
+
+		// Bets before the event is applied, used to calculate the amounts of
+		// calls and raises.
+		var prevBet int
+		if e, ok := r.Event.(EventActionTaken); ok {
+			prevBet = tmp.players[e.Addr].Bet
+		}
+
+		if err := tmp.apply(r.Event); err != nil {
+			return "", err
+		}
+
+		switch e := r.Event.(type) {
+		case EventHandStarted:
+			seats = tmp.seatOrder()
+			button := tmp.players[seats[e.DealerPos]]
+			fmt.Fprintf(b, "PokerStars Hand #%d: Hold'em No Limit (%d/%d) - %s\n",
+				e.HandNumber, tmp.smallBlind, tmp.bigBlind, r.Time.UTC().Format("2006/01/02 15:04:05")+" UTC")
+			fmt.Fprintf(b, "Table '%s' %d-max Seat #%d is the button\n", table, defaultMaxPlayers, button.Position+1)
+			for _, addr := range seats {
+				player := tmp.players[addr]
+				fmt.Fprintf(b, "Seat %d: %s (%d in chips)\n", player.Position+1, addr, player.Stack)
+			}
+
+		case EventAntePosted:
+			fmt.Fprintf(b, "%s: posts the ante %d%s\n", e.Addr, e.Amount, allInSuffix(tmp.players[e.Addr]))
+
+		case EventBlindPosted:
+			blind := "big blind"
+			if tmp.players[e.Addr].IsSmallBlind && !tmp.players[e.Addr].IsBigBlind {
+				blind = "small blind"
+			}
+			fmt.Fprintf(b, "%s: posts %s %d%s\n", e.Addr, blind, e.Amount, allInSuffix(tmp.players[e.Addr]))
+
+		case EventCardsDealt:
+			if e.Addr == seats[0] {
+				fmt.Fprintf(b, "*** HOLE CARDS ***\n")
+			}
+			if e.Addr == hero {
+				fmt.Fprintf(b, "Dealt to %s %s\n", e.Addr, formatCards(e.Cards))
+			}
+
+		case EventActionTaken:
+			player := tmp.players[e.Addr]
+			switch e.Action {
+			case PlayerActionFold:
+				foldedOn[e.Addr] = tmp.currentRound
+				fmt.Fprintf(b, "%s: folds\n", e.Addr)
+			case PlayerActionCheck:
+				fmt.Fprintf(b, "%s: checks\n", e.Addr)
+			case PlayerActionCall:
+				fmt.Fprintf(b, "%s: calls %d%s\n", e.Addr, player.Bet-prevBet, allInSuffix(player))
+			case PlayerActionBet:
+				fmt.Fprintf(b, "%s: bets %d%s\n", e.Addr, player.Bet-prevBet, allInSuffix(player))
+			case PlayerActionRaise:
+				fmt.Fprintf(b, "%s: raises %d to %d%s\n", e.Addr, e.Amount, player.Bet, allInSuffix(player))
+			}
+
+		case EventStreetDealt:
+			board := tmp.communityCards
+			switch e.Round {
+			case Flop:
+				fmt.Fprintf(b, "*** FLOP *** %s\n", formatCards(board))
+			case Turn:
+				fmt.Fprintf(b, "*** TURN *** %s %s\n", formatCards(board[:3]), formatCards(e.Cards))
+			case River:
+				fmt.Fprintf(b, "*** RIVER *** %s %s\n", formatCards(board[:4]), formatCards(e.Cards))
+			}
+
+		case EventShowdownStarted:
+			uncalled = writeUncalledBet(b, tmp, uncalled)
+			fmt.Fprintf(b, "*** SHOW DOWN ***\n")
+
+		case EventCardsShown:
+			fmt.Fprintf(b, "%s: shows %s (%s)\n", e.Addr, formatCards(e.Cards), describeHand(tmp.handOf(e.Addr)))
+
+		case EventHandMucked:
+			fmt.Fprintf(b, "%s: mucks hand\n", e.Addr)
+
+		case EventTimerStopped:
+			if e.TimedOut {
+				fmt.Fprintf(b, "%s has timed out\n", e.Addr)
+			}
+
+		case EventPlayerDisconnected:
+			fmt.Fprintf(b, "%s is disconnected\n", e.Addr)
+
+		case EventPotAwarded:
+			uncalled = writeUncalledBet(b, tmp, uncalled)
+			amount := e.Amount
+			if e.Pot == len(tmp.pot)-1 && uncalled > 0 && len(tmp.pot[e.Pot].Players) == 1 {
+				amount -= uncalled
+			}
+			if amount <= 0 {
+				continue
+			}
+			awarded[e.Addr] += amount
+			fmt.Fprintf(b, "%s collected %d from %s\n", e.Addr, amount, potName(e.Pot, len(tmp.pot)))
+
+		case EventBountyWon:
+			fmt.Fprintf(b, "%s wins the %d bounty for eliminating %s", e.Addr, e.Cash+e.Bounty, e.From)
+			if e.Bounty > 0 {
+				fmt.Fprintf(b, " and their own bounty increases by %d to %d", e.Bounty, tmp.players[e.Addr].Bounty)
+			}
+			fmt.Fprintf(b, "\n")
+
+		case EventHandEnded:
+			if e.FoldWinner != "" {
+				fmt.Fprintf(b, "%s: doesn't show hand\n", e.FoldWinner)
+			}
+			writeSummary(b, tmp, seats, awarded, foldedOn, max(uncalled, 0))
+			return b.String(), nil
+		}
+	}
+
+	if !started {
+		return "", fmt.Errorf("hand %d not found", handNumber)
+	}
+
+	return "", fmt.Errorf("hand %d is still in progress", handNumber)
+}
+
+// writeUncalledBet writes the part of the biggest bet nobody called, the
+// first time it is called. It returns the uncalled amount.
+func writeUncalledBet(b *strings.Builder, pg *PokerGame, uncalled int) int {
+	if uncalled >= 0 {
+		return uncalled
+	}
+
+	addr, uncalled := pg.uncalledBet()
+	if uncalled > 0 {
+		fmt.Fprintf(b, "Uncalled bet (%d) returned to %s\n", uncalled, addr)
+	}
+
+	return uncalled
+}
+
+func writeSummary(b *strings.Builder, pg *PokerGame, seats []string, awarded map[string]int, foldedOn map[string]BettingRound, uncalled int) {
+	fmt.Fprintf(b, "*** SUMMARY ***\n")
+
+	total, rake := 0, 0
+	for i, pot := range pg.pot {
+		total += pot.Amount + pg.rakeOf(i)
+		rake += pg.rakeOf(i)
+	}
+	total -= uncalled
+
+	if len(pg.pot) > 1 {
+		parts := []string{}
+		for i, pot := range pg.pot {
+			amount := pot.Amount + pg.rakeOf(i)
+			if i == len(pg.pot)-1 {
+				amount -= uncalled
+			}
+			if amount > 0 {
+				name := potName(i, len(pg.pot))
+				parts = append(parts, fmt.Sprintf("%s%s %d.", strings.ToUpper(name[:1]), name[1:], amount))
+			}
+		}
+		fmt.Fprintf(b, "Total pot %d %s | Rake %d\n", total, strings.Join(parts, " "), rake)
+	} else {
+		fmt.Fprintf(b, "Total pot %d | Rake %d\n", total, rake)
+	}
+
+	if len(pg.communityCards) > 0 {
+		fmt.Fprintf(b, "Board %s\n", formatCards(pg.communityCards))
+	}
+
+	for _, addr := range seats {
+		player := pg.players[addr]
+
+		role := ""
+		if player.IsDealer {
+			role += " (button)"
+		}
+		if player.IsSmallBlind && !player.IsBigBlind {
+			role += " (small blind)"
+		}
+		if player.IsBigBlind {
+			role += " (big blind)"
+		}
+
+		var result string
+		switch {
+		case player.Folded:
+			round := foldedOn[addr]
+			if round == PreFlop {
+				result = "folded before Flop"
+			} else {
+				result = fmt.Sprintf("folded on the %s", round)
+			}
+		case player.Mucked:
+			result = "mucked"
+		case len(player.ShownCards) == len(player.HoleCards) && len(player.ShownCards) > 0:
+			hand := describeHand(pg.handOf(addr))
+			if won := awarded[addr]; won > 0 {
+				result = fmt.Sprintf("showed %s and won (%d) with %s", formatCards(player.ShownCards), won, hand)
+			} else {
+				result = fmt.Sprintf("showed %s and lost with %s", formatCards(player.ShownCards), hand)
+			}
+		default:
+			result = fmt.Sprintf("collected (%d)", awarded[addr])
+		}
+
+		fmt.Fprintf(b, "Seat %d: %s%s %s\n", player.Position+1, addr, role, result)
+	}
+}
+
+func potName(i, n int) string {
+	if n == 1 {
+		return "pot"
+	}
+	if i == 0 {
+		return "main pot"
+	}
+	return fmt.Sprintf("side pot-%d", i)
+}
+
+func allInSuffix(player *PlayerState) string {
+	if player.AllIn {
+		return " and is all-in"
+	}
+	return ""
+}
+
+func formatCards(cards []deck.Card) string {
+	parts := make([]string, len(cards))
+	for i, card := range cards {
+		parts[i] = card.Short()
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
+
+func describeHand(hand deck.Hand) string {
+	return strings.ToLower(hand.Rank.String())
+}
+
+// HandHistoryWriter writes the history of every finished hand of a table to
+// a rotating file and keeps the most recent ones in memory for the API.
+type HandHistoryWriter struct {
+	mu       sync.RWMutex
+	table    string
+	dir      string
+	maxBytes int64
+	file     *os.File
+	size     int64
+	recent   map[int]string
+	ohh      map[int]*OpenHandHistory
+	order    []int
+}
+
+func NewHandHistoryWriter(table, dir string, maxBytes int64) *HandHistoryWriter {
+	if maxBytes <= 0 {
+		maxBytes = defaultHandHistoryMaxBytes
+	}
+
+	return &HandHistoryWriter{
+		table:    table,
+		dir:      dir,
+		maxBytes: maxBytes,
+		recent:   make(map[int]string),
+		ohh:      make(map[int]*OpenHandHistory),
+	}
+}
+
+// Attach writes the history of every hand of the game once it ends. The
+// returned function stops writing.
+func (w *HandHistoryWriter) Attach(pg *PokerGame, hero string) func() {
+	return pg.Subscribe(func(r EventRecord) {
+		if _, ok := r.Event.(EventHandEnded); !ok {
+			return
+		}
+
+		// Event handlers run while the game is locked.
+		go func(handNumber int) {
+			text, err := pg.HandHistory(w.table, handNumber, hero)
+			if err != nil {
+				logrus.Errorf("hand history error: %s", err)
+				return
+			}
+			if err := w.Write(handNumber, text); err != nil {
+				logrus.Errorf("hand history write error: %s", err)
+			}
+			ohh, err := pg.OpenHandHistory(w.table, handNumber, hero)
+			if err != nil {
+				logrus.Errorf("open hand history error: %s", err)
+				return
+			}
+			w.WriteOpenHandHistory(handNumber, ohh)
+		}(r.HandNumber)
+	})
+}
+
+// Write stores the history of a single hand.
+func (w *HandHistoryWriter) Write(handNumber int, text string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.recent[handNumber]; !ok {
+		w.order = append(w.order, handNumber)
+	}
+	w.recent[handNumber] = text
+	if len(w.order) > maxRecentHandHistories {
+		delete(w.recent, w.order[0])
+		delete(w.ohh, w.order[0])
+		w.order = w.order[1:]
+	}
+
+	if w.dir == "" {
+		return nil
+	}
+
+	// Hands are separated by blank lines, as trackers expect.
+	b := []byte(text + "\n\n")
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return err
+		}
+	}
+	if w.size > 0 && w.size+int64(len(b)) > w.maxBytes {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := w.file.Write(b)
+	w.size += int64(n)
+
+	return err
+}
+
+// WriteOpenHandHistory keeps the Open Hand History of a hand in memory next
+// to its text history.
+func (w *HandHistoryWriter) WriteOpenHandHistory(handNumber int, h *OpenHandHistory) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.recent[handNumber]; ok {
+		w.ohh[handNumber] = h
+	}
+}
+
+// OpenHandHistory returns the Open Hand History of the given hand if it is
+// still in memory.
+func (w *HandHistoryWriter) OpenHandHistory(handNumber int) (*OpenHandHistory, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	h, ok := w.ohh[handNumber]
+	return h, ok
+}
+
+// History returns the history of the given hand if it is still in memory.
+func (w *HandHistoryWriter) History(handNumber int) (string, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	text, ok := w.recent[handNumber]
+	return text, ok
+}
+
+// Histories returns all hand histories in memory, oldest first.
+func (w *HandHistoryWriter) Histories() []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	histories := make([]string, 0, len(w.order))
+	for _, handNumber := range w.order {
+		histories = append(histories, w.recent[handNumber])
+	}
+
+	return histories
+}
+
+func (w *HandHistoryWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+
+	return w.file.Close()
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+func (w *HandHistoryWriter) path() string {
+	name := strings.Trim(unsafeFileChars.ReplaceAllString(w.table, "_"), "_")
+	return filepath.Join(w.dir, fmt.Sprintf("table_%s.txt", name))
+}
+
+// open opens the current file of the table, appending to what was written
+// before.
+func (w *HandHistoryWriter) open() error {
+	if err := os.MkdirAll(w.dir, 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(w.path(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	w.file = f
+	w.size = info.Size()
+
+	return nil
+}
+
+// rotate moves the current file aside when it is full and opens a new one.
+func (w *HandHistoryWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.file = nil
+
+	path := w.path()
+	rotated := strings.TrimSuffix(path, ".txt") + "." + time.Now().Format("20060102-150405.000") + ".txt"
+	if err := os.Rename(path, rotated); err != nil {
+		return err
+	}
+
+	return w.open()
+}
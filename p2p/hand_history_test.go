@@ -0,0 +1,80 @@
+package p2p
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandHistoryPokerStarsFormat(t *testing.T) {
+	game := playRecordedHand(t)
+
+	text, err := game.HandHistory("ggpoker", 1, ":1")
+	assert.Nil(t, err)
+
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	assert.True(t, strings.HasPrefix(lines[0], "PokerStars Hand #1: Hold'em No Limit (10/20) - "))
+	assert.Equal(t, "Table 'ggpoker' 6-max Seat #2 is the button", lines[1])
+	assert.Equal(t, "Seat 1: :1 (1000 in chips)", lines[2])
+	assert.Equal(t, "Seat 2: :2 (500 in chips)", lines[3])
+	assert.Equal(t, "Seat 3: :3 (1500 in chips)", lines[4])
+	assert.Equal(t, ":3: posts small blind 10", lines[5])
+	assert.Equal(t, ":1: posts big blind 20", lines[6])
+	assert.Equal(t, "*** HOLE CARDS ***", lines[7])
+	assert.Equal(t, "Dealt to :1 "+formatCards(game.players[":1"].HoleCards), lines[8])
+	assert.Equal(t, ":2: raises 40 to 60", lines[9])
+	assert.Equal(t, ":3: calls 50", lines[10])
+	assert.Equal(t, ":1: calls 40", lines[11])
+	assert.Equal(t, "*** FLOP *** "+formatCards(game.communityCards[:3]), lines[12])
+	assert.Equal(t, ":3: bets 100", lines[13])
+	assert.Equal(t, ":1: folds", lines[14])
+	assert.Equal(t, ":2: calls 100", lines[15])
+	assert.Contains(t, text, "*** SHOW DOWN ***\n")
+	assert.Contains(t, text, "*** SUMMARY ***\nTotal pot 380 | Rake 0\n")
+	assert.Contains(t, text, "Board "+formatCards(game.communityCards)+"\n")
+	assert.Contains(t, text, "Seat 1: :1 (big blind) folded on the Flop\n")
+
+	// Nobody else's hole cards are dealt in the history.
+	other, err := game.HandHistory("ggpoker", 1, "")
+	assert.Nil(t, err)
+	assert.NotContains(t, other, "Dealt to")
+}
+
+func TestHandHistoryUncalledBet(t *testing.T) {
+	game := newTestGame(t, 3)
+
+	assert.Nil(t, game.PlayerAction(":2", PlayerActionRaise, 40))
+	assert.Nil(t, game.PlayerAction(":3", PlayerActionFold, 0))
+	assert.Nil(t, game.PlayerAction(":1", PlayerActionFold, 0))
+
+	text, err := game.HandHistory("ggpoker", 1, "")
+	assert.Nil(t, err)
+	assert.Contains(t, text, "Uncalled bet (40) returned to :2\n:2 collected 50 from pot\n:2: doesn't show hand\n")
+	assert.Contains(t, text, "Total pot 50 | Rake 0\n")
+	assert.Contains(t, text, "Seat 2: :2 (button) collected (50)\n")
+}
+
+func TestHandHistoryWriterRotates(t *testing.T) {
+	dir := t.TempDir()
+	w := NewHandHistoryWriter(":3000", dir, 64)
+	defer w.Close()
+
+	assert.Nil(t, w.Write(1, strings.Repeat("a", 40)))
+	assert.Nil(t, w.Write(2, strings.Repeat("b", 40)))
+
+	files, err := filepath.Glob(filepath.Join(dir, "table_3000*.txt"))
+	assert.Nil(t, err)
+	assert.Len(t, files, 2)
+
+	b, err := os.ReadFile(filepath.Join(dir, "table_3000.txt"))
+	assert.Nil(t, err)
+	assert.Equal(t, strings.Repeat("b", 40)+"\n\n", string(b))
+
+	text, ok := w.History(1)
+	assert.True(t, ok)
+	assert.Equal(t, strings.Repeat("a", 40), text)
+	assert.Len(t, w.Histories(), 2)
+}
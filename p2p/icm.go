@@ -0,0 +1,377 @@
+package p2p
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ICM returns the expected prize of every stack with the Independent Chip
+// Model. The chance of a stack to finish first is its share of all chips,
+// the chance to finish in a later place is found the same way among the
+// stacks that are left. prizes are the prizes still to be paid, best place
+// first.
+func ICM(stacks []int, prizes []int) []float64 {
+	n := len(stacks)
+	places := min(len(prizes), n)
+	memo := make(map[int][]float64)
+
+	// equity returns the expected prizes of the places that are left once
+	// the players in taken finished in the best places.
+	var equity func(taken, place int) []float64
+	equity = func(taken, place int) []float64 {
+		if place == places {
+			return make([]float64, n)
+		}
+		if eq, ok := memo[taken]; ok {
+			return eq
+		}
+
+		left, playersLeft := 0, 0
+		for i, stack := range stacks {
+			if taken&(1<<i) == 0 {
+				left += stack
+				playersLeft++
+			}
+		}
+
+		eq := make([]float64, n)
+		for i, stack := range stacks {
+			if taken&(1<<i) != 0 {
+				continue
+			}
+			// Without chips left all players are as likely to finish next.
+			p := 1 / float64(playersLeft)
+			if left > 0 {
+				p = float64(stack) / float64(left)
+			}
+			if p == 0 {
+				continue
+			}
+			eq[i] += p * float64(prizes[place])
+			for j, v := range equity(taken|1<<i, place+1) {
+				eq[j] += p * v
+			}
+		}
+		memo[taken] = eq
+
+		return eq
+	}
+
+	return equity(0, 0)
+}
+
+// ChipChop splits the prizes by chips: every player is guaranteed the
+// lowest prize left, the rest is divided in proportion to the stacks.
+func ChipChop(stacks []int, prizes []int) []int {
+	total, chips := 0, 0
+	for _, prize := range prizes[:min(len(prizes), len(stacks))] {
+		total += prize
+	}
+	for _, stack := range stacks {
+		chips += stack
+	}
+
+	lowest := 0
+	if len(prizes) >= len(stacks) {
+		lowest = prizes[len(stacks)-1]
+	}
+	rest := total - lowest*len(stacks)
+
+	shares := make([]float64, len(stacks))
+	for i, stack := range stacks {
+		shares[i] = float64(lowest)
+		if chips > 0 {
+			shares[i] += float64(rest) * float64(stack) / float64(chips)
+		}
+	}
+
+	return roundDeal(stacks, shares, total)
+}
+
+// ICMDeal pays every player the ICM value of the stack.
+func ICMDeal(stacks []int, prizes []int) []int {
+	total := 0
+	for _, prize := range prizes[:min(len(prizes), len(stacks))] {
+		total += prize
+	}
+
+	return roundDeal(stacks, ICM(stacks, prizes), total)
+}
+
+// roundDeal rounds the shares down to whole amounts and hands out what is
+// left to the largest fractions, bigger stacks first on a tie.
+func roundDeal(stacks []int, shares []float64, total int) []int {
+	amounts := make([]int, len(shares))
+	order := make([]int, len(shares))
+	left := total
+	for i, share := range shares {
+		amounts[i] = int(math.Floor(share + 1e-9))
+		left -= amounts[i]
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		fa := shares[order[a]] - math.Floor(shares[order[a]]+1e-9)
+		fb := shares[order[b]] - math.Floor(shares[order[b]]+1e-9)
+		if math.Abs(fa-fb) > 1e-9 {
+			return fa > fb
+		}
+		return stacks[order[a]] > stacks[order[b]]
+	})
+	for i := 0; left > 0 && len(order) > 0; i++ {
+		amounts[order[i%len(order)]]++
+		left--
+	}
+
+	return amounts
+}
+
+type DealKind string
+
+const (
+	DealChipChop DealKind = "CHIP_CHOP"
+	DealICM      DealKind = "ICM"
+)
+
+// Deal is a proposal to split the prizes left among the players at the
+// final table. It is made once all of them accept.
+type Deal struct {
+	Kind     DealKind           `json:"kind"`
+	Stacks   map[string]int     `json:"stacks"`
+	ICM      map[string]float64 `json:"icm"`
+	Payouts  map[string]int     `json:"payouts"`
+	Accepted map[string]bool    `json:"accepted"`
+}
+
+func (d *Deal) copy() *Deal {
+	c := &Deal{
+		Kind:     d.Kind,
+		Stacks:   make(map[string]int),
+		ICM:      make(map[string]float64),
+		Payouts:  make(map[string]int),
+		Accepted: make(map[string]bool),
+	}
+	for addr := range d.Stacks {
+		c.Stacks[addr] = d.Stacks[addr]
+		c.ICM[addr] = d.ICM[addr]
+		c.Payouts[addr] = d.Payouts[addr]
+		c.Accepted[addr] = d.Accepted[addr]
+	}
+
+	return c
+}
+
+// ProposeDeal proposes a deal of the given kind to the players left. No
+// hands are dealt while the deal is open.
+func (t *Tournament) ProposeDeal(kind DealKind) (*Deal, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.status != TournamentRunning {
+		return nil, fmt.Errorf("tournament is %s", t.status)
+	}
+	if err := t.knockOut(); err != nil {
+		return nil, fmt.Errorf("can't propose a deal: %w", err)
+	}
+	if t.status == TournamentFinished {
+		return nil, fmt.Errorf("tournament is %s", t.status)
+	}
+
+	t.game.mu.RLock()
+	addrs := t.game.allSeats()
+	stacks := make([]int, len(addrs))
+	for i, addr := range addrs {
+		stacks[i] = t.game.players[addr].Stack
+	}
+	t.game.mu.RUnlock()
+
+	prizes := make([]int, len(addrs))
+	for i := range prizes {
+		prizes[i] = t.prize(i + 1)
+	}
+
+	var payouts []int
+	switch kind {
+	case DealChipChop:
+		payouts = ChipChop(stacks, prizes)
+	case DealICM:
+		payouts = ICMDeal(stacks, prizes)
+	default:
+		return nil, fmt.Errorf("unknown deal %q", kind)
+	}
+
+	equity := ICM(stacks, prizes)
+	deal := &Deal{
+		Kind:     kind,
+		Stacks:   make(map[string]int),
+		ICM:      make(map[string]float64),
+		Payouts:  make(map[string]int),
+		Accepted: make(map[string]bool),
+	}
+	for i, addr := range addrs {
+		deal.Stacks[addr] = stacks[i]
+		deal.ICM[addr] = equity[i]
+		deal.Payouts[addr] = payouts[i]
+		deal.Accepted[addr] = false
+	}
+	t.deal = deal
+
+	return deal.copy(), nil
+}
+
+// Deal returns the open deal, or nil if there is none.
+func (t *Tournament) Deal() *Deal {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.deal == nil {
+		return nil
+	}
+	return t.deal.copy()
+}
+
+// AcceptDeal accepts the open deal for the player. Once every player
+// accepted the tournament is over and paid out as agreed, players finish
+// in the order of their stacks.
+func (t *Tournament) AcceptDeal(addr string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.deal == nil {
+		return fmt.Errorf("there is no deal to accept")
+	}
+	if _, ok := t.deal.Accepted[addr]; !ok {
+		return fmt.Errorf("player %s is not part of the deal", addr)
+	}
+	t.deal.Accepted[addr] = true
+
+	for _, accepted := range t.deal.Accepted {
+		if !accepted {
+			return nil
+		}
+	}
+
+	addrs := make([]string, 0, len(t.deal.Stacks))
+	for addr := range t.deal.Stacks {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		if t.deal.Stacks[addrs[i]] != t.deal.Stacks[addrs[j]] {
+			return t.deal.Stacks[addrs[i]] < t.deal.Stacks[addrs[j]]
+		}
+		return addrs[i] > addrs[j]
+	})
+	t.game.mu.RLock()
+	for i, addr := range addrs {
+		// Players making a deal keep their own bounty.
+		player := t.game.players[addr]
+		t.finishes = append(t.finishes, TournamentFinish{
+			Addr:     addr,
+			Place:    len(addrs) - i,
+			Prize:    t.deal.Payouts[addr],
+			Bounties: player.BountiesWon + player.Bounty,
+			Deal:     true,
+		})
+	}
+	t.game.mu.RUnlock()
+	t.status = TournamentFinished
+
+	logrus.WithField("deal", t.deal.Kind).Info("tournament finished with a deal")
+
+	return nil
+}
+
+// RejectDeal rejects the open deal for the player, after which the
+// tournament goes on.
+func (t *Tournament) RejectDeal(addr string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.deal == nil {
+		return fmt.Errorf("there is no deal to reject")
+	}
+	if _, ok := t.deal.Accepted[addr]; !ok {
+		return fmt.Errorf("player %s is not part of the deal", addr)
+	}
+	t.deal = nil
+
+	if t.auto {
+		go t.dealAfter(0)
+	}
+
+	return nil
+}
+
+// ProposeDeal proposes a deal of the given kind to the players left in the
+// tournament of the table. Every node works the deal out from its own copy
+// of the stacks, so only the kind is sent.
+func (g *GameState) ProposeDeal(kind DealKind) (*Deal, error) {
+	if g.tournament == nil {
+		return nil, fmt.Errorf("not playing a tournament")
+	}
+	if status := GameStatus(g.currentStatus.Get()); status != GameStatusPlayerReady {
+		return nil, fmt.Errorf("can't propose a deal while the game is %s", status)
+	}
+
+	deal, err := g.tournament.ProposeDeal(kind)
+	if err != nil {
+		return nil, err
+	}
+	g.sendToPlayers(MessageDealProposed{Kind: kind}, g.getOtherPlayers()...)
+
+	return deal, nil
+}
+
+// AnswerDeal accepts or rejects the open deal for us.
+func (g *GameState) AnswerDeal(accept bool) error {
+	if err := g.answerDeal(g.listenAddr, accept); err != nil {
+		return err
+	}
+	g.sendToPlayers(MessageDealAnswer{Accept: accept}, g.getOtherPlayers()...)
+
+	return nil
+}
+
+// handleDealProposed is getting called when a player in the network
+// proposes a deal.
+func (g *GameState) handleDealProposed(from string, msg MessageDealProposed) error {
+	if g.tournament == nil {
+		return fmt.Errorf("player (%s) proposed a deal, we are not playing a tournament", from)
+	}
+
+	_, err := g.tournament.ProposeDeal(msg.Kind)
+	return err
+}
+
+// handleDealAnswer is getting called when a player in the network accepts
+// or rejects the open deal.
+func (g *GameState) handleDealAnswer(from string, msg MessageDealAnswer) error {
+	return g.answerDeal(from, msg.Accept)
+}
+
+func (g *GameState) answerDeal(addr string, accept bool) error {
+	if g.tournament == nil {
+		return fmt.Errorf("not playing a tournament")
+	}
+
+	var err error
+	if accept {
+		err = g.tournament.AcceptDeal(addr)
+	} else {
+		err = g.tournament.RejectDeal(addr)
+	}
+	if err != nil {
+		return err
+	}
+
+	// No hands are dealt while the deal is open, a rejected deal lets the
+	// dealer go on.
+	if _, isDealer := g.getCurrentDealerAddr(); isDealer {
+		g.dealLater()
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package p2p
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestICM(t *testing.T) {
+	equity := ICM([]int{5000, 3000, 2000}, []int{50, 30, 20})
+	assert.InDelta(t, 38.393, equity[0], 0.001)
+	assert.InDelta(t, 100, equity[0]+equity[1]+equity[2], 0.001)
+	assert.Greater(t, equity[1], equity[2])
+
+	// Only the places that are paid count.
+	equity = ICM([]int{1000, 1000, 1000, 1000}, []int{60, 40})
+	for _, eq := range equity {
+		assert.InDelta(t, 25, eq, 0.001)
+	}
+}
+
+func TestDeals(t *testing.T) {
+	assert.Equal(t, []int{54, 46}, ChipChop([]int{6000, 4000}, []int{70, 30}))
+	assert.Equal(t, []int{34, 33, 33}, ChipChop([]int{100, 100, 100}, []int{50, 30, 20}))
+
+	// The chip leader gets less than a chip chop with ICM.
+	payouts := ICMDeal([]int{8000, 1000, 1000}, []int{50, 30, 20})
+	assert.Equal(t, 100, payouts[0]+payouts[1]+payouts[2])
+	assert.Less(t, payouts[0], ChipChop([]int{8000, 1000, 1000}, []int{50, 30, 20})[0])
+}
+
+func TestTournamentDeal(t *testing.T) {
+	now := time.Now()
+	tournament := newTestTournament(t, TournamentConfig{
+		Seats:         3,
+		StartingStack: 100,
+		Levels:        []BlindLevel{{SmallBlind: 5, BigBlind: 10}},
+		BuyIn:         10,
+		Payouts:       []int{50, 30, 20},
+		StartWhenFull: true,
+	}, &now)
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, tournament.Register(fmt.Sprintf(":%d", i+1)))
+	}
+
+	assert.Nil(t, tournament.NextHand())
+	_, err := tournament.ProposeDeal(DealICM)
+	assert.NotNil(t, err)
+	foldToBigBlind(t, tournament.Game())
+
+	// The small blind of :3 went to :1 in the big blind, the chip chop
+	// rounds to equal shares.
+	deal, err := tournament.ProposeDeal(DealChipChop)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{":1": 105, ":2": 100, ":3": 95}, deal.Stacks)
+	assert.Equal(t, map[string]int{":1": 10, ":2": 10, ":3": 10}, deal.Payouts)
+	assert.NotNil(t, tournament.NextHand())
+
+	assert.Nil(t, tournament.RejectDeal(":2"))
+	assert.Nil(t, tournament.Deal())
+	assert.Nil(t, tournament.NextHand())
+	foldToBigBlind(t, tournament.Game())
+
+	deal, err = tournament.ProposeDeal(DealICM)
+	assert.Nil(t, err)
+	assert.NotNil(t, tournament.AcceptDeal(":4"))
+	for addr := range deal.Stacks {
+		assert.Equal(t, TournamentRunning, tournament.Status())
+		assert.Nil(t, tournament.AcceptDeal(addr))
+	}
+	assert.Equal(t, TournamentFinished, tournament.Status())
+
+	total := 0
+	for i, finish := range tournament.Standings() {
+		assert.Equal(t, i+1, finish.Place)
+		assert.True(t, finish.Deal)
+		assert.Equal(t, deal.Payouts[finish.Addr], finish.Prize)
+		total += finish.Prize
+	}
+	assert.Equal(t, 30, total)
+}
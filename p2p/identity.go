@@ -0,0 +1,107 @@
+package p2p
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// PlayerID is the ID of the player holding the identity key, the hex
+// encoded public key.
+func PlayerID(key ed25519.PublicKey) string {
+	return hex.EncodeToString(key)
+}
+
+// parsePlayerID returns the public key of a player ID.
+func parsePlayerID(id string) (ed25519.PublicKey, error) {
+	key, err := hex.DecodeString(id)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid player ID %q", id)
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+func newIdentity() ed25519.PrivateKey {
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// Sign signs the message with the identity key of the sender.
+func (msg *Message) Sign(key ed25519.PrivateKey) error {
+	b, err := msg.signedBytes()
+	if err != nil {
+		return err
+	}
+	msg.Signature = ed25519.Sign(key, b)
+
+	return nil
+}
+
+// Verify checks that the message is signed by the player it is from.
+func (msg *Message) Verify() error {
+	if len(msg.Signature) == 0 {
+		return errors.New("message is not signed")
+	}
+	key, err := parsePlayerID(msg.From)
+	if err != nil {
+		return err
+	}
+	b, err := msg.signedBytes()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(key, b, msg.Signature) {
+		return fmt.Errorf("invalid signature of %T from %s", msg.Payload, msg.From)
+	}
+
+	return nil
+}
+
+// signedBytes is the canonical encoding of the message without the
+// signature, see canonicalBytes. It is the same on every transport and with
+// every version of the protobuf library.
+func (msg *Message) signedBytes() ([]byte, error) {
+	env, err := toEnvelope(&Message{From: msg.From, Payload: msg.Payload, Hand: msg.Hand, Seq: msg.Seq})
+	if err != nil {
+		return nil, err
+	}
+	return canonicalBytes(env)
+}
+
+// seqWindowSize is how many sequence numbers of a player we remember.
+const seqWindowSize = 1024
+
+// seqWindow remembers the sequence numbers of the messages of a player, so
+// a message that is sent again is dropped. Messages may arrive a little out
+// of order, but those more than seqWindowSize below the highest number we
+// got are too old to tell and dropped as well.
+type seqWindow struct {
+	highest uint64
+	seen    map[uint64]bool
+}
+
+func newSeqWindow() *seqWindow {
+	return &seqWindow{seen: make(map[uint64]bool)}
+}
+
+// accept reports whether seq is new and remembers it.
+func (w *seqWindow) accept(seq uint64) bool {
+	if seq+seqWindowSize <= w.highest || w.seen[seq] {
+		return false
+	}
+	w.seen[seq] = true
+	if seq > w.highest {
+		w.highest = seq
+		for s := range w.seen {
+			if s+seqWindowSize <= w.highest {
+				delete(w.seen, s)
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,194 @@
+package p2p
+
+import (
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	pb "github.com/koshiq/ggpoker/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageSignature(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(nil)
+	assert.Nil(t, err)
+	id := PlayerID(pub)
+
+	msg := NewMessage(id, MessageCardKeys{HandNumber: 1, Keys: map[int][]byte{3: {1}, 1: {2}, 2: {3}}})
+	assert.NotNil(t, msg.Verify())
+	assert.Nil(t, msg.Sign(key))
+	assert.Nil(t, msg.Verify())
+
+	// The signature survives both transports.
+	got, err := fromEnvelope(mustEnvelope(t, msg))
+	assert.Nil(t, err)
+	assert.Nil(t, got.Verify())
+
+	tampered := NewMessage(id, MessageCardKeys{HandNumber: 2, Keys: msg.Payload.(MessageCardKeys).Keys})
+	tampered.Signature = msg.Signature
+	assert.ErrorContains(t, tampered.Verify(), "invalid signature")
+
+	other, _, err := ed25519.GenerateKey(nil)
+	assert.Nil(t, err)
+	spoofed := NewMessage(PlayerID(other), msg.Payload)
+	spoofed.Signature = msg.Signature
+	assert.ErrorContains(t, spoofed.Verify(), "invalid signature")
+
+	spoofed.From = ":3000"
+	assert.ErrorContains(t, spoofed.Verify(), "invalid player ID")
+
+	// The hand and the sequence number are signed as well.
+	msg.Hand++
+	assert.ErrorContains(t, msg.Verify(), "invalid signature")
+	msg.Hand--
+	msg.Seq++
+	assert.ErrorContains(t, msg.Verify(), "invalid signature")
+}
+
+func mustEnvelope(t *testing.T, msg *Message) *pb.Envelope {
+	env, err := toEnvelope(msg)
+	assert.Nil(t, err)
+	return env
+}
+
+func TestServerRefusesUnsignedPlayers(t *testing.T) {
+	network := NewMemoryNetwork()
+	s := NewServerWithTransport(ServerConfig{Version: "test", ListenAddr: ":3000"}, network.Transport(":3000"))
+	assert.Nil(t, s.Start())
+
+	// A handshake without a signature is refused.
+	conn, err := network.Transport(":4000").Dial(":3000")
+	assert.Nil(t, err)
+	pub, key, err := ed25519.GenerateKey(nil)
+	assert.Nil(t, err)
+	hs := NewMessage(PlayerID(pub), Handshake{Version: "test", ListenAddr: ":4000"})
+	assert.Nil(t, conn.Send(hs))
+	_, err = conn.Receive()
+	assert.NotNil(t, err)
+	assert.Empty(t, s.Peers())
+
+	// A signed one is not, and the player is known by the key.
+	conn, err = network.Transport(":4000").Dial(":3000")
+	assert.Nil(t, err)
+	assert.Nil(t, hs.Sign(key))
+	assert.Nil(t, conn.Send(hs))
+	reply, err := conn.Receive()
+	assert.Nil(t, err)
+	assert.Equal(t, s.ID(), reply.From)
+	assert.Nil(t, reply.Verify())
+	assert.Eventually(t, func() bool { return len(s.Peers()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{PlayerID(pub)}, s.Peers())
+
+	// Messages have to be signed by the player they are from.
+	ready := NewMessage(PlayerID(pub), MessageReady{})
+	assert.ErrorContains(t, s.handleMessage(ready), "not signed")
+	assert.Nil(t, ready.Sign(s.Identity))
+	assert.ErrorContains(t, s.handleMessage(ready), "invalid signature")
+	ready.Seq = hs.Seq + 1
+	assert.Nil(t, ready.Sign(key))
+	assert.Nil(t, s.handleMessage(ready))
+
+	// Once.
+	assert.ErrorContains(t, s.handleMessage(ready), "replayed")
+
+	// Actions have to be of the hand we are at.
+	action := NewMessage(PlayerID(pub), MessagePlayerAction{Action: PlayerActionFold})
+	action.Hand = 5
+	action.Seq = ready.Seq + 1
+	assert.Nil(t, action.Sign(key))
+	assert.ErrorContains(t, s.handleMessage(action), "of hand 5")
+
+	// And the player has to be at the table.
+	_, strangerKey, err := ed25519.GenerateKey(nil)
+	assert.Nil(t, err)
+	stranger := NewMessage(PlayerID(strangerKey.Public().(ed25519.PublicKey)), MessageReady{})
+	assert.Nil(t, stranger.Sign(strangerKey))
+	assert.ErrorContains(t, s.handleMessage(stranger), "unknown player")
+}
+
+func TestServerBindsPlayerToTLSIdentity(t *testing.T) {
+	addr := freeAddr(t)
+	s := NewServer(ServerConfig{Version: "test", ListenAddr: addr})
+	assert.Nil(t, s.Start())
+	defer s.transport.Close()
+
+	_, tlsKey, err := ed25519.GenerateKey(nil)
+	assert.Nil(t, err)
+	cfg, err := NewTLSConfig(tlsKey, nil)
+	assert.Nil(t, err)
+	conn, err := NewTLSTransport(":4000", cfg).Dial(addr)
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	// The handshake is signed, but by another key than the one of the
+	// TLS certificate.
+	pub, key, err := ed25519.GenerateKey(nil)
+	assert.Nil(t, err)
+	hs := NewMessage(PlayerID(pub), Handshake{Version: "test", ListenAddr: ":4000"})
+	assert.Nil(t, hs.Sign(key))
+	assert.Nil(t, conn.Send(hs))
+	_, err = conn.Receive()
+	assert.NotNil(t, err)
+	assert.Empty(t, s.Peers())
+}
+
+// keylessConn is a TLS connection of which the peer has no key.
+type keylessConn struct {
+	MessageConn
+}
+
+func (keylessConn) Authenticated() bool        { return true }
+func (keylessConn) PeerKey() ed25519.PublicKey { return nil }
+
+func TestServerRefusesUnauthenticatedPlayers(t *testing.T) {
+	network := NewMemoryNetwork()
+	s := NewServerWithTransport(ServerConfig{Version: "test", ListenAddr: ":3000"}, network.Transport(":3000"))
+
+	addPeer := make(chan *Peer, 1)
+	assert.Nil(t, network.Transport(":5000").Listen(addPeer))
+	conn, err := network.Transport(":4000").Dial(":5000")
+	assert.Nil(t, err)
+	peer := <-addPeer
+
+	pub, key, err := ed25519.GenerateKey(nil)
+	assert.Nil(t, err)
+	hs := NewMessage(PlayerID(pub), Handshake{Version: "test", ListenAddr: ":4000"})
+	assert.Nil(t, hs.Sign(key))
+	assert.Nil(t, conn.Send(hs))
+	_, err = s.handshake(NewPeer(keylessConn{peer.conn}, false))
+	assert.ErrorContains(t, err, "did not authenticate")
+}
+
+func TestSeqWindow(t *testing.T) {
+	w := newSeqWindow()
+	assert.True(t, w.accept(5))
+	assert.False(t, w.accept(5))
+	assert.True(t, w.accept(3))
+	assert.True(t, w.accept(2000))
+	assert.False(t, w.accept(3))
+	assert.True(t, w.accept(2000-seqWindowSize+1))
+	assert.False(t, w.accept(2000-seqWindowSize))
+	assert.False(t, w.accept(2000))
+	assert.Len(t, w.seen, 2)
+}
+
+func TestPeerDropsMessagesInTheNameOfOthers(t *testing.T) {
+	network := NewMemoryNetwork()
+	addPeer := make(chan *Peer, 1)
+	assert.Nil(t, network.Transport(":3000").Listen(addPeer))
+	conn, err := network.Transport(":4000").Dial(":3000")
+	assert.Nil(t, err)
+	peer := <-addPeer
+	peer.id = "a"
+
+	msgch := make(chan *Message, 2)
+	delch := make(chan *Peer, 1)
+	go peer.ReadLoop(msgch, delch)
+	assert.Nil(t, conn.Send(NewMessage("b", MessageReady{})))
+	assert.Nil(t, conn.Send(NewMessage("a", MessageReady{})))
+	conn.Close()
+
+	assert.Equal(t, peer, <-delch)
+	assert.Len(t, msgch, 1)
+	assert.Equal(t, "a", (<-msgch).From)
+}
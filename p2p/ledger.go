@@ -0,0 +1,133 @@
+package p2p
+
+import (
+	"fmt"
+	"time"
+)
+
+// BuyInConfig holds the buy-in limits of a cash table. Zero values mean no
+// limit.
+type BuyInConfig struct {
+	Min int
+	Max int
+}
+
+type LedgerReason string
+
+const (
+	LedgerBuyIn LedgerReason = "BUY_IN"
+	LedgerRebuy LedgerReason = "REBUY"
+	LedgerTopUp LedgerReason = "TOP_UP"
+)
+
+// LedgerEntry is a single change of chips brought to the table.
+type LedgerEntry struct {
+	Seq    uint64       `json:"seq"`
+	Time   time.Time    `json:"time"`
+	Addr   string       `json:"addr"`
+	Reason LedgerReason `json:"reason"`
+	Amount int          `json:"amount"`
+}
+
+// SetBuyIn sets the buy-in limits of the table.
+func (pg *PokerGame) SetBuyIn(cfg BuyInConfig) {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	pg.buyIn = cfg
+}
+
+func (pg *PokerGame) validateBuyIn(amount int) error {
+	if amount <= 0 {
+		return fmt.Errorf("invalid buy-in %d", amount)
+	}
+	if pg.buyIn.Min > 0 && amount < pg.buyIn.Min {
+		return fmt.Errorf("buy-in %d is below the minimum of %d", amount, pg.buyIn.Min)
+	}
+	if pg.buyIn.Max > 0 && amount > pg.buyIn.Max {
+		return fmt.Errorf("buy-in %d is above the maximum of %d", amount, pg.buyIn.Max)
+	}
+	return nil
+}
+
+// canAddChips reports an error when chips can't be added to the stack of
+// the player, which is the case while the player is dealt in a hand.
+func (pg *PokerGame) canAddChips(player *PlayerState) error {
+	if pg.gameStarted && !player.SittingOut {
+		return fmt.Errorf("cannot add chips during a hand")
+	}
+	return nil
+}
+
+// Rebuy buys a busted player back in.
+func (pg *PokerGame) Rebuy(addr string, amount int) error {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	player, exists := pg.players[addr]
+	if !exists {
+		return fmt.Errorf("player %s not found", addr)
+	}
+
+	if player.Stack > 0 {
+		return fmt.Errorf("player %s is not busted", addr)
+	}
+
+	if err := pg.canAddChips(player); err != nil {
+		return err
+	}
+
+	if err := pg.validateBuyIn(amount); err != nil {
+		return err
+	}
+
+	return pg.record(EventChipsAdded{Addr: addr, Amount: amount, Reason: LedgerRebuy})
+}
+
+// TopUp adds chips to the stack of the player between hands. The stack can
+// not be topped up above the maximum buy-in.
+func (pg *PokerGame) TopUp(addr string, amount int) error {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	player, exists := pg.players[addr]
+	if !exists {
+		return fmt.Errorf("player %s not found", addr)
+	}
+
+	if player.Stack == 0 {
+		return fmt.Errorf("player %s is busted and has to rebuy", addr)
+	}
+
+	if err := pg.canAddChips(player); err != nil {
+		return err
+	}
+
+	if amount <= 0 {
+		return fmt.Errorf("invalid top-up %d", amount)
+	}
+
+	if pg.buyIn.Max > 0 && player.Stack+amount > pg.buyIn.Max {
+		return fmt.Errorf("top-up of %d exceeds the maximum stack of %d", amount, pg.buyIn.Max)
+	}
+
+	return pg.record(EventChipsAdded{Addr: addr, Amount: amount, Reason: LedgerTopUp})
+}
+
+// Ledger returns every buy-in, rebuy and top-up of the table in order.
+func (pg *PokerGame) Ledger() []LedgerEntry {
+	pg.mu.RLock()
+	defer pg.mu.RUnlock()
+
+	ledger := []LedgerEntry{}
+	for _, r := range pg.events {
+		switch e := r.Event.(type) {
+		case EventPlayerJoined:
+			ledger = append(ledger, LedgerEntry{Seq: r.Seq, Time: r.Time, Addr: e.Addr, Reason: LedgerBuyIn, Amount: e.Stack})
+		case EventChipsAdded:
+			ledger = append(ledger, LedgerEntry{Seq: r.Seq, Time: r.Time, Addr: e.Addr, Reason: e.Reason, Amount: e.Amount})
+		}
+	}
+
+	return ledger
+}
@@ -0,0 +1,61 @@
+package p2p
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuyInLimits(t *testing.T) {
+	game := NewPokerGame(10, 20)
+	game.SetBuyIn(BuyInConfig{Min: 400, Max: 2000})
+
+	assert.NotNil(t, game.AddPlayer(":1", 399, 0))
+	assert.NotNil(t, game.AddPlayer(":1", 2001, 0))
+	assert.Nil(t, game.AddPlayer(":1", 1000, 0))
+	assert.Nil(t, game.AddPlayer(":2", 2000, 1))
+	assert.Nil(t, game.AddPlayer(":3", 400, 2))
+
+	// No chips can be added during a hand.
+	assert.Nil(t, game.StartNewHand())
+	assert.NotNil(t, game.TopUp(":3", 100))
+	foldToBigBlind(t, game)
+
+	assert.Nil(t, game.TopUp(":3", 100))
+	assert.NotNil(t, game.TopUp(":2", 10))
+	assert.NotNil(t, game.Rebuy(":3", 1000))
+
+	ledger := game.Ledger()
+	assert.Len(t, ledger, 4)
+	assert.Equal(t, LedgerEntry{Seq: ledger[3].Seq, Time: ledger[3].Time, Addr: ":3", Reason: LedgerTopUp, Amount: 100}, ledger[3])
+	assert.Equal(t, LedgerBuyIn, ledger[0].Reason)
+}
+
+func TestRebuyWhenBusted(t *testing.T) {
+	game := NewPokerGame(10, 20)
+	game.SetBuyIn(BuyInConfig{Min: 400, Max: 2000})
+	for i, addr := range []string{":1", ":2", ":3"} {
+		assert.Nil(t, game.AddPlayer(addr, 1000, i))
+	}
+	assert.Nil(t, game.StartNewHand())
+	foldToBigBlind(t, game)
+
+	// :3 lost everything and is not dealt in anymore.
+	game.players[":3"].Stack = 0
+	assert.NotNil(t, game.TopUp(":3", 1000))
+	assert.NotNil(t, game.SitIn(":3", false))
+	assert.Nil(t, game.StartNewHand())
+	assert.True(t, game.players[":3"].SittingOut)
+
+	// Busted players can rebuy during a hand they are not dealt in.
+	assert.NotNil(t, game.Rebuy(":3", 300))
+	assert.Nil(t, game.Rebuy(":3", 500))
+	assert.NotNil(t, game.Rebuy(":3", 500))
+	assert.Equal(t, 500, game.players[":3"].Stack)
+	assert.Nil(t, game.SitIn(":3", false))
+	foldToBigBlind(t, game)
+
+	assert.Nil(t, game.StartNewHand())
+	assert.False(t, game.players[":3"].SittingOut)
+	assert.Equal(t, LedgerRebuy, game.Ledger()[3].Reason)
+}
@@ -0,0 +1,153 @@
+package p2p
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"sync"
+)
+
+// memPipeSize is how many frames a direction of an in-memory connection
+// buffers before writes block.
+const memPipeSize = 1024
+
+// MemoryNetwork connects the in-memory transports of a table in a single
+// process, for tests and simulations.
+type MemoryNetwork struct {
+	lock      sync.Mutex
+	listeners map[string]chan<- *Peer
+}
+
+func NewMemoryNetwork() *MemoryNetwork {
+	return &MemoryNetwork{
+		listeners: make(map[string]chan<- *Peer),
+	}
+}
+
+// Transport returns the transport of the player listening at addr.
+func (n *MemoryNetwork) Transport(addr string) *MemoryTransport {
+	return &MemoryTransport{
+		network:    n,
+		listenAddr: addr,
+	}
+}
+
+// MemoryTransport is a transport of a MemoryNetwork. Messages go through
+// the codec just like on TCP, so players never share any memory.
+type MemoryTransport struct {
+	network    *MemoryNetwork
+	listenAddr string
+}
+
+func (t *MemoryTransport) Listen(addPeer chan<- *Peer) error {
+	t.network.lock.Lock()
+	defer t.network.lock.Unlock()
+
+	if _, ok := t.network.listeners[t.listenAddr]; ok {
+		return fmt.Errorf("address %s already in use", t.listenAddr)
+	}
+	t.network.listeners[t.listenAddr] = addPeer
+
+	return nil
+}
+
+func (t *MemoryTransport) Dial(addr string) (MessageConn, error) {
+	t.network.lock.Lock()
+	addPeer, ok := t.network.listeners[addr]
+	t.network.lock.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("connection refused by %s", addr)
+	}
+
+	local, remote := newMemPipe(NetAddr(t.listenAddr), NetAddr(addr))
+	addPeer <- NewPeer(newMemConn(remote), false)
+
+	return newMemConn(local), nil
+}
+
+func (t *MemoryTransport) Close() error {
+	t.network.lock.Lock()
+	defer t.network.lock.Unlock()
+
+	delete(t.network.listeners, t.listenAddr)
+	return nil
+}
+
+// memPipe is one end of a buffered in-memory byte stream. Both ends share
+// done, closing either one closes the stream.
+type memPipe struct {
+	remote  net.Addr
+	in      <-chan []byte
+	out     chan<- []byte
+	pending []byte
+
+	closeOnce *sync.Once
+	done      chan struct{}
+}
+
+func newMemPipe(a, b net.Addr) (*memPipe, *memPipe) {
+	ab := make(chan []byte, memPipeSize)
+	ba := make(chan []byte, memPipeSize)
+	once, done := new(sync.Once), make(chan struct{})
+
+	return &memPipe{remote: b, in: ba, out: ab, closeOnce: once, done: done},
+		&memPipe{remote: a, in: ab, out: ba, closeOnce: once, done: done}
+}
+
+// Read returns what was written before the stream was closed, then io.EOF.
+func (p *memPipe) Read(b []byte) (int, error) {
+	if len(p.pending) == 0 {
+		select {
+		case p.pending = <-p.in:
+		default:
+			select {
+			case p.pending = <-p.in:
+			case <-p.done:
+				return 0, io.EOF
+			}
+		}
+	}
+
+	n := copy(b, p.pending)
+	p.pending = p.pending[n:]
+	return n, nil
+}
+
+func (p *memPipe) Write(b []byte) (int, error) {
+	frame := make([]byte, len(b))
+	copy(frame, b)
+
+	select {
+	case <-p.done:
+		return 0, net.ErrClosed
+	default:
+	}
+	select {
+	case p.out <- frame:
+		return len(b), nil
+	case <-p.done:
+		return 0, net.ErrClosed
+	}
+}
+
+func (p *memPipe) Close() error {
+	p.closeOnce.Do(func() { close(p.done) })
+	return nil
+}
+
+// memConn is an in-memory connection carrying frames, see Codec.
+type memConn struct {
+	*memPipe
+	codec *Codec
+}
+
+func newMemConn(p *memPipe) MessageConn {
+	return &memConn{
+		memPipe: p,
+		codec:   NewCodec(p, defaultMaxFrameSize),
+	}
+}
+
+func (c *memConn) Send(msg *Message) error    { return c.codec.Encode(msg) }
+func (c *memConn) Receive() (*Message, error) { return c.codec.Decode() }
+func (c *memConn) RemoteAddr() net.Addr       { return c.remote }
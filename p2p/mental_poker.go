@@ -0,0 +1,394 @@
+package p2p
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/koshiq/ggpoker/deck"
+)
+
+// faceDownShort is how a card that was never revealed is written down.
+const faceDownShort = "??"
+
+// mentalDeck is our part in dealing a hand with mental poker. The deck is
+// encrypted and shuffled by every player in turn, after that every player
+// swaps their encryption for a key per position in the deck. A card is
+// known once all players revealed their key for its position, so nobody
+// can look at a card on their own.
+type mentalDeck struct {
+	mu         sync.Mutex
+	handNumber int
+	// players are all players holding keys, the ones the deck went around.
+	players    []string
+	shuffleKey *deck.SRAKey
+	keys       []*deck.SRAKey
+	// cards is the deck as dealt, encrypted with the keys of all players.
+	cards [][]byte
+	// revealed holds the keys of the other players, by position.
+	revealed map[int]map[string]*big.Int
+	// known are the positions we turned up.
+	known map[int]bool
+	// positions are the positions of the cards we turned up, no card may
+	// turn up twice.
+	positions map[deck.Card]int
+	// sent are the positions we revealed our key for, by player. Keys for
+	// the board and the hands shown are sent to everybody.
+	sent map[string]map[int]bool
+}
+
+func newMentalDeck(handNumber int, players []string) (*mentalDeck, error) {
+	shuffleKey, err := deck.NewSRAKey()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*deck.SRAKey, 52)
+	for i := range keys {
+		if keys[i], err = deck.NewSRAKey(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &mentalDeck{
+		handNumber: handNumber,
+		players:    players,
+		shuffleKey: shuffleKey,
+		keys:       keys,
+		revealed:   make(map[int]map[string]*big.Int),
+		known:      make(map[int]bool),
+		positions:  make(map[deck.Card]int),
+		sent:       make(map[string]map[int]bool),
+	}, nil
+}
+
+// encodeDeck returns the cards of a new deck as the dealer puts them on
+// the table, in order.
+func encodeDeck() [][]byte {
+	cards := make([][]byte, 0, 52)
+	for suit := deck.Spades; suit <= deck.Clubs; suit++ {
+		for value := 1; value <= 13; value++ {
+			cards = append(cards, deck.EncodeCard(deck.NewCard(suit, value)))
+		}
+	}
+	return cards
+}
+
+// faceDownDeck is the deck the rules engine deals from, until the cards
+// are revealed.
+func faceDownDeck() []deck.Card {
+	cards := make([]deck.Card, 52)
+	for i := range cards {
+		cards[i] = deck.FaceDown(i)
+	}
+	return cards
+}
+
+// shuffle encrypts every card with our shuffle key and shuffles the deck.
+func (md *mentalDeck) shuffle(cards [][]byte) ([][]byte, error) {
+	if len(cards) != len(md.keys) {
+		return nil, fmt.Errorf("invalid deck of %d cards", len(cards))
+	}
+
+	shuffled := make([][]byte, len(cards))
+	for i, card := range cards {
+		shuffled[i] = md.shuffleKey.Encrypt(card)
+	}
+
+	for i := len(shuffled) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return nil, err
+		}
+		shuffled[i], shuffled[j.Int64()] = shuffled[j.Int64()], shuffled[i]
+	}
+
+	return shuffled, nil
+}
+
+// lock takes off our shuffle key and encrypts every card with the key of
+// its position instead.
+func (md *mentalDeck) lock(cards [][]byte) ([][]byte, error) {
+	if len(cards) != len(md.keys) {
+		return nil, fmt.Errorf("invalid deck of %d cards", len(cards))
+	}
+
+	locked := make([][]byte, len(cards))
+	for i, card := range cards {
+		locked[i] = deck.SRAExp(card, deck.SRAProduct(md.shuffleKey.D, md.keys[i].E))
+	}
+
+	return locked, nil
+}
+
+func (md *mentalDeck) setCards(cards [][]byte) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	if len(cards) != len(md.keys) {
+		return fmt.Errorf("invalid deck of %d cards", len(cards))
+	}
+	md.cards = cards
+
+	return nil
+}
+
+// keysFor returns our keys for the positions that were not sent to the
+// player yet. An empty address stands for everybody.
+func (md *mentalDeck) keysFor(addr string, positions []int) map[int][]byte {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	sent, ok := md.sent[addr]
+	if !ok {
+		sent = make(map[int]bool)
+		md.sent[addr] = sent
+	}
+
+	keys := make(map[int][]byte)
+	for _, pos := range positions {
+		if sent[pos] || md.sent[""][pos] || pos < 0 || pos >= len(md.keys) {
+			continue
+		}
+		sent[pos] = true
+		keys[pos] = md.keys[pos].D.Bytes()
+	}
+
+	return keys
+}
+
+// positionsOf returns the positions in the deck of the cards. Cards we
+// turned up don't know their position, we look it up.
+func (md *mentalDeck) positionsOf(cards []deck.Card) []int {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	positions := []int{}
+	for _, card := range cards {
+		if card.IsFaceDown() {
+			positions = append(positions, card.FaceDownPos())
+		} else if pos, ok := md.positions[card]; ok {
+			positions = append(positions, pos)
+		}
+	}
+
+	return positions
+}
+
+// sentTo returns our keys that were sent to the player or to everybody, to
+// send them again after the player lost the connection.
+func (md *mentalDeck) sentTo(addr string) map[int][]byte {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	keys := make(map[int][]byte)
+	for _, sent := range []map[int]bool{md.sent[addr], md.sent[""]} {
+		for pos := range sent {
+			keys[pos] = md.keys[pos].D.Bytes()
+		}
+	}
+
+	return keys
+}
+
+// addKeys stores the keys another player revealed.
+func (md *mentalDeck) addKeys(from string, keys map[int][]byte) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	if !containsAddr(md.players, from) {
+		return fmt.Errorf("player (%s) did not deal hand %d", from, md.handNumber)
+	}
+
+	for pos, key := range keys {
+		if pos < 0 || pos >= len(md.keys) {
+			return fmt.Errorf("invalid card position %d", pos)
+		}
+		if _, ok := md.revealed[pos]; !ok {
+			md.revealed[pos] = make(map[string]*big.Int)
+		}
+		md.revealed[pos][from] = new(big.Int).SetBytes(key)
+	}
+
+	return nil
+}
+
+// turnUp returns the cards we have all keys for and did not turn up yet.
+// A card that is in the deck twice is an error, the deck was not a
+// complete deck.
+func (md *mentalDeck) turnUp() (map[int]deck.Card, error) {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	cards := make(map[int]deck.Card)
+	if md.cards == nil {
+		return cards, nil
+	}
+
+	for pos := range md.revealed {
+		if md.known[pos] {
+			continue
+		}
+		card, ok, err := md.card(pos)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if _, ok := md.positions[card]; ok {
+			return nil, fmt.Errorf("%s is in the deck of hand %d more than once", card, md.handNumber)
+		}
+		md.known[pos] = true
+		md.positions[card] = pos
+		cards[pos] = card
+	}
+
+	return cards, nil
+}
+
+// card decrypts the card at the position once all other players revealed
+// their key for it.
+func (md *mentalDeck) card(pos int) (deck.Card, bool, error) {
+	keys := []*big.Int{md.keys[pos].D}
+	for _, addr := range md.players {
+		key, ok := md.revealed[pos][addr]
+		if ok {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) < len(md.players) {
+		return deck.Card{}, false, nil
+	}
+
+	card, err := deck.DecodeCard(deck.SRAExp(md.cards[pos], deck.SRAProduct(keys...)))
+	if err != nil {
+		return card, false, fmt.Errorf("card %d of hand %d: %s", pos, md.handNumber, err)
+	}
+
+	return card, true, nil
+}
+
+// RevealCards turns up cards that were dealt face down, by their position
+// in the deck of the hand. A showdown waiting for the cards goes on once
+// all of them are known, and it is over once the hands shown are known.
+func (pg *PokerGame) RevealCards(cards map[int]deck.Card) error {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	if !pg.gameStarted {
+		return fmt.Errorf("no hand in progress")
+	}
+	for pos, card := range cards {
+		if card.IsFaceDown() {
+			return fmt.Errorf("card %d is revealed face down", pos)
+		}
+	}
+
+	if err := pg.record(EventCardsRevealed{Cards: cards}); err != nil {
+		return err
+	}
+
+	if pending := pg.revealPending; pending != nil {
+		if pg.hasFaceDownCards(pending.AllIn) {
+			return nil
+		}
+		if err := pg.startShowdown(pending.AllIn); err != nil {
+			return err
+		}
+		return pg.startTimer()
+	}
+	if pg.currentRound == Showdown {
+		return pg.maybeFinishShowdown()
+	}
+
+	return nil
+}
+
+// revealCards replaces the face down cards at the given positions. The
+// slices are copied, snapshots may still hold the old ones.
+func (pg *PokerGame) revealCards(revealed map[int]deck.Card) {
+	reveal := func(cards []deck.Card) []deck.Card {
+		out := make([]deck.Card, len(cards))
+		for i, card := range cards {
+			out[i] = card
+			if c, ok := revealed[card.FaceDownPos()]; ok && card.IsFaceDown() {
+				out[i] = c
+			}
+		}
+		return out
+	}
+
+	pg.deck = reveal(pg.deck)
+	pg.communityCards = reveal(pg.communityCards)
+	for _, player := range pg.players {
+		player.HoleCards = reveal(player.HoleCards)
+		player.ShownCards = reveal(player.ShownCards)
+	}
+}
+
+// hasFaceDownCards reports whether the board has cards that are not
+// revealed. With allIn the hands of the players still in the hand are
+// turned up as well.
+func (pg *PokerGame) hasFaceDownCards(allIn bool) bool {
+	cards := append([]deck.Card{}, pg.communityCards...)
+	for _, addr := range pg.seatOrder() {
+		if player := pg.players[addr]; allIn && !player.Folded {
+			cards = append(cards, player.HoleCards...)
+		}
+	}
+
+	return containsFaceDown(cards)
+}
+
+// shownFaceDown reports whether a hand shown at showdown is not revealed
+// yet.
+func (pg *PokerGame) shownFaceDown() bool {
+	for _, player := range pg.players {
+		if !player.Mucked && containsFaceDown(player.ShownCards) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFaceDown(cards []deck.Card) bool {
+	for _, card := range cards {
+		if card.IsFaceDown() {
+			return true
+		}
+	}
+	return false
+}
+
+// cardsToReveal returns the positions in the deck of the cards dealt face
+// down to every player, and the cards everybody gets to see: the board and
+// the hands shown, or about to be shown when players are all-in. Mucked
+// hands are never turned up for the other players.
+func (pg *PokerGame) cardsToReveal() (map[string][]int, []deck.Card) {
+	pg.mu.RLock()
+	defer pg.mu.RUnlock()
+
+	holes := make(map[string][]int)
+	for addr, player := range pg.players {
+		for _, card := range player.HoleCards {
+			if card.IsFaceDown() {
+				holes[addr] = append(holes[addr], card.FaceDownPos())
+			}
+		}
+	}
+
+	public := append([]deck.Card{}, pg.communityCards...)
+	allIn := pg.gameStarted && pg.revealPending != nil && pg.revealPending.AllIn
+	for _, player := range pg.players {
+		switch {
+		case allIn && !player.Folded:
+			public = append(public, player.HoleCards...)
+		case !player.Mucked:
+			public = append(public, player.ShownCards...)
+		}
+	}
+
+	return holes, public
+}
@@ -0,0 +1,154 @@
+package p2p
+
+import (
+	"testing"
+
+	"github.com/koshiq/ggpoker/deck"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShowdownWaitsForFaceDownCards(t *testing.T) {
+	game := NewPokerGame(10, 20)
+	assert.Nil(t, game.AddPlayer(":1", 1000, 0))
+	assert.Nil(t, game.AddPlayer(":2", 1000, 1))
+	assert.Nil(t, game.StartNewHandWithDeck(faceDownDeck()))
+
+	// All-in before the flop runs out the board face down.
+	assert.Nil(t, game.PlayerAction(game.actionOn, PlayerActionRaise, 980))
+	assert.Nil(t, game.PlayerAction(game.actionOn, PlayerActionCall, 0))
+	assert.True(t, game.gameStarted)
+	assert.NotNil(t, game.revealPending)
+	assert.Len(t, game.communityCards, 5)
+
+	// The hands are turned up along with the board.
+	holes, public := game.cardsToReveal()
+	assert.Len(t, holes, 2)
+	assert.Len(t, public, 9)
+
+	cards := deck.NewSeeded(1)
+	revealed := map[int]deck.Card{}
+	for _, card := range game.communityCards {
+		revealed[card.FaceDownPos()] = cards[card.FaceDownPos()]
+	}
+	assert.Nil(t, game.RevealCards(revealed))
+	assert.True(t, game.gameStarted)
+
+	revealed = map[int]deck.Card{}
+	for _, positions := range holes {
+		for _, pos := range positions {
+			revealed[pos] = cards[pos]
+		}
+	}
+	assert.Nil(t, game.RevealCards(revealed))
+	assert.False(t, game.gameStarted)
+	assert.Equal(t, 2000, game.players[":1"].Stack+game.players[":2"].Stack)
+	assert.NotNil(t, game.RevealCards(revealed))
+}
+
+func TestMentalDeckDetectsWrongKeys(t *testing.T) {
+	a, err := newMentalDeck(1, []string{":1", ":2"})
+	assert.Nil(t, err)
+	b, err := newMentalDeck(1, []string{":1", ":2"})
+	assert.Nil(t, err)
+
+	cards, err := a.shuffle(encodeDeck())
+	assert.Nil(t, err)
+	cards, err = b.shuffle(cards)
+	assert.Nil(t, err)
+	cards, err = a.lock(cards)
+	assert.Nil(t, err)
+	cards, err = b.lock(cards)
+	assert.Nil(t, err)
+	assert.Nil(t, a.setCards(cards))
+
+	assert.Nil(t, a.addKeys(":2", b.keysFor(":1", []int{0})))
+	turned, err := a.turnUp()
+	assert.Nil(t, err)
+	assert.Len(t, turned, 1)
+
+	// A key for another position does not decrypt the card.
+	assert.Nil(t, a.addKeys(":2", map[int][]byte{1: b.keys[2].D.Bytes()}))
+	_, err = a.turnUp()
+	assert.NotNil(t, err)
+	assert.NotNil(t, a.addKeys(":3", map[int][]byte{}))
+}
+
+func TestMentalDeckDetectsDuplicateCards(t *testing.T) {
+	a, err := newMentalDeck(1, []string{":1", ":2"})
+	assert.Nil(t, err)
+	b, err := newMentalDeck(1, []string{":1", ":2"})
+	assert.Nil(t, err)
+
+	cards := encodeDeck()
+	cards[1] = cards[0]
+	cards, err = a.shuffle(cards)
+	assert.Nil(t, err)
+	cards, err = b.shuffle(cards)
+	assert.Nil(t, err)
+	cards, err = a.lock(cards)
+	assert.Nil(t, err)
+	cards, err = b.lock(cards)
+	assert.Nil(t, err)
+	assert.Nil(t, a.setCards(cards))
+
+	all := make([]int, len(cards))
+	for i := range all {
+		all[i] = i
+	}
+	assert.Nil(t, a.addKeys(":2", b.keysFor("", all)))
+	_, err = a.turnUp()
+	assert.NotNil(t, err)
+}
+
+func TestShowdownWaitsForShownHands(t *testing.T) {
+	game := NewPokerGame(10, 20)
+	assert.Nil(t, game.AddPlayer(":1", 1000, 0))
+	assert.Nil(t, game.AddPlayer(":2", 1000, 1))
+	assert.Nil(t, game.StartNewHandWithDeck(faceDownDeck()))
+
+	// The board is turned up, the hands of the players are not.
+	cards := deck.NewSeeded(1)
+	for {
+		_, public := game.cardsToReveal()
+		revealed := map[int]deck.Card{}
+		for _, card := range public {
+			if card.IsFaceDown() {
+				revealed[card.FaceDownPos()] = cards[card.FaceDownPos()]
+			}
+		}
+		if len(revealed) > 0 {
+			assert.Nil(t, game.RevealCards(revealed))
+		}
+		if game.currentRound == Showdown {
+			break
+		}
+		action := PlayerActionCheck
+		if game.players[game.actionOn].Bet < game.currentBet {
+			action = PlayerActionCall
+		}
+		assert.Nil(t, game.PlayerAction(game.actionOn, action, 0))
+	}
+
+	shown := game.actionOn
+	assert.Nil(t, game.ShowHand(shown))
+	_, public := game.cardsToReveal()
+	assert.Len(t, public, 7)
+
+	// The node of the other player says the hand is mucked, we can't tell.
+	mucked := game.actionOn
+	action, err := game.TimedOut(mucked, true)
+	assert.Nil(t, err)
+	assert.Equal(t, PlayerActionFold, action)
+	assert.True(t, game.gameStarted)
+
+	revealed := map[int]deck.Card{}
+	for _, card := range game.players[shown].HoleCards {
+		revealed[card.FaceDownPos()] = cards[card.FaceDownPos()]
+	}
+	assert.Nil(t, game.RevealCards(revealed))
+	assert.False(t, game.gameStarted)
+	assert.Equal(t, 1020, game.players[shown].Stack)
+	for _, card := range game.players[mucked].HoleCards {
+		assert.True(t, card.IsFaceDown())
+	}
+}
@@ -1,13 +1,27 @@
 package p2p
 
+import "time"
+
 type Message struct {
 	Payload any
-	From    string
+	// From is the player ID of the sender, see PlayerID.
+	From string
+	// Signature is the signature of the sender over From, Hand, Seq and
+	// Payload.
+	Signature []byte
+	// Hand is the number of the hand the sender was at when it sent the
+	// message.
+	Hand int
+	// Seq counts up the messages of the sender, so replayed messages are
+	// dropped, see seqWindow.
+	Seq uint64
 }
 
 type BroadcastTo struct {
 	To      []string
 	Payload any
+	// Hand is the hand the payload belongs to, see Message.Hand.
+	Hand int
 }
 
 func NewMessage(from string, payload any) *Message {
@@ -32,20 +46,94 @@ type MessagePlayerAction struct {
 	Action PlayerAction
 	// The value of the bet if any
 	Value int
+	// TimedOut is set when the player ran out of time and Action was taken
+	// for them, see PokerGame.TimeOut.
+	TimedOut bool
+}
+
+// MessageTimerStarted tells peers that the clock of a player started.
+type MessageTimerStarted struct {
+	Addr     string
+	Started  time.Time
+	Timeout  time.Duration
+	TimeBank time.Duration
 }
 
-type MessagePreFlop struct{}
+// MessageTimerStopped tells peers that a player acted, or ran out of time.
+type MessageTimerStopped struct {
+	Addr         string
+	TimeBankUsed time.Duration
+	TimedOut     bool
+}
+
+// MessagePreFlop tells peers that the dealer started a hand dealt from
+// Deck, the deck encrypted by all players.
+type MessagePreFlop struct {
+	Deck [][]byte
+}
 
 func (msg MessagePreFlop) String() string {
 	return "MSG: PREFLOP"
 }
 
+// MessageDealer tells peers who the sending player has dealing the hand.
+type MessageDealer struct {
+	HandNumber int
+	Dealer     string
+}
+
+// MessageShowHand tells peers that the sending player shows or mucks the
+// hole cards at showdown.
+type MessageShowHand struct {
+	Muck bool
+}
+
+// MessageDealProposed tells peers that the sending player proposes a deal
+// of Kind to the players left in the tournament.
+type MessageDealProposed struct {
+	Kind DealKind
+}
+
+// MessageDealAnswer tells peers that the sending player accepts or rejects
+// the open deal.
+type MessageDealAnswer struct {
+	Accept bool
+}
+
 type MessagePeerList struct {
 	Peers []string
 }
 
+// MessageEncDeck passes the deck on to the next player. It goes around the
+// table to be shuffled first, then once more to be Locked with a key per
+// card.
 type MessageEncDeck struct {
-	Deck [][]byte
+	Deck   [][]byte
+	Locked bool
+}
+
+// MessageCardKeys reveals keys of the sending player for the cards at the
+// given positions of the deck.
+type MessageCardKeys struct {
+	HandNumber int
+	Keys       map[int][]byte
+}
+
+// MessagePlayerDisconnected tells peers that the sending player lost the
+// connection to the player at Addr.
+type MessagePlayerDisconnected struct {
+	Addr string
+}
+
+// MessageStateSync catches up a player that reconnected. It holds the seats
+// by address, the status and the actions of the current hand as the sending
+// player has them, and the keys the sending player revealed to the player.
+type MessageStateSync struct {
+	Seats      map[string]int
+	Status     GameStatus
+	HandNumber int
+	Actions    []HandAction
+	Keys       map[int][]byte
 }
 
 type MessageReady struct{}
@@ -53,3 +141,22 @@ type MessageReady struct{}
 func (msg MessageReady) String() string {
 	return "MSG: READY"
 }
+
+// MessageSitOut tells peers that the sending player steps away from the
+// table, after the current hand or at the next big blind.
+type MessageSitOut struct {
+	NextBigBlind bool
+}
+
+// MessageSitIn tells peers that the sending player returns to play, right
+// away by posting a big blind or once the big blind reaches the player.
+type MessageSitIn struct {
+	WaitForBigBlind bool
+}
+
+// MessageChipsAdded tells peers that the sending player rebuys or tops up
+// between hands.
+type MessageChipsAdded struct {
+	Amount int
+	Reason LedgerReason
+}
@@ -0,0 +1,563 @@
+package p2p
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type MTTConfig struct {
+	// TableSize is the number of seats of every table.
+	TableSize     int
+	StartingStack int
+	Levels        []BlindLevel
+	BuyIn         int
+	Payouts       []int
+	Bounty        BountyConfig
+}
+
+// MTT runs a tournament over several tables. Tables are kept within one
+// player of each other and broken up as the field shrinks. On the bubble
+// all tables play hand-for-hand.
+//
+// The player that is moved off a table is the one due for the big blind,
+// and moved players wait for the big blind at their new table. That way
+// nobody skips the big blind or pays it twice.
+type MTT struct {
+	mu      sync.Mutex
+	cfg     MTTConfig
+	status  TournamentStatus
+	entries []string
+	tables  map[int]*PokerGame
+	// tableOf holds the table of every player that is still in.
+	tableOf map[string]int
+	// betweenHands holds the tables that are done with their hand, they
+	// wait for the next hand, for players or for the other tables when
+	// playing hand-for-hand.
+	betweenHands map[int]bool
+	handForHand  bool
+	started      time.Time
+	level        int
+	// finishes holds the players knocked out, in the order they went out.
+	finishes   []TournamentFinish
+	handStacks map[string]int
+
+	// auto is set while Run deals the hands.
+	auto        bool
+	delay       time.Duration
+	unsubscribe map[int]func()
+
+	now func() time.Time
+}
+
+func NewMTT(cfg MTTConfig) (*MTT, error) {
+	if cfg.TableSize < 2 || cfg.TableSize > defaultMaxPlayers {
+		return nil, fmt.Errorf("tables need between 2 and %d seats", defaultMaxPlayers)
+	}
+	if cfg.StartingStack <= 0 {
+		return nil, fmt.Errorf("invalid starting stack %d", cfg.StartingStack)
+	}
+	if err := validateStructure(cfg.Levels, cfg.Payouts); err != nil {
+		return nil, err
+	}
+
+	return &MTT{
+		cfg:          cfg,
+		status:       TournamentRegistering,
+		entries:      []string{},
+		tables:       make(map[int]*PokerGame),
+		tableOf:      make(map[string]int),
+		betweenHands: make(map[int]bool),
+		finishes:     []TournamentFinish{},
+		handStacks:   make(map[string]int),
+		unsubscribe:  make(map[int]func()),
+		now:          time.Now,
+	}, nil
+}
+
+func (m *MTT) Status() TournamentStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.status
+}
+
+// Register enters the player, players are seated once the tournament
+// starts.
+func (m *MTT) Register(addr string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.status != TournamentRegistering {
+		return fmt.Errorf("registration is closed")
+	}
+	for _, entry := range m.entries {
+		if entry == addr {
+			return fmt.Errorf("player %s is already registered", addr)
+		}
+	}
+	m.entries = append(m.entries, addr)
+
+	return nil
+}
+
+// Start seats the players over as few tables as possible, in the order
+// they registered.
+func (m *MTT) Start() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.status != TournamentRegistering {
+		return fmt.Errorf("tournament is %s", m.status)
+	}
+	if len(m.entries) < 2 {
+		return fmt.Errorf("need at least 2 players to start a tournament")
+	}
+
+	n := (len(m.entries) + m.cfg.TableSize - 1) / m.cfg.TableSize
+	first := m.cfg.Levels[0]
+	for id := 1; id <= n; id++ {
+		game := NewPokerGame(first.SmallBlind, first.BigBlind)
+		if err := game.SetBlinds(first.SmallBlind, first.BigBlind, first.Ante); err != nil {
+			return err
+		}
+		game.SetBounty(m.cfg.Bounty)
+		m.tables[id] = game
+		m.betweenHands[id] = true
+	}
+	for i, addr := range m.entries {
+		id := i%n + 1
+		if err := m.tables[id].AddPlayer(addr, m.cfg.StartingStack, i/n); err != nil {
+			return err
+		}
+		m.tableOf[addr] = id
+	}
+
+	m.status = TournamentRunning
+	m.started = m.now()
+	m.level = 0
+
+	logrus.WithFields(logrus.Fields{
+		"players": len(m.entries),
+		"tables":  n,
+	}).Info("tournament started")
+
+	if m.auto {
+		m.startDealing()
+	}
+
+	return nil
+}
+
+// Tables returns the ids of the tables in play.
+func (m *MTT) Tables() []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.tableIDs()
+}
+
+func (m *MTT) tableIDs() []int {
+	ids := make([]int, 0, len(m.tables))
+	for id := range m.tables {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	return ids
+}
+
+// Table returns the game of the given table, or nil if there is no such
+// table (anymore).
+func (m *MTT) Table(id int) *PokerGame {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.tables[id]
+}
+
+// TableOf returns the table the player is seated at.
+func (m *MTT) TableOf(addr string) (int, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id, ok := m.tableOf[addr]
+	return id, ok
+}
+
+func (m *MTT) HandForHand() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.handForHand
+}
+
+// NextHand is called once the hand at the given table is over. It knocks
+// out the players without chips, balances or breaks the table and deals
+// the next hand. While playing hand-for-hand no table is dealt until all
+// tables are done with their hand.
+func (m *MTT) NextHand(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.status != TournamentRunning {
+		return fmt.Errorf("tournament is %s", m.status)
+	}
+	game, ok := m.tables[id]
+	if !ok {
+		return fmt.Errorf("table %d not found", id)
+	}
+	game.mu.RLock()
+	inHand := game.gameStarted
+	game.mu.RUnlock()
+	if inHand {
+		return fmt.Errorf("hand at table %d is still in progress", id)
+	}
+
+	ids := []int{id}
+	if m.handForHand {
+		ids = m.tableIDs()
+	}
+	m.betweenHands[id] = true
+	if m.handForHand && !m.allBetweenHands() {
+		return nil
+	}
+
+	if err := m.knockOut(ids); err != nil {
+		return err
+	}
+	if m.status == TournamentFinished {
+		return nil
+	}
+
+	if bubble := len(m.tables) > 1 && len(m.tableOf) == len(m.cfg.Payouts)+1; bubble != m.handForHand {
+		m.handForHand = bubble
+		if bubble {
+			logrus.WithField("players", len(m.tableOf)).Info("tournament on the bubble, playing hand-for-hand")
+		} else {
+			logrus.WithField("players", len(m.tableOf)).Info("tournament in the money, hand-for-hand play is over")
+		}
+	}
+	if m.handForHand {
+		// The first hand-for-hand hand is dealt once every table is done.
+		if !m.allBetweenHands() {
+			return nil
+		}
+		ids = m.tableIDs()
+	}
+
+	for _, id := range ids {
+		if _, ok := m.tables[id]; ok {
+			if err := m.balance(id); err != nil {
+				return err
+			}
+		}
+	}
+
+	return m.dealTables()
+}
+
+func (m *MTT) allBetweenHands() bool {
+	for id := range m.tables {
+		if !m.betweenHands[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// knockOut takes the players without chips off the given tables and gives
+// them their finishing place.
+func (m *MTT) knockOut(ids []int) error {
+	busted := []string{}
+	for _, id := range ids {
+		players, err := bustedPlayers(m.tables[id])
+		if err != nil {
+			return fmt.Errorf("table %d: %w", id, err)
+		}
+		busted = append(busted, players...)
+	}
+	sortKnockouts(busted, m.handStacks)
+
+	for _, addr := range busted {
+		game := m.tables[m.tableOf[addr]]
+		bounties := bountiesOf(game, addr)
+		if err := game.RemovePlayer(addr); err != nil {
+			return err
+		}
+		m.finishes = append(m.finishes, TournamentFinish{Addr: addr, Place: len(m.tableOf), Bounties: bounties})
+		delete(m.tableOf, addr)
+
+		logrus.WithFields(logrus.Fields{
+			"player": addr,
+			"place":  len(m.tableOf) + 1,
+		}).Info("player knocked out of tournament")
+	}
+
+	if len(m.tableOf) == 1 {
+		for addr, id := range m.tableOf {
+			// The winner also collects the own bounty.
+			bounties := bountiesOf(m.tables[id], addr) + bountyOf(m.tables[id], addr)
+			m.finishes = append(m.finishes, TournamentFinish{Addr: addr, Place: 1, Bounties: bounties})
+			logrus.WithField("winner", addr).Info("tournament finished")
+		}
+		m.status = TournamentFinished
+		m.stopDealing()
+	}
+
+	return nil
+}
+
+// balance breaks the table once the players left fit on the other tables,
+// otherwise it moves players to the smallest table until the table has at
+// most one player more.
+func (m *MTT) balance(id int) error {
+	if len(m.tables) > 1 && len(m.tableOf) <= (len(m.tables)-1)*m.cfg.TableSize {
+		return m.breakTable(id)
+	}
+
+	for {
+		to := m.smallestTable(id)
+		if to < 0 || m.count(id) <= m.count(to)+1 {
+			return nil
+		}
+		if err := m.move(m.nextToMove(id), id, to); err != nil {
+			return err
+		}
+	}
+}
+
+func (m *MTT) breakTable(id int) error {
+	game := m.tables[id]
+	game.mu.RLock()
+	addrs := game.allSeats()
+	game.mu.RUnlock()
+
+	for _, addr := range addrs {
+		if err := m.move(addr, id, m.smallestTable(id)); err != nil {
+			return err
+		}
+	}
+
+	delete(m.tables, id)
+	delete(m.betweenHands, id)
+	if unsubscribe, ok := m.unsubscribe[id]; ok {
+		unsubscribe()
+		delete(m.unsubscribe, id)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"table":  id,
+		"tables": len(m.tables),
+	}).Info("tournament table broken")
+
+	return nil
+}
+
+// smallestTable returns the table with the fewest players other than the
+// given one, or -1 if there is none.
+func (m *MTT) smallestTable(except int) int {
+	smallest := -1
+	for _, id := range m.tableIDs() {
+		if id != except && (smallest < 0 || m.count(id) < m.count(smallest)) {
+			smallest = id
+		}
+	}
+	return smallest
+}
+
+func (m *MTT) count(id int) int {
+	n := 0
+	for _, table := range m.tableOf {
+		if table == id {
+			n++
+		}
+	}
+	return n
+}
+
+// nextToMove returns the player of the table that is due for the big
+// blind.
+func (m *MTT) nextToMove(id int) string {
+	game := m.tables[id]
+	game.mu.RLock()
+	defer game.mu.RUnlock()
+
+	playerAddrs := game.seatOrder()
+	if len(playerAddrs) < 2 {
+		return game.allSeats()[0]
+	}
+	return game.dueBigBlind(playerAddrs).Addr
+}
+
+// move takes the player with all chips from one table to a free seat at
+// the other, where the player waits for the big blind.
+func (m *MTT) move(addr string, from, to int) error {
+	src, dst := m.tables[from], m.tables[to]
+
+	src.mu.RLock()
+	player := src.players[addr]
+	joined := EventPlayerJoined{
+		Addr:            addr,
+		Stack:           player.Stack,
+		Bounty:          player.Bounty,
+		BountiesWon:     player.BountiesWon,
+		WaitForBigBlind: true,
+	}
+	src.mu.RUnlock()
+
+	position, ok := freeSeat(dst, m.cfg.TableSize)
+	if !ok {
+		return fmt.Errorf("table %d is full", to)
+	}
+	if err := src.RemovePlayer(addr); err != nil {
+		return err
+	}
+
+	joined.Position = position
+	dst.mu.Lock()
+	err := dst.seatPlayer(joined)
+	dst.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	m.tableOf[addr] = to
+
+	logrus.WithFields(logrus.Fields{
+		"player": addr,
+		"from":   from,
+		"to":     to,
+	}).Info("player moved to another table")
+
+	return nil
+}
+
+// dealTables deals the next hand at every table that is between hands and
+// has enough players.
+func (m *MTT) dealTables() error {
+	if level := levelAt(m.cfg.Levels, m.started, m.now()); level != m.level {
+		m.level = level
+		logrus.WithFields(logrus.Fields{
+			"level":  level + 1,
+			"blinds": fmt.Sprintf("%d/%d", m.cfg.Levels[level].SmallBlind, m.cfg.Levels[level].BigBlind),
+			"ante":   m.cfg.Levels[level].Ante,
+		}).Info("tournament blinds up")
+	}
+	blinds := m.cfg.Levels[m.level]
+
+	for _, id := range m.tableIDs() {
+		if !m.betweenHands[id] || m.count(id) < 2 {
+			continue
+		}
+
+		game := m.tables[id]
+		if err := game.SetBlinds(blinds.SmallBlind, blinds.BigBlind, blinds.Ante); err != nil {
+			return err
+		}
+		game.mu.RLock()
+		for addr, player := range game.players {
+			m.handStacks[addr] = player.Stack
+		}
+		game.mu.RUnlock()
+
+		if err := game.StartNewHand(); err != nil {
+			return fmt.Errorf("table %d: %w", id, err)
+		}
+		m.betweenHands[id] = false
+	}
+
+	return nil
+}
+
+// Level returns the blind level being played, counting from 0.
+func (m *MTT) Level() (int, BlindLevel) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.level, m.cfg.Levels[m.level]
+}
+
+// PrizePool returns the sum of all buy-ins.
+func (m *MTT) PrizePool() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.cfg.BuyIn * len(m.entries)
+}
+
+// Standings returns the players that finished, best place first, with the
+// prize of their place.
+func (m *MTT) Standings() []TournamentFinish {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	standings := make([]TournamentFinish, len(m.finishes))
+	for i, finish := range m.finishes {
+		finish.Prize = payout(m.cfg.BuyIn*len(m.entries), m.cfg.Payouts, finish.Place)
+		standings[len(m.finishes)-1-i] = finish
+	}
+
+	return standings
+}
+
+// Run deals the hands at all tables, waiting delay after each hand. The
+// returned function stops dealing.
+func (m *MTT) Run(delay time.Duration) func() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.auto = true
+	m.delay = delay
+	if m.status == TournamentRunning {
+		m.startDealing()
+	}
+
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		m.stopDealing()
+	}
+}
+
+func (m *MTT) startDealing() {
+	delay := m.delay
+	for _, id := range m.tableIDs() {
+		id := id
+		m.unsubscribe[id] = m.tables[id].Subscribe(func(r EventRecord) {
+			if _, ok := r.Event.(EventHandEnded); ok {
+				go m.dealAfter(id, delay)
+			}
+		})
+		if m.betweenHands[id] {
+			go m.dealAfter(id, 0)
+		}
+	}
+}
+
+func (m *MTT) stopDealing() {
+	m.auto = false
+	for id, unsubscribe := range m.unsubscribe {
+		unsubscribe()
+		delete(m.unsubscribe, id)
+	}
+}
+
+func (m *MTT) dealAfter(id int, delay time.Duration) {
+	time.Sleep(delay)
+
+	m.mu.Lock()
+	_, ok := m.tables[id]
+	auto := m.auto && ok
+	m.mu.Unlock()
+	if !auto {
+		return
+	}
+
+	if err := m.NextHand(id); err != nil {
+		logrus.Errorf("tournament failed to deal the next hand at table %d: %s", id, err)
+	}
+}
@@ -0,0 +1,123 @@
+package p2p
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMTT(t *testing.T, players, tableSize int) *MTT {
+	mtt, err := NewMTT(MTTConfig{
+		TableSize:     tableSize,
+		StartingStack: 100,
+		Levels:        []BlindLevel{{SmallBlind: 5, BigBlind: 10}},
+		BuyIn:         10,
+		Payouts:       []int{50, 30, 20},
+	})
+	assert.Nil(t, err)
+	for i := 0; i < players; i++ {
+		assert.Nil(t, mtt.Register(fmt.Sprintf(":%d", i+1)))
+	}
+	assert.Nil(t, mtt.Start())
+
+	return mtt
+}
+
+func tableCounts(mtt *MTT) []int {
+	counts := []int{}
+	for _, id := range mtt.Tables() {
+		counts = append(counts, len(mtt.Table(id).players))
+	}
+	return counts
+}
+
+// bust takes all chips of the players, as if they lost them in the last
+// hand of their table.
+func bust(mtt *MTT, addrs ...string) {
+	for _, addr := range addrs {
+		id, _ := mtt.TableOf(addr)
+		mtt.Table(id).players[addr].Stack = 0
+	}
+}
+
+func TestMTTSeating(t *testing.T) {
+	mtt := newTestMTT(t, 10, 4)
+
+	assert.Equal(t, []int{1, 2, 3}, mtt.Tables())
+	assert.Equal(t, []int{4, 3, 3}, tableCounts(mtt))
+	id, ok := mtt.TableOf(":5")
+	assert.True(t, ok)
+	assert.Equal(t, 2, id)
+}
+
+func TestMTTBalanceBreakAndBubble(t *testing.T) {
+	// Table 1: :1 :4 :7, table 2: :2 :5 :8, table 3: :3 :6 :9
+	mtt := newTestMTT(t, 9, 3)
+
+	// Table 1 is short and waits for players, the first hand is dealt at
+	// the other tables.
+	bust(mtt, ":1", ":4")
+	assert.Nil(t, mtt.NextHand(1))
+	assert.Equal(t, []int{1, 3, 3}, tableCounts(mtt))
+	assert.False(t, mtt.Table(1).gameStarted)
+	assert.True(t, mtt.Table(3).gameStarted)
+	foldToBigBlind(t, mtt.Table(2))
+	foldToBigBlind(t, mtt.Table(3))
+
+	// The player due for the big blind at table 2 moves to table 1, both
+	// tables are dealt.
+	table2 := mtt.Table(2)
+	mover := table2.dueBigBlind(table2.seatOrder()).Addr
+	assert.Nil(t, mtt.NextHand(2))
+	id, _ := mtt.TableOf(mover)
+	assert.Equal(t, 1, id)
+	assert.Equal(t, []int{2, 2, 3}, tableCounts(mtt))
+	assert.True(t, mtt.Table(1).gameStarted)
+	assert.True(t, mtt.Table(2).gameStarted)
+	assert.False(t, mtt.Table(3).gameStarted)
+	assert.NotNil(t, mtt.NextHand(1))
+	foldToBigBlind(t, mtt.Table(1))
+	foldToBigBlind(t, mtt.Table(2))
+
+	// With 6 players left table 3 is broken up.
+	bust(mtt, ":3")
+	assert.Nil(t, mtt.NextHand(3))
+	assert.Equal(t, []int{1, 2}, mtt.Tables())
+	assert.Equal(t, []int{3, 3}, tableCounts(mtt))
+	assert.Nil(t, mtt.Table(3))
+
+	// 4 players left with 3 paid is the bubble, tables wait for each
+	// other.
+	addrs := []string{}
+	for addr := range mtt.Table(1).players {
+		addrs = append(addrs, addr)
+	}
+	bust(mtt, addrs[:2]...)
+	assert.Nil(t, mtt.NextHand(1))
+	assert.True(t, mtt.HandForHand())
+	assert.False(t, mtt.Table(1).gameStarted)
+	assert.Nil(t, mtt.NextHand(2))
+	assert.Equal(t, []int{2, 2}, tableCounts(mtt))
+	assert.True(t, mtt.Table(1).gameStarted)
+	assert.True(t, mtt.Table(2).gameStarted)
+	foldToBigBlind(t, mtt.Table(1))
+	foldToBigBlind(t, mtt.Table(2))
+
+	// A knock out at table 2 counts once table 1 is done as well, then
+	// the final table is formed.
+	for addr := range mtt.Table(2).players {
+		bust(mtt, addr)
+		break
+	}
+	assert.Nil(t, mtt.NextHand(2))
+	assert.False(t, mtt.Table(2).gameStarted)
+	assert.Len(t, mtt.Standings(), 5)
+	assert.Nil(t, mtt.NextHand(1))
+	assert.False(t, mtt.HandForHand())
+	assert.Len(t, mtt.Tables(), 1)
+	assert.Equal(t, []int{3}, tableCounts(mtt))
+	assert.Len(t, mtt.Standings(), 6)
+	assert.Equal(t, 4, mtt.Standings()[0].Place)
+	assert.Equal(t, 0, mtt.Standings()[0].Prize)
+}
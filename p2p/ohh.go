@@ -0,0 +1,621 @@
+package p2p
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/koshiq/ggpoker/deck"
+)
+
+const ohhSpecVersion = "1.4.6"
+
+// OpenHandHistory is a single hand in the Open Hand History JSON standard
+// (https://hh-specs.handhistory.org).
+type OpenHandHistory struct {
+	OHH OHHHand `json:"ohh"`
+}
+
+type OHHHand struct {
+	SpecVersion      string      `json:"spec_version"`
+	SiteName         string      `json:"site_name"`
+	NetworkName      string      `json:"network_name"`
+	InternalVersion  string      `json:"internal_version"`
+	Tournament       bool        `json:"tournament"`
+	GameNumber       string      `json:"game_number"`
+	StartDateUTC     string      `json:"start_date_utc"`
+	TableName        string      `json:"table_name"`
+	GameType         string      `json:"game_type"`
+	BetLimit         OHHBetLimit `json:"bet_limit"`
+	TableSize        int         `json:"table_size"`
+	Currency         string      `json:"currency"`
+	DealerSeat       int         `json:"dealer_seat"`
+	SmallBlindAmount float64     `json:"small_blind_amount"`
+	BigBlindAmount   float64     `json:"big_blind_amount"`
+	AnteAmount       float64     `json:"ante_amount"`
+	HeroPlayerID     int         `json:"hero_player_id,omitempty"`
+	Players          []OHHPlayer `json:"players"`
+	Rounds           []OHHRound  `json:"rounds"`
+	Pots             []OHHPot    `json:"pots"`
+}
+
+type OHHBetLimit struct {
+	BetCap  float64 `json:"bet_cap"`
+	BetType string  `json:"bet_type"`
+}
+
+type OHHPlayer struct {
+	ID            int     `json:"id"`
+	Seat          int     `json:"seat"`
+	Name          string  `json:"name"`
+	Display       string  `json:"display,omitempty"`
+	StartingStack float64 `json:"starting_stack"`
+	PlayerBounty  float64 `json:"player_bounty,omitempty"`
+}
+
+type OHHRound struct {
+	ID      int         `json:"id"`
+	Street  string      `json:"street"`
+	Cards   []string    `json:"cards,omitempty"`
+	Actions []OHHAction `json:"actions"`
+}
+
+type OHHAction struct {
+	ActionNumber int      `json:"action_number"`
+	PlayerID     int      `json:"player_id"`
+	Action       string   `json:"action"`
+	Amount       float64  `json:"amount,omitempty"`
+	IsAllIn      bool     `json:"is_allin,omitempty"`
+	Cards        []string `json:"cards,omitempty"`
+}
+
+type OHHPot struct {
+	Number     int            `json:"number"`
+	Amount     float64        `json:"amount"`
+	Rake       float64        `json:"rake"`
+	Jackpot    float64        `json:"jackpot"`
+	PlayerWins []OHHPlayerWin `json:"player_wins"`
+}
+
+type OHHPlayerWin struct {
+	PlayerID        int     `json:"player_id"`
+	WinAmount       float64 `json:"win_amount"`
+	ContributedRake float64 `json:"contributed_rake"`
+}
+
+const (
+	ohhPostAnte   = "Post Ante"
+	ohhPostSB     = "Post SB"
+	ohhPostBB     = "Post BB"
+	ohhDealtCards = "Dealt Cards"
+	ohhFold       = "Fold"
+	ohhCheck      = "Check"
+	ohhCall       = "Call"
+	ohhBet        = "Bet"
+	ohhRaise      = "Raise"
+	ohhShowCards  = "Shows Cards"
+	ohhMuckCards  = "Mucks Cards"
+)
+
+var ohhStreets = map[BettingRound]string{
+	PreFlop:  "Preflop",
+	Flop:     "Flop",
+	Turn:     "Turn",
+	River:    "River",
+	Showdown: "Showdown",
+}
+
+// OpenHandHistory exports the given hand in the Open Hand History format.
+// Only the hole cards of hero and the cards shown at showdown are included.
+func (pg *PokerGame) OpenHandHistory(table string, handNumber int, hero string) (*OpenHandHistory, error) {
+	pg.mu.RLock()
+	defer pg.mu.RUnlock()
+
+	var (
+		tmp     = NewPokerGame(pg.smallBlind, pg.bigBlind)
+		started bool
+		ids     = make(map[string]int)
+		h       *OHHHand
+		round   *OHHRound
+		wins    = make(map[int]map[string]int)
+	)
+
+	addAction := func(addr, action string, amount int, allIn bool, cards []deck.Card) {
+		round.Actions = append(round.Actions, OHHAction{
+			ActionNumber: countOHHActions(h) + 1,
+			PlayerID:     ids[addr],
+			Action:       action,
+			Amount:       float64(amount),
+			IsAllIn:      allIn,
+			Cards:        shortCards(cards),
+		})
+	}
+	newRound := func(street BettingRound, cards []deck.Card) {
+		h.Rounds = append(h.Rounds, OHHRound{
+			ID:      len(h.Rounds),
+			Street:  ohhStreets[street],
+			Cards:   shortCards(cards),
+			Actions: []OHHAction{},
+		})
+		round = &h.Rounds[len(h.Rounds)-1]
+	}
+
+	for _, r := range pg.events {
+		if ev, ok := r.Event.(EventHandStarted); ok && ev.HandNumber == handNumber {
+			started = true
+		}
+		if !started {
+			if err := tmp.apply(r.Event); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		var prevBet int
+		if e, ok := r.Event.(EventActionTaken); ok {
+			prevBet = tmp.players[e.Addr].Bet
+		}
+
+		if err := tmp.apply(r.Event); err != nil {
+			return nil, err
+		}
+
+		switch e := r.Event.(type) {
+		case EventHandStarted:
+			seats := tmp.seatOrder()
+			h = &OHHHand{
+				SpecVersion:      ohhSpecVersion,
+				SiteName:         "ggpoker",
+				NetworkName:      "ggpoker",
+				InternalVersion:  "1",
+				GameNumber:       fmt.Sprintf("%d", e.HandNumber),
+				StartDateUTC:     r.Time.UTC().Format("2006-01-02T15:04:05Z"),
+				TableName:        table,
+				GameType:         "Holdem",
+				BetLimit:         OHHBetLimit{BetType: "NL"},
+				TableSize:        defaultMaxPlayers,
+				Currency:         "CHIPS",
+				DealerSeat:       tmp.players[seats[e.DealerPos]].Position + 1,
+				SmallBlindAmount: float64(tmp.smallBlind),
+				BigBlindAmount:   float64(tmp.bigBlind),
+				AnteAmount:       float64(tmp.ante),
+				Players:          []OHHPlayer{},
+				Rounds:           []OHHRound{},
+				Pots:             []OHHPot{},
+			}
+			for i, addr := range seats {
+				player := tmp.players[addr]
+				ids[addr] = i + 1
+				h.Players = append(h.Players, OHHPlayer{
+					ID:            i + 1,
+					Seat:          player.Position + 1,
+					Name:          addr,
+					StartingStack: float64(player.Stack),
+					PlayerBounty:  float64(player.Bounty),
+				})
+			}
+			if id, ok := ids[hero]; ok {
+				h.HeroPlayerID = id
+			}
+			newRound(PreFlop, nil)
+
+		case EventAntePosted:
+			addAction(e.Addr, ohhPostAnte, e.Amount, tmp.players[e.Addr].AllIn, nil)
+
+		case EventBlindPosted:
+			action := ohhPostBB
+			if tmp.players[e.Addr].IsSmallBlind && !tmp.players[e.Addr].IsBigBlind {
+				action = ohhPostSB
+			}
+			addAction(e.Addr, action, e.Amount, tmp.players[e.Addr].AllIn, nil)
+
+		case EventCardsDealt:
+			if e.Addr == hero {
+				addAction(e.Addr, ohhDealtCards, 0, false, e.Cards)
+			}
+
+		case EventActionTaken:
+			player := tmp.players[e.Addr]
+			switch e.Action {
+			case PlayerActionFold:
+				addAction(e.Addr, ohhFold, 0, false, nil)
+			case PlayerActionCheck:
+				addAction(e.Addr, ohhCheck, 0, false, nil)
+			case PlayerActionCall:
+				addAction(e.Addr, ohhCall, player.Bet-prevBet, player.AllIn, nil)
+			case PlayerActionBet:
+				addAction(e.Addr, ohhBet, player.Bet-prevBet, player.AllIn, nil)
+			case PlayerActionRaise:
+				addAction(e.Addr, ohhRaise, player.Bet-prevBet, player.AllIn, nil)
+			}
+
+		case EventStreetDealt:
+			newRound(e.Round, e.Cards)
+
+		case EventShowdownStarted:
+			newRound(Showdown, nil)
+
+		case EventCardsShown:
+			addAction(e.Addr, ohhShowCards, 0, false, e.Cards)
+
+		case EventHandMucked:
+			addAction(e.Addr, ohhMuckCards, 0, false, nil)
+
+		case EventPotAwarded:
+			if wins[e.Pot] == nil {
+				wins[e.Pot] = make(map[string]int)
+			}
+			wins[e.Pot][e.Addr] += e.Amount
+
+		case EventHandEnded:
+			for i, pot := range tmp.pot {
+				rake := tmp.rakeOf(i)
+				ohhPot := OHHPot{
+					Number:     i,
+					Amount:     float64(pot.Amount + rake),
+					Rake:       float64(rake),
+					PlayerWins: []OHHPlayerWin{},
+				}
+				// The rake is taken from the shares of the winners in
+				// proportion, the first winner pays what can't be split.
+				left := rake
+				for _, addr := range tmp.seatOrder() {
+					if amount, ok := wins[i][addr]; ok {
+						contributed := 0
+						if pot.Amount > 0 {
+							contributed = rake * amount / pot.Amount
+						}
+						left -= contributed
+						ohhPot.PlayerWins = append(ohhPot.PlayerWins, OHHPlayerWin{
+							PlayerID:        ids[addr],
+							WinAmount:       float64(amount),
+							ContributedRake: float64(contributed),
+						})
+					}
+				}
+				if len(ohhPot.PlayerWins) > 0 {
+					ohhPot.PlayerWins[0].ContributedRake += float64(left)
+				}
+				h.Pots = append(h.Pots, ohhPot)
+			}
+			return &OpenHandHistory{OHH: *h}, nil
+		}
+	}
+
+	if !started {
+		return nil, fmt.Errorf("hand %d not found", handNumber)
+	}
+
+	return nil, fmt.Errorf("hand %d is still in progress", handNumber)
+}
+
+func countOHHActions(h *OHHHand) int {
+	n := 0
+	for _, r := range h.Rounds {
+		n += len(r.Actions)
+	}
+	return n
+}
+
+func shortCards(cards []deck.Card) []string {
+	if len(cards) == 0 {
+		return nil
+	}
+	s := make([]string, len(cards))
+	for i, card := range cards {
+		s[i] = card.Short()
+	}
+	return s
+}
+
+// ParseOpenHandHistories reads one or more hands in the Open Hand History
+// format. Hands can be a single JSON object, concatenated objects or JSON
+// lines, which is how most sites export them.
+func ParseOpenHandHistories(r io.Reader) ([]*OpenHandHistory, error) {
+	dec := json.NewDecoder(r)
+	hands := []*OpenHandHistory{}
+
+	for {
+		h := &OpenHandHistory{}
+		if err := dec.Decode(h); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if h.OHH.SpecVersion == "" && len(h.OHH.Players) == 0 {
+			return nil, fmt.Errorf("not an open hand history")
+		}
+		hands = append(hands, h)
+	}
+
+	return hands, nil
+}
+
+// IsOpenHandHistory reports whether b looks like an Open Hand History.
+func IsOpenHandHistory(b []byte) bool {
+	var probe map[string]json.RawMessage
+	if err := json.NewDecoder(bytes.NewReader(b)).Decode(&probe); err != nil {
+		return false
+	}
+	_, ok := probe["ohh"]
+	return ok
+}
+
+// HandRecord converts the hand into a record that can be replayed through
+// PokerGame. The deck is stacked with all the known cards, unknown hole
+// cards are filled up with the lowest remaining cards. The showdown is
+// played in the order of our own rules, using the shown and mucked hands
+// of the history.
+func (h *OpenHandHistory) HandRecord() (*HandRecord, error) {
+	hand := &h.OHH
+
+	if hand.GameType != "Holdem" {
+		return nil, fmt.Errorf("unsupported game type %q", hand.GameType)
+	}
+
+	scale := hand.chipScale()
+	chips := func(v float64) int { return int(math.Round(v * scale)) }
+
+	players := append([]OHHPlayer{}, hand.Players...)
+	sort.Slice(players, func(i, j int) bool { return players[i].Seat < players[j].Seat })
+
+	names := make(map[int]string)
+	rec := &HandRecord{
+		SmallBlind: chips(hand.SmallBlindAmount),
+		BigBlind:   chips(hand.BigBlindAmount),
+		Ante:       chips(hand.AnteAmount),
+		Seats:      []SeatRecord{},
+		Actions:    []ActionRecord{},
+		Result:     &HandResult{Stacks: make(map[string]int)},
+	}
+	fmt.Sscanf(hand.GameNumber, "%d", &rec.HandNumber)
+	for _, p := range players {
+		names[p.ID] = p.Name
+		rec.Seats = append(rec.Seats, SeatRecord{Addr: p.Name, Position: p.Seat - 1, Stack: chips(p.StartingStack)})
+		rec.Result.Stacks[p.Name] = chips(p.StartingStack)
+		if p.Seat == hand.DealerSeat {
+			rec.Button = p.Name
+		}
+	}
+	if rec.Button == "" {
+		return nil, fmt.Errorf("no player on the dealer seat %d", hand.DealerSeat)
+	}
+
+	var (
+		holeCards = make(map[string][]deck.Card)
+		board     = []deck.Card{}
+		shown     = make(map[string]bool)
+		// Bets of the current street, to turn amounts into raise sizes.
+		streetBets = make(map[string]int)
+		currentBet int
+	)
+
+	for _, round := range hand.Rounds {
+		if round.Street != "Preflop" && round.Street != "Showdown" {
+			streetBets = make(map[string]int)
+			currentBet = 0
+		}
+		for _, c := range round.Cards {
+			card, err := deck.ParseCard(c)
+			if err != nil {
+				return nil, err
+			}
+			board = append(board, card)
+		}
+
+		for _, a := range round.Actions {
+			addr, ok := names[a.PlayerID]
+			if !ok {
+				return nil, fmt.Errorf("unknown player id %d", a.PlayerID)
+			}
+			amount := chips(a.Amount)
+			rec.Result.Stacks[addr] -= amount
+
+			switch a.Action {
+			case ohhPostAnte:
+			case ohhPostSB, ohhPostBB:
+				streetBets[addr] += amount
+				currentBet = max(currentBet, streetBets[addr])
+			case ohhDealtCards, ohhShowCards:
+				cards, err := parseCards(a.Cards)
+				if err != nil {
+					return nil, err
+				}
+				if len(cards) > 0 {
+					holeCards[addr] = cards
+				}
+				if a.Action == ohhShowCards {
+					shown[addr] = true
+				}
+			case ohhMuckCards:
+			case ohhFold:
+				rec.Actions = append(rec.Actions, ActionRecord{Addr: addr, Action: PlayerActionFold.String()})
+			case ohhCheck:
+				rec.Actions = append(rec.Actions, ActionRecord{Addr: addr, Action: PlayerActionCheck.String()})
+			case ohhCall:
+				streetBets[addr] += amount
+				rec.Actions = append(rec.Actions, ActionRecord{Addr: addr, Action: PlayerActionCall.String()})
+			case ohhBet, ohhRaise:
+				streetBets[addr] += amount
+				action := PlayerActionRaise
+				if a.Action == ohhBet {
+					action = PlayerActionBet
+				}
+				rec.Actions = append(rec.Actions, ActionRecord{Addr: addr, Action: action.String(), Amount: streetBets[addr] - currentBet})
+				currentBet = streetBets[addr]
+			default:
+				return nil, fmt.Errorf("unsupported action %q", a.Action)
+			}
+		}
+	}
+
+	// The rake formula of the site is unknown, the hand is played without
+	// rake and the rake is given back to the winners.
+	for _, pot := range hand.Pots {
+		rec.Result.Pots = append(rec.Result.Pots, chips(pot.Amount))
+		rake := chips(pot.Rake)
+		for _, win := range pot.PlayerWins {
+			rec.Result.Stacks[names[win.PlayerID]] += chips(win.WinAmount) + chips(win.ContributedRake)
+			rake -= chips(win.ContributedRake)
+		}
+		if len(pot.PlayerWins) > 0 {
+			rec.Result.Stacks[names[pot.PlayerWins[0].PlayerID]] += rake
+		}
+	}
+
+	cards, err := stackDeck(rec.Seats, holeCards, board)
+	if err != nil {
+		return nil, err
+	}
+	rec.Deck = shortCards(cards)
+
+	// Play the betting to find out the order of the showdown.
+	pg, err := newReplayGame(rec)
+	if err != nil {
+		return nil, err
+	}
+	for _, action := range rec.Actions {
+		if err := pg.replayAction(action); err != nil {
+			return rec, nil
+		}
+	}
+	for pg.gameStarted && pg.currentRound == Showdown {
+		addr := pg.actionOn
+		action := ActionRecord{Addr: addr, Action: actionMuck}
+		if shown[addr] || !pg.isBeaten(addr) {
+			action.Action = actionShow
+		}
+		rec.Actions = append(rec.Actions, action)
+		if err := pg.replayAction(action); err != nil {
+			return nil, err
+		}
+	}
+
+	return rec, nil
+}
+
+// chipScale returns the factor to turn the amounts of the hand into whole
+// chips.
+func (h *OHHHand) chipScale() float64 {
+	amounts := []float64{h.SmallBlindAmount, h.BigBlindAmount, h.AnteAmount}
+	for _, p := range h.Players {
+		amounts = append(amounts, p.StartingStack)
+	}
+	for _, r := range h.Rounds {
+		for _, a := range r.Actions {
+			amounts = append(amounts, a.Amount)
+		}
+	}
+
+	for _, a := range amounts {
+		if a != math.Trunc(a) {
+			return 100
+		}
+	}
+	return 1
+}
+
+func parseCards(s []string) ([]deck.Card, error) {
+	cards := make([]deck.Card, 0, len(s))
+	for i, c := range s {
+		// Cards dealt face down that were never revealed.
+		if c == faceDownShort {
+			cards = append(cards, deck.FaceDown(i))
+			continue
+		}
+		card, err := deck.ParseCard(c)
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, card)
+	}
+	return cards, nil
+}
+
+// stackDeck orders a deck so that dealing it gives every seat its known hole
+// cards and results in the given board.
+func stackDeck(seats []SeatRecord, holeCards map[string][]deck.Card, board []deck.Card) ([]deck.Card, error) {
+	var (
+		n     = len(seats)
+		cards = make([]deck.Card, 2*n+5)
+		set   = make([]bool, len(cards))
+		used  = make(map[deck.Card]bool)
+	)
+
+	place := func(i int, card deck.Card) error {
+		if used[card] {
+			return fmt.Errorf("card %s is dealt twice", card.Short())
+		}
+		cards[i] = card
+		set[i] = true
+		used[card] = true
+		return nil
+	}
+
+	for j, seat := range seats {
+		for i, card := range holeCards[seat.Addr] {
+			if i > 1 {
+				return nil, fmt.Errorf("too many hole cards for %s", seat.Addr)
+			}
+			if err := place(i*n+j, card); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(board) > 5 {
+		return nil, fmt.Errorf("too many board cards")
+	}
+	for i, card := range board {
+		if err := place(2*n+i, card); err != nil {
+			return nil, err
+		}
+	}
+
+	// Unknown cards get the lowest cards left, which will lose against
+	// pretty much every hand that was shown.
+	remaining := []deck.Card{}
+	for value := 2; value <= 13; value++ {
+		for suit := deck.Spades; suit <= deck.Clubs; suit++ {
+			remaining = append(remaining, deck.NewCard(suit, value))
+		}
+	}
+	for suit := deck.Spades; suit <= deck.Clubs; suit++ {
+		remaining = append(remaining, deck.NewCard(suit, 1))
+	}
+	for i := range cards {
+		if set[i] {
+			continue
+		}
+		for len(remaining) > 0 && used[remaining[0]] {
+			remaining = remaining[1:]
+		}
+		if err := place(i, remaining[0]); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, card := range remaining {
+		if !used[card] {
+			cards = append(cards, card)
+			used[card] = true
+		}
+	}
+
+	return cards, nil
+}
+
+// ReplayOpenHandHistory replays the hand through PokerGame and reports
+// every difference in pots and stacks with the original hand.
+func ReplayOpenHandHistory(h *OpenHandHistory) (*ReplayResult, error) {
+	rec, err := h.HandRecord()
+	if err != nil {
+		return nil, err
+	}
+
+	return ReplayHand(rec)
+}
@@ -0,0 +1,139 @@
+package p2p
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenHandHistoryExport(t *testing.T) {
+	game := playRecordedHand(t)
+
+	h, err := game.OpenHandHistory("ggpoker", 1, ":1")
+	assert.Nil(t, err)
+	assert.Equal(t, "1", h.OHH.GameNumber)
+	assert.Equal(t, 2, h.OHH.DealerSeat)
+	assert.Equal(t, 1, h.OHH.HeroPlayerID)
+	assert.Len(t, h.OHH.Players, 3)
+	assert.Equal(t, 500.0, h.OHH.Players[1].StartingStack)
+
+	assert.Equal(t, []string{"Preflop", "Flop", "Turn", "River", "Showdown"},
+		[]string{h.OHH.Rounds[0].Street, h.OHH.Rounds[1].Street, h.OHH.Rounds[2].Street, h.OHH.Rounds[3].Street, h.OHH.Rounds[4].Street})
+
+	preflop := h.OHH.Rounds[0].Actions
+	assert.Equal(t, OHHAction{ActionNumber: 1, PlayerID: 3, Action: ohhPostSB, Amount: 10}, preflop[0])
+	assert.Equal(t, OHHAction{ActionNumber: 2, PlayerID: 1, Action: ohhPostBB, Amount: 20}, preflop[1])
+	assert.Equal(t, ohhDealtCards, preflop[2].Action)
+	assert.Equal(t, shortCards(game.players[":1"].HoleCards), preflop[2].Cards)
+	assert.Equal(t, OHHAction{ActionNumber: 4, PlayerID: 2, Action: ohhRaise, Amount: 60}, preflop[3])
+	assert.Equal(t, OHHAction{ActionNumber: 5, PlayerID: 3, Action: ohhCall, Amount: 50}, preflop[4])
+
+	assert.Len(t, h.OHH.Pots, 1)
+	assert.Equal(t, 380.0, h.OHH.Pots[0].Amount)
+
+	_, err = game.OpenHandHistory("ggpoker", 2, ":1")
+	assert.NotNil(t, err)
+}
+
+func TestOpenHandHistoryRoundTrip(t *testing.T) {
+	game := playRecordedHand(t)
+
+	h, err := game.OpenHandHistory("ggpoker", 1, ":1")
+	assert.Nil(t, err)
+
+	b, err := json.Marshal(h)
+	assert.Nil(t, err)
+	assert.True(t, IsOpenHandHistory(b))
+
+	hands, err := ParseOpenHandHistories(bytes.NewReader(append(append(b, '\n'), b...)))
+	assert.Nil(t, err)
+	assert.Len(t, hands, 2)
+
+	result, err := ReplayOpenHandHistory(hands[0])
+	assert.Nil(t, err)
+	assert.False(t, result.Diverged(), result.Divergences)
+
+	final := result.Steps[len(result.Steps)-1].State
+	for addr, player := range game.players {
+		assert.Equal(t, player.Stack, final.Players[addr].Stack)
+	}
+
+	// A wrong payout is reported.
+	hands[1].OHH.Pots[0].PlayerWins[0].WinAmount -= 10
+	result, err = ReplayOpenHandHistory(hands[1])
+	assert.Nil(t, err)
+	assert.True(t, result.Diverged())
+}
+
+const siteOHH = `{"ohh": {
+  "spec_version": "1.4.6", "site_name": "Site", "game_number": "77",
+  "game_type": "Holdem", "bet_limit": {"bet_type": "NL"}, "table_size": 6,
+  "currency": "USD", "dealer_seat": 1,
+  "small_blind_amount": 0.5, "big_blind_amount": 1, "ante_amount": 0,
+  "hero_player_id": 1,
+  "players": [
+    {"id": 1, "seat": 1, "name": "hero", "starting_stack": 100},
+    {"id": 2, "seat": 4, "name": "villain", "starting_stack": 80.5},
+    {"id": 3, "seat": 6, "name": "other", "starting_stack": 50}
+  ],
+  "rounds": [
+    {"id": 0, "street": "Preflop", "actions": [
+      {"action_number": 1, "player_id": 2, "action": "Post SB", "amount": 0.5},
+      {"action_number": 2, "player_id": 3, "action": "Post BB", "amount": 1},
+      {"action_number": 3, "player_id": 1, "action": "Dealt Cards", "cards": ["Kh", "Kd"]},
+      {"action_number": 4, "player_id": 1, "action": "Raise", "amount": 3},
+      {"action_number": 5, "player_id": 2, "action": "Call", "amount": 2.5},
+      {"action_number": 6, "player_id": 3, "action": "Fold"}
+    ]},
+    {"id": 1, "street": "Flop", "cards": ["Kc", "7s", "2d"], "actions": [
+      {"action_number": 7, "player_id": 2, "action": "Check"},
+      {"action_number": 8, "player_id": 1, "action": "Bet", "amount": 4.5},
+      {"action_number": 9, "player_id": 2, "action": "Raise", "amount": 15},
+      {"action_number": 10, "player_id": 1, "action": "Call", "amount": 10.5}
+    ]},
+    {"id": 2, "street": "Turn", "cards": ["9h"], "actions": [
+      {"action_number": 11, "player_id": 2, "action": "Check"},
+      {"action_number": 12, "player_id": 1, "action": "Check"}
+    ]},
+    {"id": 3, "street": "River", "cards": ["3c"], "actions": [
+      {"action_number": 13, "player_id": 2, "action": "Check"},
+      {"action_number": 14, "player_id": 1, "action": "Check"}
+    ]},
+    {"id": 4, "street": "Showdown", "actions": [
+      {"action_number": 15, "player_id": 1, "action": "Shows Cards", "cards": ["Kh", "Kd"]},
+      {"action_number": 16, "player_id": 2, "action": "Mucks Cards"}
+    ]}
+  ],
+  "pots": [
+    {"number": 0, "amount": 37, "rake": 0, "jackpot": 0,
+     "player_wins": [{"player_id": 1, "win_amount": 37, "contributed_rake": 0}]}
+  ]
+}}`
+
+func TestOpenHandHistoryImport(t *testing.T) {
+	hands, err := ParseOpenHandHistories(strings.NewReader(siteOHH))
+	assert.Nil(t, err)
+	assert.Len(t, hands, 1)
+
+	rec, err := hands[0].HandRecord()
+	assert.Nil(t, err)
+	assert.Equal(t, 77, rec.HandNumber)
+	assert.Equal(t, 50, rec.SmallBlind)
+	assert.Equal(t, 100, rec.BigBlind)
+	assert.Equal(t, "hero", rec.Button)
+	assert.Equal(t, []int{3700}, rec.Result.Pots)
+	assert.Equal(t, map[string]int{"hero": 11900, "villain": 6250, "other": 4900}, rec.Result.Stacks)
+
+	// The showdown follows our own rules: the last aggressor shows first and
+	// cannot muck, their hole cards are filled up with low cards.
+	last := rec.Actions[len(rec.Actions)-2:]
+	assert.Equal(t, ActionRecord{Addr: "villain", Action: actionShow}, last[0])
+	assert.Equal(t, ActionRecord{Addr: "hero", Action: actionShow}, last[1])
+
+	result, err := ReplayHand(rec)
+	assert.Nil(t, err)
+	assert.False(t, result.Diverged(), result.Divergences)
+}
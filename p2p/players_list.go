@@ -1,8 +1,10 @@
 package p2p
 
 import (
+	"slices"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 )
 
@@ -31,14 +33,35 @@ func (p *PlayersList) len() int {
 	return len(p.list)
 }
 
+// add puts the player on the list, once. Players that dial each other at
+// the same time are connected twice.
 func (p *PlayersList) add(addr string) {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
+	if slices.Contains(p.list, addr) {
+		return
+	}
 	p.list = append(p.list, addr)
 	sort.Sort(p)
 }
 
+// remove takes the player off the list. It reports false when the player
+// was not on it.
+func (p *PlayersList) remove(addr string) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for i := 0; i < len(p.list); i++ {
+		if addr == p.list[i] {
+			// List hands out the slice, so it is not changed in place.
+			p.list = append(append([]string{}, p.list[:i]...), p.list[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
 func (p *PlayersList) getIndex(addr string) int {
 	p.lock.RLock()
 	defer p.lock.RUnlock()
@@ -74,9 +97,16 @@ func (p *PlayersList) Len() int { return len(p.list) }
 func (p *PlayersList) Swap(i, j int) {
 	p.list[i], p.list[j] = p.list[j], p.list[i]
 }
+
+// Less orders players by port when they are known by their listen address,
+// and by player ID otherwise. All nodes have to agree on the order, as it
+// gives the seats.
 func (p *PlayersList) Less(i, j int) bool {
-	portI, _ := strconv.Atoi(p.list[i][1:])
-	portJ, _ := strconv.Atoi(p.list[j][1:])
+	portI, errI := strconv.Atoi(strings.TrimPrefix(p.list[i], ":"))
+	portJ, errJ := strconv.Atoi(strings.TrimPrefix(p.list[j], ":"))
+	if errI != nil || errJ != nil {
+		return p.list[i] < p.list[j]
+	}
 
 	return portI < portJ
 }
@@ -2,8 +2,12 @@ package p2p
 
 import (
 	"fmt"
+	"math/rand"
+	"slices"
 	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/koshiq/ggpoker/deck"
 )
@@ -37,22 +41,38 @@ func (br BettingRound) String() string {
 
 type PlayerState struct {
 	Addr         string
-	Stack        int          // Current chip stack
-	Bet          int          // Current bet in this round
-	TotalBet     int          // Total bet in this hand
-	Folded       bool         // Whether player has folded
-	AllIn        bool         // Whether player is all-in
-	HoleCards    []deck.Card  // Player's hole cards
-	LastAction   PlayerAction // Last action taken
-	IsDealer     bool         // Whether player is dealer
-	IsSmallBlind bool         // Whether player is small blind
-	IsBigBlind   bool         // Whether player is big blind
-	Position     int          // Seat position at table
+	Stack        int           // Current chip stack
+	Bet          int           // Current bet in this round
+	TotalBet     int           // Total bet in this hand
+	Folded       bool          // Whether player has folded
+	AllIn        bool          // Whether player is all-in
+	HasActed     bool          // Whether player has acted in this betting round
+	HoleCards    []deck.Card   // Player's hole cards
+	ShownCards   []deck.Card   // Hole cards exposed to the table
+	Mucked       bool          // Whether player mucked at showdown
+	LastAction   PlayerAction  // Last action taken
+	IsDealer     bool          // Whether player is dealer
+	IsSmallBlind bool          // Whether player is small blind
+	IsBigBlind   bool          // Whether player is big blind
+	Position     int           // Seat position at table
+	TimeBank     time.Duration // Time left in the time bank
+	TimeOuts     int           // Consecutive actions that timed out
+	Bounty       int           // Bounty for knocking the player out
+	BountiesWon  int           // Bounties collected, paid out apart from the stack
+
+	SittingOut         bool // Whether player is not dealt in
+	SitOutNextHand     bool // Sit out once the current hand is over
+	SitOutNextBigBlind bool // Sit out instead of paying the next big blind
+	SitInNextHand      bool // Return to play at the next hand
+	WaitForBigBlind    bool // Return to play once it is this player's big blind
+	PostBigBlind       bool // Post a big blind at the next hand to return
+	SatOutHand         int  // First hand missed while sitting out
+	Disconnected       bool // Lost the connection, leaves after the hand
 }
 
 type Pot struct {
-	Amount  int
-	Players []string // Players eligible for this pot
+	Amount  int      `json:"amount"`
+	Players []string `json:"players"` // Players eligible for this pot
 }
 
 type PokerGame struct {
@@ -66,11 +86,51 @@ type PokerGame struct {
 	minRaise       int
 	smallBlind     int
 	bigBlind       int
+	ante           int
 	dealerPos      int
-	activePlayers  []string
-	lastRaise      string
-	gameStarted    bool
-	handNumber     int
+	// buttonSeat is the seat position of the last button, the button moves
+	// on to the next seat in play. It is -1 before the first hand.
+	buttonSeat    int
+	activePlayers []string
+	lastRaise     string
+	gameStarted   bool
+	handNumber    int
+
+	// actionOn is the player that has to act next, either in a betting
+	// round or at showdown.
+	actionOn string
+	// showdownOrder is the order in which players show or muck, starting
+	// with the last aggressor.
+	showdownOrder []string
+	showdownPos   int
+	// foldWinner is set when the last hand ended because everybody else
+	// folded. That player may still choose to show cards.
+	foldWinner string
+	// revealPending is set when the hand is ready for the showdown, but
+	// cards dealt face down still have to be revealed.
+	revealPending *EventRevealPending
+
+	// timers are the action timeouts of the table, timer is the timer of
+	// the player that is currently on the move.
+	timers TimerConfig
+	timer  *actionTimer
+	buyIn  BuyInConfig
+	rake   RakeConfig
+	bounty BountyConfig
+	// potRake is the rake taken from each pot of the current hand.
+	potRake []int
+	// actionStarted and actionTimeout describe the running timer as
+	// recorded in the event log.
+	actionStarted time.Time
+	actionTimeout time.Duration
+
+	// events is the append-only log of every state change.
+	events        []EventRecord
+	handlers      map[int]EventHandler
+	nextHandlerID int
+
+	// onTimeout takes over expired timers, see OnTimeout.
+	onTimeout func(addr string)
 }
 
 func NewPokerGame(smallBlind, bigBlind int) *PokerGame {
@@ -88,8 +148,11 @@ func NewPokerGame(smallBlind, bigBlind int) *PokerGame {
 		smallBlind:     smallBlind,
 		bigBlind:       bigBlind,
 		dealerPos:      0,
+		buttonSeat:     -1,
 		activePlayers:  make([]string, 0),
 		handNumber:     0,
+		events:         make([]EventRecord, 0),
+		handlers:       make(map[int]EventHandler),
 	}
 }
 
@@ -97,40 +160,134 @@ func (pg *PokerGame) AddPlayer(addr string, stack int, position int) error {
 	pg.mu.Lock()
 	defer pg.mu.Unlock()
 
-	if _, exists := pg.players[addr]; exists {
-		return fmt.Errorf("player %s already exists", addr)
+	// Players joining during a hand are dealt in once it is their turn to
+	// pay the big blind.
+	return pg.seatPlayer(EventPlayerJoined{
+		Addr:            addr,
+		Stack:           stack,
+		Position:        position,
+		Bounty:          pg.bounty.Amount,
+		WaitForBigBlind: pg.gameStarted,
+	})
+}
+
+func (pg *PokerGame) seatPlayer(ev EventPlayerJoined) error {
+	if _, exists := pg.players[ev.Addr]; exists {
+		return fmt.Errorf("player %s already exists", ev.Addr)
+	}
+
+	if err := pg.validateBuyIn(ev.Stack); err != nil {
+		return err
+	}
+
+	ev.TimeBank = pg.timers.TimeBank
+	return pg.record(ev)
+}
+
+// RemovePlayer takes a player off the table. Players that are dealt in can
+// only leave once the hand is over.
+func (pg *PokerGame) RemovePlayer(addr string) error {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	player, ok := pg.players[addr]
+	if !ok {
+		return fmt.Errorf("player %s not found", addr)
 	}
+	if pg.gameStarted && !player.SittingOut {
+		return fmt.Errorf("player %s is dealt in hand %d", addr, pg.handNumber)
+	}
+
+	return pg.record(EventPlayerLeft{Addr: addr})
+}
 
-	pg.players[addr] = &PlayerState{
-		Addr:       addr,
-		Stack:      stack,
-		Position:   position,
-		Bet:        0,
-		TotalBet:   0,
-		Folded:     false,
-		AllIn:      false,
-		HoleCards:  make([]deck.Card, 0),
-		LastAction: PlayerActionNone,
+// SetBlinds changes the blinds and the ante, starting with the next hand.
+func (pg *PokerGame) SetBlinds(smallBlind, bigBlind, ante int) error {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	if bigBlind <= 0 || smallBlind < 0 || smallBlind > bigBlind || ante < 0 {
+		return fmt.Errorf("invalid blinds %d/%d with ante %d", smallBlind, bigBlind, ante)
+	}
+	if pg.gameStarted {
+		return fmt.Errorf("can't change the blinds during hand %d", pg.handNumber)
 	}
 
+	pg.smallBlind = smallBlind
+	pg.bigBlind = bigBlind
+	pg.ante = ante
+	pg.minRaise = bigBlind
+
 	return nil
 }
 
 func (pg *PokerGame) StartNewHand() error {
+	return pg.StartNewHandWithSeed(rand.Int63())
+}
+
+// StartNewHandWithSeed starts a new hand with a deck shuffled by the given
+// seed, which makes the hand reproducible.
+func (pg *PokerGame) StartNewHandWithSeed(seed int64) error {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	if err := pg.startNewHand(EventHandStarted{Seed: seed}); err != nil {
+		return err
+	}
+
+	return pg.startTimer()
+}
+
+// StartNewHandWithDeck starts a new hand dealt from the given cards in
+// order. It is used to play hands of which the cards are already known.
+func (pg *PokerGame) StartNewHandWithDeck(cards []deck.Card) error {
 	pg.mu.Lock()
 	defer pg.mu.Unlock()
 
-	if len(pg.players) < 2 {
+	if err := pg.startNewHand(EventHandStarted{Deck: append([]deck.Card{}, cards...)}); err != nil {
+		return err
+	}
+
+	return pg.startTimer()
+}
+
+func (pg *PokerGame) startNewHand(ev EventHandStarted) error {
+	if pg.gameStarted {
+		return fmt.Errorf("hand %d is still in progress", pg.handNumber)
+	}
+
+	if err := pg.prepareSeats(); err != nil {
+		return err
+	}
+
+	playerAddrs := pg.seatOrder()
+	if len(playerAddrs) < 2 {
 		return fmt.Errorf("need at least 2 players to start a hand")
 	}
 
-	// Reset game state
-	pg.resetHand()
+	// Move dealer button and reset game state
+	ev.HandNumber = pg.handNumber + 1
+	ev.DealerPos = pg.nextButton(playerAddrs)
+	ev.SmallBlind = pg.smallBlind
+	ev.BigBlind = pg.bigBlind
+	ev.Ante = pg.ante
+	if err := pg.record(ev); err != nil {
+		return err
+	}
 
-	// Move dealer button
-	pg.moveDealerButton()
+	if pg.timers.TimeBankRefill > 0 {
+		if err := pg.record(EventTimeBanksRefilled{
+			Amount: pg.timers.TimeBankRefill,
+			Max:    pg.timers.TimeBank,
+		}); err != nil {
+			return err
+		}
+	}
 
-	// Post blinds
+	// Post antes and blinds
+	if err := pg.postAntes(); err != nil {
+		return err
+	}
 	if err := pg.postBlinds(); err != nil {
 		return err
 	}
@@ -140,21 +297,27 @@ func (pg *PokerGame) StartNewHand() error {
 		return err
 	}
 
-	pg.gameStarted = true
-	pg.handNumber++
+	// Blinds can put players all-in, in which case there is nothing to bet.
+	if pg.actionOn == "" || pg.isBettingRoundComplete() {
+		return pg.endBettingRound()
+	}
 
 	return nil
 }
 
 func (pg *PokerGame) resetHand() {
 	pg.communityCards = make([]deck.Card, 0)
-	deckArray := deck.New()
-	pg.deck = deckArray[:]
 	pg.currentRound = PreFlop
 	pg.pot = make([]Pot, 0)
 	pg.currentBet = 0
 	pg.minRaise = pg.bigBlind
 	pg.lastRaise = ""
+	pg.actionOn = ""
+	pg.showdownOrder = nil
+	pg.showdownPos = 0
+	pg.foldWinner = ""
+	pg.revealPending = nil
+	pg.potRake = nil
 
 	// Reset player states
 	for _, player := range pg.players {
@@ -162,7 +325,10 @@ func (pg *PokerGame) resetHand() {
 		player.TotalBet = 0
 		player.Folded = false
 		player.AllIn = false
+		player.HasActed = false
 		player.HoleCards = make([]deck.Card, 0)
+		player.ShownCards = nil
+		player.Mucked = false
 		player.LastAction = PlayerActionNone
 		player.IsDealer = false
 		player.IsSmallBlind = false
@@ -170,18 +336,58 @@ func (pg *PokerGame) resetHand() {
 	}
 }
 
-func (pg *PokerGame) moveDealerButton() {
+// seatOrder returns the players that are dealt in, ordered by their seat.
+func (pg *PokerGame) seatOrder() []string {
+	playerAddrs := make([]string, 0, len(pg.players))
+	for _, addr := range pg.allSeats() {
+		if !pg.players[addr].SittingOut {
+			playerAddrs = append(playerAddrs, addr)
+		}
+	}
+
+	return playerAddrs
+}
+
+// allSeats returns every seated player, including the ones sitting out.
+func (pg *PokerGame) allSeats() []string {
 	playerAddrs := make([]string, 0, len(pg.players))
 	for addr := range pg.players {
 		playerAddrs = append(playerAddrs, addr)
 	}
 
-	// Sort by position
 	sort.Slice(playerAddrs, func(i, j int) bool {
 		return pg.players[playerAddrs[i]].Position < pg.players[playerAddrs[j]].Position
 	})
 
-	pg.dealerPos = (pg.dealerPos + 1) % len(playerAddrs)
+	return playerAddrs
+}
+
+// nextToAct returns the first player after the given seat index that can
+// still act in the current betting round. It returns an empty string if
+// nobody can act.
+func (pg *PokerGame) nextToAct(fromPos int) string {
+	playerAddrs := pg.seatOrder()
+	for i := 1; i <= len(playerAddrs); i++ {
+		player := pg.players[playerAddrs[(fromPos+i)%len(playerAddrs)]]
+		if !player.Folded && !player.AllIn {
+			return player.Addr
+		}
+	}
+	return ""
+}
+
+func (pg *PokerGame) seatIndex(addr string) int {
+	for i, a := range pg.seatOrder() {
+		if a == addr {
+			return i
+		}
+	}
+	return -1
+}
+
+// markButton flags the dealer and the blinds relative to dealerPos.
+func (pg *PokerGame) markButton() {
+	playerAddrs := pg.seatOrder()
 
 	// Set dealer
 	pg.players[playerAddrs[pg.dealerPos]].IsDealer = true
@@ -195,109 +401,146 @@ func (pg *PokerGame) moveDealerButton() {
 	pg.players[playerAddrs[bigBlindPos]].IsBigBlind = true
 }
 
-func (pg *PokerGame) postBlinds() error {
-	playerAddrs := make([]string, 0, len(pg.players))
-	for addr := range pg.players {
-		playerAddrs = append(playerAddrs, addr)
+// postAntes takes the ante of every player dealt in. Antes go into the pot
+// but don't count as a bet.
+func (pg *PokerGame) postAntes() error {
+	if pg.ante == 0 {
+		return nil
 	}
 
-	sort.Slice(playerAddrs, func(i, j int) bool {
-		return pg.players[playerAddrs[i]].Position < pg.players[playerAddrs[j]].Position
-	})
+	for _, addr := range pg.seatOrder() {
+		if err := pg.record(EventAntePosted{
+			Addr:   addr,
+			Amount: min(pg.ante, pg.players[addr].Stack),
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (pg *PokerGame) postBlinds() error {
+	playerAddrs := pg.seatOrder()
 
 	// Post small blind
 	smallBlindPos := (pg.dealerPos + 1) % len(playerAddrs)
 	smallBlindPlayer := pg.players[playerAddrs[smallBlindPos]]
-	smallBlindAmount := min(pg.smallBlind, smallBlindPlayer.Stack)
-	smallBlindPlayer.Bet = smallBlindAmount
-	smallBlindPlayer.TotalBet = smallBlindAmount
-	smallBlindPlayer.Stack -= smallBlindAmount
+	if err := pg.record(EventBlindPosted{
+		Addr:   smallBlindPlayer.Addr,
+		Amount: min(pg.smallBlind, smallBlindPlayer.Stack),
+	}); err != nil {
+		return err
+	}
 
 	// Post big blind
 	bigBlindPos := (pg.dealerPos + 2) % len(playerAddrs)
 	bigBlindPlayer := pg.players[playerAddrs[bigBlindPos]]
-	bigBlindAmount := min(pg.bigBlind, bigBlindPlayer.Stack)
-	bigBlindPlayer.Bet = bigBlindAmount
-	bigBlindPlayer.TotalBet = bigBlindAmount
-	bigBlindPlayer.Stack -= bigBlindAmount
+	if err := pg.record(EventBlindPosted{
+		Addr:   bigBlindPlayer.Addr,
+		Amount: min(pg.bigBlind, bigBlindPlayer.Stack),
+	}); err != nil {
+		return err
+	}
 
-	pg.currentBet = bigBlindAmount
-	pg.minRaise = pg.bigBlind
+	// Players returning from sitting out post a big blind out of position.
+	for _, addr := range playerAddrs {
+		player := pg.players[addr]
+		if !player.PostBigBlind || player.IsSmallBlind || player.IsBigBlind {
+			continue
+		}
+		if err := pg.record(EventBlindPosted{
+			Addr:   addr,
+			Amount: min(pg.bigBlind, player.Stack),
+			Extra:  true,
+		}); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
 func (pg *PokerGame) dealHoleCards() error {
-	playerAddrs := make([]string, 0, len(pg.players))
-	for addr := range pg.players {
-		playerAddrs = append(playerAddrs, addr)
-	}
+	playerAddrs := pg.seatOrder()
+	holeCards := make(map[string][]deck.Card)
 
-	sort.Slice(playerAddrs, func(i, j int) bool {
-		return pg.players[playerAddrs[i]].Position < pg.players[playerAddrs[j]].Position
-	})
+	cards, err := pg.peekCards(2 * len(playerAddrs))
+	if err != nil {
+		return err
+	}
 
 	// Deal 2 cards to each player
 	for i := 0; i < 2; i++ {
-		for _, addr := range playerAddrs {
-			if len(pg.deck) == 0 {
-				return fmt.Errorf("not enough cards in deck")
-			}
-			card := pg.deck[0]
-			pg.deck = pg.deck[1:]
-			pg.players[addr].HoleCards = append(pg.players[addr].HoleCards, card)
+		for j, addr := range playerAddrs {
+			holeCards[addr] = append(holeCards[addr], cards[i*len(playerAddrs)+j])
+		}
+	}
+
+	for _, addr := range playerAddrs {
+		if err := pg.record(EventCardsDealt{Addr: addr, Cards: holeCards[addr]}); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
+// peekCards returns the next n cards of the deck. The cards are taken off
+// the deck once the event dealing them is applied.
+func (pg *PokerGame) peekCards(n int) ([]deck.Card, error) {
+	if len(pg.deck) < n {
+		return nil, fmt.Errorf("not enough cards in deck")
+	}
+
+	return append([]deck.Card{}, pg.deck[:n]...), nil
+}
+
+func (pg *PokerGame) removeFromDeck(cards []deck.Card) {
+	remaining := make([]deck.Card, 0, len(pg.deck))
+	for _, card := range pg.deck {
+		if !containsCard(cards, card) {
+			remaining = append(remaining, card)
+		}
+	}
+	pg.deck = remaining
+}
+
 func (pg *PokerGame) DealCommunityCards() error {
 	pg.mu.Lock()
 	defer pg.mu.Unlock()
 
+	return pg.dealCommunityCards()
+}
+
+func (pg *PokerGame) dealCommunityCards() error {
+	var (
+		next  BettingRound
+		count int
+	)
+
 	switch pg.currentRound {
 	case PreFlop:
 		// Deal flop (3 cards)
-		for i := 0; i < 3; i++ {
-			if len(pg.deck) == 0 {
-				return fmt.Errorf("not enough cards in deck")
-			}
-			card := pg.deck[0]
-			pg.deck = pg.deck[1:]
-			pg.communityCards = append(pg.communityCards, card)
-		}
-		pg.currentRound = Flop
-
+		next, count = Flop, 3
 	case Flop:
 		// Deal turn (1 card)
-		if len(pg.deck) == 0 {
-			return fmt.Errorf("not enough cards in deck")
-		}
-		card := pg.deck[0]
-		pg.deck = pg.deck[1:]
-		pg.communityCards = append(pg.communityCards, card)
-		pg.currentRound = Turn
-
+		next, count = Turn, 1
 	case Turn:
 		// Deal river (1 card)
-		if len(pg.deck) == 0 {
-			return fmt.Errorf("not enough cards in deck")
-		}
-		card := pg.deck[0]
-		pg.deck = pg.deck[1:]
-		pg.communityCards = append(pg.communityCards, card)
-		pg.currentRound = River
-
+		next, count = River, 1
 	case River:
-		pg.currentRound = Showdown
-		return pg.determineWinner()
+		return pg.showdown(false)
+	default:
+		return fmt.Errorf("cannot deal community cards at %s", pg.currentRound)
 	}
 
-	// Reset betting for new round
-	pg.resetBettingRound()
+	cards, err := pg.peekCards(count)
+	if err != nil {
+		return err
+	}
 
-	return nil
+	return pg.record(EventStreetDealt{Round: next, Cards: cards})
 }
 
 func (pg *PokerGame) resetBettingRound() {
@@ -307,6 +550,7 @@ func (pg *PokerGame) resetBettingRound() {
 
 	for _, player := range pg.players {
 		player.Bet = 0
+		player.HasActed = false
 	}
 }
 
@@ -314,11 +558,52 @@ func (pg *PokerGame) PlayerAction(addr string, action PlayerAction, amount int)
 	pg.mu.Lock()
 	defer pg.mu.Unlock()
 
+	if err := pg.playerAction(addr, action, amount); err != nil {
+		return err
+	}
+
+	return pg.startTimer()
+}
+
+func (pg *PokerGame) playerAction(addr string, action PlayerAction, amount int) error {
+	if err := pg.validateAction(addr, action, amount); err != nil {
+		return err
+	}
+
+	if err := pg.stopTimer(addr, false); err != nil {
+		return err
+	}
+
+	if err := pg.record(EventActionTaken{
+		Addr:   addr,
+		Action: action,
+		Amount: amount,
+	}); err != nil {
+		return err
+	}
+
+	if pg.countInHand() == 1 {
+		return pg.winByFold()
+	}
+
+	// Check if betting round is complete
+	if pg.isBettingRoundComplete() {
+		return pg.endBettingRound()
+	}
+
+	return nil
+}
+
+func (pg *PokerGame) validateAction(addr string, action PlayerAction, amount int) error {
 	player, exists := pg.players[addr]
 	if !exists {
 		return fmt.Errorf("player %s not found", addr)
 	}
 
+	if !pg.gameStarted || pg.currentRound == Showdown {
+		return fmt.Errorf("no betting round in progress")
+	}
+
 	if player.Folded {
 		return fmt.Errorf("player %s has already folded", addr)
 	}
@@ -327,200 +612,253 @@ func (pg *PokerGame) PlayerAction(addr string, action PlayerAction, amount int)
 		return fmt.Errorf("player %s is all-in", addr)
 	}
 
+	if pg.actionOn != addr {
+		return fmt.Errorf("player %s acting out of turn, waiting for %s", addr, pg.actionOn)
+	}
+
 	switch action {
 	case PlayerActionFold:
-		player.Folded = true
-		player.LastAction = PlayerActionFold
 
 	case PlayerActionCheck:
-		if player.TotalBet < pg.currentBet {
+		if player.Bet < pg.currentBet {
 			return fmt.Errorf("cannot check when there's a bet to call")
 		}
-		player.LastAction = PlayerActionCheck
 
 	case PlayerActionCall:
-		callAmount := pg.currentBet - player.TotalBet
-		if callAmount > player.Stack {
-			// All-in
-			player.Bet = player.Stack
-			player.TotalBet += player.Stack
-			player.Stack = 0
-			player.AllIn = true
-		} else {
-			player.Bet = callAmount
-			player.TotalBet += callAmount
-			player.Stack -= callAmount
+		if pg.currentBet == player.Bet {
+			return fmt.Errorf("nothing to call")
 		}
-		player.LastAction = PlayerActionCall
 
-	case PlayerActionBet:
-		if amount < pg.minRaise {
-			return fmt.Errorf("bet must be at least %d", pg.minRaise)
+	case PlayerActionBet, PlayerActionRaise:
+		if action == PlayerActionBet && pg.currentBet > 0 {
+			return fmt.Errorf("cannot bet when there's a bet to call, raise instead")
 		}
-		if amount > player.Stack {
-			return fmt.Errorf("insufficient chips")
+		if action == PlayerActionRaise && pg.currentBet == 0 {
+			return fmt.Errorf("cannot raise when there's no bet, bet instead")
 		}
-		player.Bet = amount
-		player.TotalBet += amount
-		player.Stack -= amount
-		pg.currentBet = player.TotalBet
-		pg.minRaise = amount
-		pg.lastRaise = addr
-		player.LastAction = PlayerActionBet
-
-	case PlayerActionRaise:
-		if amount < pg.minRaise {
-			return fmt.Errorf("raise must be at least %d", pg.minRaise)
+		if amount <= 0 {
+			return fmt.Errorf("%s amount must be positive", strings.ToLower(action.String()))
 		}
-		if amount > player.Stack {
+		// amount is the size of the bet or raise on top of the current bet.
+		total := pg.currentBet - player.Bet + amount
+		if total > player.Stack {
 			return fmt.Errorf("insufficient chips")
 		}
-		player.Bet = amount
-		player.TotalBet += amount
-		player.Stack -= amount
-		pg.currentBet = player.TotalBet
-		pg.minRaise = amount
-		pg.lastRaise = addr
-		player.LastAction = PlayerActionRaise
-	}
-
-	// Check if betting round is complete
-	if pg.isBettingRoundComplete() {
-		pg.collectBets()
-		if pg.currentRound != Showdown {
-			pg.DealCommunityCards()
+		// An all-in for less than a full raise is allowed, but does not
+		// reopen the betting.
+		if amount < pg.minRaise && total != player.Stack {
+			return fmt.Errorf("%s must be at least %d", strings.ToLower(action.String()), pg.minRaise)
 		}
+
+	default:
+		return fmt.Errorf("invalid action %s", action)
 	}
 
 	return nil
 }
 
-func (pg *PokerGame) isBettingRoundComplete() bool {
-	activePlayers := 0
-	playersAtCurrentBet := 0
+func (pg *PokerGame) applyAction(e EventActionTaken) {
+	player := pg.players[e.Addr]
 
-	for _, player := range pg.players {
-		if !player.Folded && !player.AllIn {
-			activePlayers++
-			if player.TotalBet == pg.currentBet {
-				playersAtCurrentBet++
+	switch e.Action {
+	case PlayerActionFold:
+		player.Folded = true
+
+	case PlayerActionCall:
+		pg.commitChips(player, pg.currentBet-player.Bet)
+
+	case PlayerActionBet, PlayerActionRaise:
+		pg.commitChips(player, pg.currentBet-player.Bet+e.Amount)
+		if e.Amount >= pg.minRaise {
+			pg.minRaise = e.Amount
+			for _, other := range pg.players {
+				other.HasActed = false
 			}
 		}
+		pg.currentBet = max(pg.currentBet, player.Bet)
+		pg.lastRaise = e.Addr
+	}
+
+	player.LastAction = e.Action
+	player.HasActed = true
+
+	if pg.countInHand() == 1 || pg.isBettingRoundComplete() {
+		pg.collectBets()
+		pg.actionOn = ""
+		return
 	}
 
-	// All active players have either folded, are all-in, or have matched the current bet
-	return activePlayers == playersAtCurrentBet
+	pg.actionOn = pg.nextToAct(pg.seatIndex(e.Addr))
 }
 
-func (pg *PokerGame) collectBets() {
-	// Create main pot
-	mainPot := Pot{Amount: 0, Players: make([]string, 0)}
+// commitChips moves chips from the player's stack into the current bet.
+// A player that cannot cover the amount is all-in for the rest of the stack.
+func (pg *PokerGame) commitChips(player *PlayerState, amount int) {
+	amount = min(amount, player.Stack)
+	player.Bet += amount
+	player.TotalBet += amount
+	player.Stack -= amount
+	if player.Stack == 0 {
+		player.AllIn = true
+	}
+}
 
-	for addr, player := range pg.players {
-		if !player.Folded {
-			mainPot.Players = append(mainPot.Players, addr)
+func (pg *PokerGame) countInHand() int {
+	n := 0
+	for _, addr := range pg.seatOrder() {
+		if !pg.players[addr].Folded {
+			n++
+		}
+	}
+	return n
+}
+
+func (pg *PokerGame) isBettingRoundComplete() bool {
+	for _, addr := range pg.seatOrder() {
+		player := pg.players[addr]
+		if player.Folded || player.AllIn {
+			continue
+		}
+		if player.Bet < pg.currentBet {
+			return false
+		}
+		// A player that is the only one left who can act does not need to
+		// act once the bet is matched.
+		if !player.HasActed && pg.countCanAct() > 1 {
+			return false
 		}
-		mainPot.Amount += player.TotalBet
-		player.TotalBet = 0
 	}
 
-	pg.pot = append(pg.pot, mainPot)
+	return true
 }
 
-func (pg *PokerGame) determineWinner() error {
-	// Find active players (not folded)
-	activePlayers := make([]string, 0)
-	for addr, player := range pg.players {
-		if !player.Folded {
-			activePlayers = append(activePlayers, addr)
+func (pg *PokerGame) countCanAct() int {
+	n := 0
+	for _, addr := range pg.seatOrder() {
+		if player := pg.players[addr]; !player.Folded && !player.AllIn {
+			n++
 		}
 	}
+	return n
+}
+
+// endBettingRound moves the hand to the next street. When no more betting
+// is possible the board is run out and all hands are turned face up.
+func (pg *PokerGame) endBettingRound() error {
+	if pg.currentRound == River {
+		return pg.showdown(false)
+	}
 
-	if len(activePlayers) == 1 {
-		// Last player standing wins
-		winner := pg.players[activePlayers[0]]
-		winner.Stack += pg.pot[0].Amount
-		return nil
+	if pg.countCanAct() < 2 {
+		for pg.currentRound != River {
+			if err := pg.dealCommunityCards(); err != nil {
+				return err
+			}
+		}
+		return pg.showdown(true)
 	}
 
-	// Evaluate hands for all active players
-	playerHands := make(map[string]deck.Hand)
-	for _, addr := range activePlayers {
+	return pg.dealCommunityCards()
+}
+
+// collectBets rebuilds the main pot and side pots from everything the
+// players have put in during this hand.
+func (pg *PokerGame) collectBets() {
+	playerAddrs := pg.seatOrder()
+
+	levels := []int{}
+	for _, addr := range playerAddrs {
 		player := pg.players[addr]
-		allCards := append(player.HoleCards, pg.communityCards...)
-		hand := deck.EvaluateHand(allCards)
-		playerHands[addr] = hand
-	}
-
-	// Find winner(s)
-	winners := make([]string, 0)
-	var bestHand deck.Hand
-
-	for addr, hand := range playerHands {
-		if len(winners) == 0 {
-			winners = append(winners, addr)
-			bestHand = hand
-		} else {
-			comparison := deck.CompareHands(hand, bestHand)
-			if comparison > 0 {
-				// New winner
-				winners = winners[:0]
-				winners = append(winners, addr)
-				bestHand = hand
-			} else if comparison == 0 {
-				// Tie
-				winners = append(winners, addr)
+		if player.TotalBet > 0 && (player.AllIn || !player.Folded) {
+			levels = append(levels, player.TotalBet)
+		}
+	}
+	sort.Ints(levels)
+
+	pots := make([]Pot, 0)
+	prevLevel := 0
+	for _, level := range levels {
+		if level == prevLevel {
+			continue
+		}
+
+		pot := Pot{Amount: 0, Players: make([]string, 0)}
+		for _, addr := range playerAddrs {
+			player := pg.players[addr]
+			pot.Amount += min(player.TotalBet, level) - min(player.TotalBet, prevLevel)
+			if !player.Folded && player.TotalBet >= level {
+				pot.Players = append(pot.Players, addr)
 			}
 		}
+		prevLevel = level
+
+		// Merge pots that are contested by the same players.
+		if n := len(pots); n > 0 && slices.Equal(pots[n-1].Players, pot.Players) {
+			pots[n-1].Amount += pot.Amount
+			continue
+		}
+		pots = append(pots, pot)
 	}
 
-	// Split pot among winners
-	potAmount := pg.pot[0].Amount
-	splitAmount := potAmount / len(winners)
-	remainder := potAmount % len(winners)
+	// Chips of folded players above the highest remaining bet still belong
+	// to the last pot.
+	total := 0
+	for _, player := range pg.players {
+		total += player.TotalBet
+	}
+	collected := 0
+	for _, pot := range pots {
+		collected += pot.Amount
+	}
+	if n := len(pots); n > 0 {
+		pots[n-1].Amount += total - collected
+	}
 
-	for i, winner := range winners {
-		amount := splitAmount
-		if i < remainder {
-			amount++
+	for i, rake := range pg.potRake {
+		if i < len(pots) {
+			pots[i].Amount -= rake
 		}
-		pg.players[winner].Stack += amount
 	}
 
-	return nil
+	pg.pot = pots
 }
 
-func (pg *PokerGame) GetGameState() map[string]interface{} {
-	pg.mu.RLock()
-	defer pg.mu.RUnlock()
-
-	players := make(map[string]interface{})
-	for addr, player := range pg.players {
-		players[addr] = map[string]interface{}{
-			"stack":        player.Stack,
-			"bet":          player.Bet,
-			"totalBet":     player.TotalBet,
-			"folded":       player.Folded,
-			"allIn":        player.AllIn,
-			"holeCards":    player.HoleCards,
-			"lastAction":   player.LastAction,
-			"isDealer":     player.IsDealer,
-			"isSmallBlind": player.IsSmallBlind,
-			"isBigBlind":   player.IsBigBlind,
-			"position":     player.Position,
+// winByFold awards every pot to the last player that did not fold.
+func (pg *PokerGame) winByFold() error {
+	var winner string
+	for _, addr := range pg.seatOrder() {
+		if !pg.players[addr].Folded {
+			winner = addr
 		}
 	}
 
-	return map[string]interface{}{
-		"currentRound":   pg.currentRound.String(),
-		"communityCards": pg.communityCards,
-		"pot":            pg.pot,
-		"currentBet":     pg.currentBet,
-		"minRaise":       pg.minRaise,
-		"players":        players,
-		"handNumber":     pg.handNumber,
-		"gameStarted":    pg.gameStarted,
+	if err := pg.takeRake(); err != nil {
+		return err
 	}
+
+	for i, pot := range pg.pot {
+		if err := pg.record(EventPotAwarded{Pot: i, Addr: winner, Amount: pot.Amount}); err != nil {
+			return err
+		}
+	}
+
+	return pg.record(EventHandEnded{FoldWinner: winner})
+}
+
+// uncalledBet returns the player with the highest bet of the hand and the
+// part of that bet nobody called.
+func (pg *PokerGame) uncalledBet() (string, int) {
+	bets := make([]*PlayerState, 0, len(pg.players))
+	for _, player := range pg.players {
+		bets = append(bets, player)
+	}
+	sort.Slice(bets, func(i, j int) bool { return bets[i].TotalBet > bets[j].TotalBet })
+
+	if len(bets) < 2 || bets[0].TotalBet == bets[1].TotalBet {
+		return "", 0
+	}
+
+	return bets[0].Addr, bets[0].TotalBet - bets[1].TotalBet
 }
 
 func min(a, b int) int {
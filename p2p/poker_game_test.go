@@ -0,0 +1,171 @@
+package p2p
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/koshiq/ggpoker/deck"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestGame(t *testing.T, nPlayers int) *PokerGame {
+	game := NewPokerGame(10, 20)
+	for i := 0; i < nPlayers; i++ {
+		assert.Nil(t, game.AddPlayer(fmt.Sprintf(":%d", i+1), 1000, i))
+	}
+	assert.Nil(t, game.StartNewHand())
+
+	return game
+}
+
+func TestPokerGameShowdownOrder(t *testing.T) {
+	// [:1 BB] [:2 D] [:3 SB]
+	game := newTestGame(t, 3)
+
+	// PREFLOP
+	assert.Nil(t, game.PlayerAction(":2", PlayerActionCall, 0))
+	assert.Nil(t, game.PlayerAction(":3", PlayerActionCall, 0))
+	assert.Nil(t, game.PlayerAction(":1", PlayerActionCheck, 0))
+	assert.Equal(t, Flop, game.currentRound)
+
+	// FLOP + TURN
+	for i := 0; i < 2; i++ {
+		assert.Nil(t, game.PlayerAction(":3", PlayerActionCheck, 0))
+		assert.Nil(t, game.PlayerAction(":1", PlayerActionCheck, 0))
+		assert.Nil(t, game.PlayerAction(":2", PlayerActionCheck, 0))
+	}
+
+	// RIVER
+	assert.Nil(t, game.PlayerAction(":3", PlayerActionCheck, 0))
+	assert.Nil(t, game.PlayerAction(":1", PlayerActionBet, 20))
+	assert.Nil(t, game.PlayerAction(":2", PlayerActionCall, 0))
+	assert.Nil(t, game.PlayerAction(":3", PlayerActionCall, 0))
+
+	assert.Equal(t, Showdown, game.currentRound)
+	assert.Equal(t, []string{":1", ":2", ":3"}, game.showdownOrder)
+
+	game.communityCards = []deck.Card{
+		deck.NewCard(deck.Spades, 2),
+		deck.NewCard(deck.Harts, 7),
+		deck.NewCard(deck.Diamonds, 9),
+		deck.NewCard(deck.Clubs, 11),
+		deck.NewCard(deck.Spades, 4),
+	}
+	game.players[":1"].HoleCards = []deck.Card{deck.NewCard(deck.Spades, 12), deck.NewCard(deck.Harts, 12)}
+	game.players[":2"].HoleCards = []deck.Card{deck.NewCard(deck.Spades, 10), deck.NewCard(deck.Harts, 10)}
+	game.players[":3"].HoleCards = []deck.Card{deck.NewCard(deck.Spades, 9), deck.NewCard(deck.Harts, 9)}
+
+	// Only the last aggressor can start the showdown.
+	assert.NotNil(t, game.ShowHand(":2"))
+	assert.Nil(t, game.ShowHand(":1"))
+	assert.Nil(t, game.MuckHand(":2"))
+	// Trips are not beaten by a pair of queens.
+	assert.NotNil(t, game.MuckHand(":3"))
+	assert.Nil(t, game.ShowHand(":3"))
+
+	assert.False(t, game.gameStarted)
+	assert.Equal(t, 960, game.players[":1"].Stack)
+	assert.Equal(t, 960, game.players[":2"].Stack)
+	assert.Equal(t, 1080, game.players[":3"].Stack)
+
+	snapshot := game.SnapshotFor("")
+	assert.Len(t, snapshot.Players[":1"].HoleCards, 2)
+	assert.Len(t, snapshot.Players[":2"].HoleCards, 0)
+	assert.Len(t, snapshot.Players[":3"].HoleCards, 2)
+}
+
+func TestPokerGameShowOneCardAfterFoldWin(t *testing.T) {
+	game := newTestGame(t, 3)
+
+	assert.Nil(t, game.PlayerAction(":2", PlayerActionFold, 0))
+	assert.Nil(t, game.PlayerAction(":3", PlayerActionFold, 0))
+
+	assert.False(t, game.gameStarted)
+	assert.Equal(t, 1010, game.players[":1"].Stack)
+
+	assert.Len(t, game.SnapshotFor(":2").Players[":1"].HoleCards, 0)
+
+	assert.NotNil(t, game.ShowCards(":2", 0))
+	assert.NotNil(t, game.ShowCards(":1", 2))
+	assert.Nil(t, game.ShowCards(":1", 0))
+
+	assert.Equal(t, []deck.Card{game.players[":1"].HoleCards[0]}, game.SnapshotFor(":2").Players[":1"].HoleCards)
+}
+
+func TestPokerGameAllInShowsEveryHand(t *testing.T) {
+	game := newTestGame(t, 3)
+
+	assert.Nil(t, game.PlayerAction(":2", PlayerActionRaise, 980))
+	assert.Nil(t, game.PlayerAction(":3", PlayerActionCall, 0))
+	assert.Nil(t, game.PlayerAction(":1", PlayerActionCall, 0))
+
+	assert.False(t, game.gameStarted)
+	assert.Len(t, game.communityCards, 5)
+	for _, player := range game.players {
+		assert.Len(t, player.ShownCards, 2)
+	}
+
+	total := 0
+	for _, player := range game.players {
+		total += player.Stack
+	}
+	assert.Equal(t, 3000, total)
+}
+
+func TestPokerGameOddChipGoesLeftOfButton(t *testing.T) {
+	// Seats :1 to :4, the button is the seat at dealerPos.
+	tests := []struct {
+		dealerPos int
+		amount    int
+		winners   []string
+		stacks    map[string]int
+	}{
+		{dealerPos: 1, amount: 41, winners: []string{":1", ":3"}, stacks: map[string]int{":3": 21, ":1": 20}},
+		{dealerPos: 3, amount: 41, winners: []string{":4", ":2"}, stacks: map[string]int{":2": 21, ":4": 20}},
+		{dealerPos: 0, amount: 32, winners: []string{":1", ":2", ":3"}, stacks: map[string]int{":2": 11, ":3": 11, ":1": 10}},
+		{dealerPos: 1, amount: 32, winners: []string{":1", ":2", ":3"}, stacks: map[string]int{":3": 11, ":1": 11, ":2": 10}},
+		{dealerPos: 2, amount: 43, winners: []string{":1", ":2", ":3", ":4"}, stacks: map[string]int{":4": 11, ":1": 11, ":2": 11, ":3": 10}},
+		{dealerPos: 2, amount: 7, winners: []string{":3"}, stacks: map[string]int{":3": 7}},
+	}
+	for _, tt := range tests {
+		game := newTestGame(t, 4)
+		game.dealerPos = tt.dealerPos
+		for _, player := range game.players {
+			player.Stack = 0
+		}
+
+		assert.Nil(t, game.splitPot(0, tt.amount, tt.winners))
+		for addr, player := range game.players {
+			assert.Equal(t, tt.stacks[addr], player.Stack, "button %d, player %s", tt.dealerPos, addr)
+		}
+	}
+}
+
+func TestPokerGameAntes(t *testing.T) {
+	game := NewPokerGame(10, 20)
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, game.AddPlayer(fmt.Sprintf(":%d", i+1), 1000, i))
+	}
+	game.players[":2"].Stack = 3
+	assert.Nil(t, game.SetBlinds(10, 20, 5))
+
+	// [:1 BB] [:2 D] [:3 SB], :2 is all-in with the ante.
+	assert.Nil(t, game.StartNewHandWithSeed(42))
+	assert.NotNil(t, game.SetBlinds(20, 40, 5))
+	assert.True(t, game.players[":2"].AllIn)
+	assert.Equal(t, 0, game.players[":2"].Bet)
+	assert.Equal(t, 15, game.players[":3"].TotalBet)
+	assert.Equal(t, 20, game.currentBet)
+	assert.Equal(t, ":3", game.actionOn)
+
+	// The board is run out between :1 and the all-in :2, the chips of :3
+	// are lost to the pots.
+	assert.Nil(t, game.PlayerAction(":3", PlayerActionFold, 0))
+	assert.False(t, game.gameStarted)
+	total := 0
+	for _, player := range game.players {
+		total += player.Stack
+	}
+	assert.Equal(t, 2003, total)
+	assert.Equal(t, 985, game.players[":3"].Stack)
+}
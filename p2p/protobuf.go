@@ -0,0 +1,222 @@
+package p2p
+
+import (
+	"fmt"
+
+	pb "github.com/koshiq/ggpoker/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// toEnvelope converts a message to its protobuf definition, which is what
+// the gRPC transport sends.
+func toEnvelope(msg *Message) (*pb.Envelope, error) {
+	env := &pb.Envelope{From: msg.From, Signature: msg.Signature, Hand: int64(msg.Hand), Seq: msg.Seq}
+
+	switch v := msg.Payload.(type) {
+	case Handshake:
+		env.Payload = &pb.Envelope_Handshake{Handshake: &pb.Handshake{
+			Version:     v.Version,
+			GameVariant: pb.GameVariant(v.GameVariant),
+			GameStatus:  pb.GameStatus(v.GameStatus),
+			ListenAddr:  v.ListenAddr,
+		}}
+	case MessagePeerList:
+		env.Payload = &pb.Envelope_PeerList{PeerList: &pb.PeerList{Peers: v.Peers}}
+	case MessageEncDeck:
+		env.Payload = &pb.Envelope_EncDeck{EncDeck: &pb.EncDeck{Deck: v.Deck, Locked: v.Locked}}
+	case MessageReady:
+		env.Payload = &pb.Envelope_Ready{Ready: &pb.Ready{}}
+	case MessageSitOut:
+		env.Payload = &pb.Envelope_SitOut{SitOut: &pb.SitOut{NextBigBlind: v.NextBigBlind}}
+	case MessageSitIn:
+		env.Payload = &pb.Envelope_SitIn{SitIn: &pb.SitIn{WaitForBigBlind: v.WaitForBigBlind}}
+	case MessageChipsAdded:
+		env.Payload = &pb.Envelope_ChipsAdded{ChipsAdded: &pb.ChipsAdded{
+			Amount: int64(v.Amount),
+			Reason: string(v.Reason),
+		}}
+	case MessagePreFlop:
+		env.Payload = &pb.Envelope_PreFlop{PreFlop: &pb.PreFlop{Deck: v.Deck}}
+	case MessagePlayerAction:
+		env.Payload = &pb.Envelope_PlayerAction{PlayerAction: &pb.PlayerAction{
+			CurrentGameStatus: pb.GameStatus(v.CurrentGameStatus),
+			Action:            pb.Action(v.Action),
+			Value:             int64(v.Value),
+			TimedOut:          v.TimedOut,
+		}}
+	case MessageShowHand:
+		env.Payload = &pb.Envelope_ShowHand{ShowHand: &pb.ShowHand{Muck: v.Muck}}
+	case MessageDealer:
+		env.Payload = &pb.Envelope_Dealer{Dealer: &pb.Dealer{
+			HandNumber: int64(v.HandNumber),
+			Dealer:     v.Dealer,
+		}}
+	case MessageCardKeys:
+		env.Payload = &pb.Envelope_CardKeys{CardKeys: &pb.CardKeys{
+			HandNumber: int64(v.HandNumber),
+			Keys:       keysToProto(v.Keys),
+		}}
+	case MessagePlayerDisconnected:
+		env.Payload = &pb.Envelope_PlayerDisconnected{PlayerDisconnected: &pb.PlayerDisconnected{Addr: v.Addr}}
+	case MessageStateSync:
+		sync := &pb.StateSync{
+			Seats:      make(map[string]int32, len(v.Seats)),
+			Status:     pb.GameStatus(v.Status),
+			HandNumber: int64(v.HandNumber),
+			Keys:       keysToProto(v.Keys),
+		}
+		for addr, pos := range v.Seats {
+			sync.Seats[addr] = int32(pos)
+		}
+		for _, a := range v.Actions {
+			sync.Actions = append(sync.Actions, &pb.HandAction{
+				Addr:   a.Addr,
+				Action: pb.Action(a.Action),
+				Amount: int64(a.Amount),
+				Show:   a.Show,
+				Muck:   a.Muck,
+			})
+		}
+		env.Payload = &pb.Envelope_StateSync{StateSync: sync}
+	case MessageTimerStarted:
+		env.Payload = &pb.Envelope_TimerStarted{TimerStarted: &pb.TimerStarted{
+			Addr:     v.Addr,
+			Started:  timestamppb.New(v.Started),
+			Timeout:  durationpb.New(v.Timeout),
+			TimeBank: durationpb.New(v.TimeBank),
+		}}
+	case MessageTimerStopped:
+		env.Payload = &pb.Envelope_TimerStopped{TimerStopped: &pb.TimerStopped{
+			Addr:         v.Addr,
+			TimeBankUsed: durationpb.New(v.TimeBankUsed),
+			TimedOut:     v.TimedOut,
+		}}
+	case MessageDealProposed:
+		env.Payload = &pb.Envelope_DealProposed{DealProposed: &pb.DealProposed{Kind: string(v.Kind)}}
+	case MessageDealAnswer:
+		env.Payload = &pb.Envelope_DealAnswer{DealAnswer: &pb.DealAnswer{Accept: v.Accept}}
+	default:
+		return nil, fmt.Errorf("no protobuf definition for message %T", msg.Payload)
+	}
+
+	return env, nil
+}
+
+// fromEnvelope converts a message received from the gRPC transport back.
+func fromEnvelope(env *pb.Envelope) (*Message, error) {
+	var payload any
+
+	switch p := env.Payload.(type) {
+	case *pb.Envelope_Handshake:
+		payload = Handshake{
+			Version:     p.Handshake.GetVersion(),
+			GameVariant: GameVariant(p.Handshake.GetGameVariant()),
+			GameStatus:  GameStatus(p.Handshake.GetGameStatus()),
+			ListenAddr:  p.Handshake.GetListenAddr(),
+		}
+	case *pb.Envelope_PeerList:
+		payload = MessagePeerList{Peers: p.PeerList.GetPeers()}
+	case *pb.Envelope_EncDeck:
+		payload = MessageEncDeck{Deck: p.EncDeck.GetDeck(), Locked: p.EncDeck.GetLocked()}
+	case *pb.Envelope_Ready:
+		payload = MessageReady{}
+	case *pb.Envelope_SitOut:
+		payload = MessageSitOut{NextBigBlind: p.SitOut.GetNextBigBlind()}
+	case *pb.Envelope_SitIn:
+		payload = MessageSitIn{WaitForBigBlind: p.SitIn.GetWaitForBigBlind()}
+	case *pb.Envelope_ChipsAdded:
+		payload = MessageChipsAdded{
+			Amount: int(p.ChipsAdded.GetAmount()),
+			Reason: LedgerReason(p.ChipsAdded.GetReason()),
+		}
+	case *pb.Envelope_PreFlop:
+		payload = MessagePreFlop{Deck: p.PreFlop.GetDeck()}
+	case *pb.Envelope_PlayerAction:
+		payload = MessagePlayerAction{
+			CurrentGameStatus: GameStatus(p.PlayerAction.GetCurrentGameStatus()),
+			Action:            PlayerAction(p.PlayerAction.GetAction()),
+			Value:             int(p.PlayerAction.GetValue()),
+			TimedOut:          p.PlayerAction.GetTimedOut(),
+		}
+	case *pb.Envelope_ShowHand:
+		payload = MessageShowHand{Muck: p.ShowHand.GetMuck()}
+	case *pb.Envelope_Dealer:
+		payload = MessageDealer{
+			HandNumber: int(p.Dealer.GetHandNumber()),
+			Dealer:     p.Dealer.GetDealer(),
+		}
+	case *pb.Envelope_CardKeys:
+		payload = MessageCardKeys{
+			HandNumber: int(p.CardKeys.GetHandNumber()),
+			Keys:       keysFromProto(p.CardKeys.GetKeys()),
+		}
+	case *pb.Envelope_PlayerDisconnected:
+		payload = MessagePlayerDisconnected{Addr: p.PlayerDisconnected.GetAddr()}
+	case *pb.Envelope_StateSync:
+		sync := MessageStateSync{
+			Seats:      make(map[string]int, len(p.StateSync.GetSeats())),
+			Status:     GameStatus(p.StateSync.GetStatus()),
+			HandNumber: int(p.StateSync.GetHandNumber()),
+			Actions:    []HandAction{},
+			Keys:       keysFromProto(p.StateSync.GetKeys()),
+		}
+		for addr, pos := range p.StateSync.GetSeats() {
+			sync.Seats[addr] = int(pos)
+		}
+		for _, a := range p.StateSync.GetActions() {
+			sync.Actions = append(sync.Actions, HandAction{
+				Addr:   a.GetAddr(),
+				Action: PlayerAction(a.GetAction()),
+				Amount: int(a.GetAmount()),
+				Show:   a.GetShow(),
+				Muck:   a.GetMuck(),
+			})
+		}
+		payload = sync
+	case *pb.Envelope_TimerStarted:
+		payload = MessageTimerStarted{
+			Addr:     p.TimerStarted.GetAddr(),
+			Started:  p.TimerStarted.GetStarted().AsTime(),
+			Timeout:  p.TimerStarted.GetTimeout().AsDuration(),
+			TimeBank: p.TimerStarted.GetTimeBank().AsDuration(),
+		}
+	case *pb.Envelope_TimerStopped:
+		payload = MessageTimerStopped{
+			Addr:         p.TimerStopped.GetAddr(),
+			TimeBankUsed: p.TimerStopped.GetTimeBankUsed().AsDuration(),
+			TimedOut:     p.TimerStopped.GetTimedOut(),
+		}
+	case *pb.Envelope_DealProposed:
+		payload = MessageDealProposed{Kind: DealKind(p.DealProposed.GetKind())}
+	case *pb.Envelope_DealAnswer:
+		payload = MessageDealAnswer{Accept: p.DealAnswer.GetAccept()}
+	default:
+		return nil, fmt.Errorf("unknown protobuf message %T", env.Payload)
+	}
+
+	msg := NewMessage(env.From, payload)
+	msg.Hand = int(env.Hand)
+	msg.Seq = env.Seq
+	if len(env.Signature) > 0 {
+		msg.Signature = env.Signature
+	}
+
+	return msg, nil
+}
+
+func keysToProto(keys map[int][]byte) map[int32][]byte {
+	out := make(map[int32][]byte, len(keys))
+	for pos, key := range keys {
+		out[int32(pos)] = key
+	}
+	return out
+}
+
+func keysFromProto(keys map[int32][]byte) map[int][]byte {
+	out := make(map[int][]byte, len(keys))
+	for pos, key := range keys {
+		out[int(pos)] = key
+	}
+	return out
+}
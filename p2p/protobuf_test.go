@@ -0,0 +1,99 @@
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/koshiq/ggpoker/proto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestProtobufRoundTrip(t *testing.T) {
+	msgs := []*Message{
+		NewMessage(":3000", Handshake{Version: "v1", GameVariant: Other, GameStatus: GameStatusFlop, ListenAddr: ":3000"}),
+		NewMessage(":3000", MessagePeerList{Peers: []string{":4000", ":5000"}}),
+		NewMessage(":3000", MessageEncDeck{Deck: [][]byte{{1}, {2, 3}}, Locked: true}),
+		NewMessage(":3000", MessageReady{}),
+		NewMessage(":3000", MessageSitOut{NextBigBlind: true}),
+		NewMessage(":3000", MessageSitIn{WaitForBigBlind: true}),
+		NewMessage(":3000", MessageChipsAdded{Amount: 500, Reason: LedgerTopUp}),
+		NewMessage(":3000", MessagePreFlop{Deck: [][]byte{{4}, {5}}}),
+		NewMessage(":3000", MessagePlayerAction{CurrentGameStatus: GameStatusTurn, Action: PlayerActionRaise, Value: 40, TimedOut: true}),
+		NewMessage(":3000", MessageShowHand{Muck: true}),
+		NewMessage(":3000", MessageDealer{HandNumber: 3, Dealer: ":4000"}),
+		NewMessage(":3000", MessageCardKeys{HandNumber: 3, Keys: map[int][]byte{7: {1, 2, 3}}}),
+		NewMessage(":3000", MessagePlayerDisconnected{Addr: ":5000"}),
+		NewMessage(":3000", MessageStateSync{
+			Seats:      map[string]int{":3000": 0, ":4000": 1},
+			Status:     GameStatusRiver,
+			HandNumber: 3,
+			Actions: []HandAction{
+				{Addr: ":3000", Action: PlayerActionBet, Amount: 20},
+				{Addr: ":4000", Show: true},
+			},
+			Keys: map[int][]byte{0: {9}},
+		}),
+		NewMessage(":3000", MessageTimerStarted{
+			Addr:     ":4000",
+			Started:  time.Date(2024, 5, 1, 12, 0, 0, 500, time.UTC),
+			Timeout:  30 * time.Second,
+			TimeBank: time.Minute,
+		}),
+		{From: ":3000", Payload: MessageTimerStopped{Addr: ":4000", TimeBankUsed: time.Second, TimedOut: true}, Hand: 2, Seq: 7},
+		NewMessage(":3000", MessageDealProposed{Kind: DealICM}),
+		NewMessage(":3000", MessageDealAnswer{Accept: true}),
+	}
+	assert.Len(t, msgs, len(messageTags))
+
+	for _, msg := range msgs {
+		env, err := toEnvelope(msg)
+		assert.Nil(t, err)
+		b, err := proto.Marshal(env)
+		assert.Nil(t, err)
+
+		got := new(pb.Envelope)
+		assert.Nil(t, proto.Unmarshal(b, got))
+		decoded, err := fromEnvelope(got)
+		assert.Nil(t, err)
+		assert.Equal(t, msg, decoded)
+	}
+
+	_, err := toEnvelope(NewMessage(":3000", "not a message"))
+	assert.NotNil(t, err)
+	_, err = fromEnvelope(&pb.Envelope{From: ":3000"})
+	assert.NotNil(t, err)
+}
+
+func TestGRPCTransport(t *testing.T) {
+	addr := freeAddr(t)
+	tr := NewGRPCTransport(addr, nil)
+	addPeer := make(chan *Peer, 1)
+	assert.Nil(t, tr.Listen(addPeer))
+	defer tr.Close()
+
+	conn, err := tr.Dial(addr)
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	hs := NewMessage(":3000", Handshake{Version: "v1", ListenAddr: ":3000"})
+	assert.Nil(t, conn.Send(hs))
+
+	peer := <-addPeer
+	msg, err := peer.conn.Receive()
+	assert.Nil(t, err)
+	assert.Equal(t, hs, msg)
+	assert.False(t, conn.(authenticatedConn).Authenticated())
+	assert.False(t, peer.conn.(authenticatedConn).Authenticated())
+
+	reply := NewMessage(":4000", MessagePeerList{Peers: []string{":5000"}})
+	assert.Nil(t, peer.Send(reply))
+	msg, err = conn.Receive()
+	assert.Nil(t, err)
+	assert.Equal(t, reply, msg)
+
+	// Closing the peer ends the stream for the other side.
+	peer.conn.Close()
+	_, err = conn.Receive()
+	assert.NotNil(t, err)
+}
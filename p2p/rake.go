@@ -0,0 +1,134 @@
+package p2p
+
+import (
+	"fmt"
+	"sort"
+)
+
+// RakeConfig describes the rake taken from the pots of a table. The rake
+// is BasisPoints/10000 of the pot, taken for every full Increment of chips
+// only, e.g. 500 basis points with an increment of 20 take 1 chip per 20.
+type RakeConfig struct {
+	BasisPoints int `json:"basisPoints"`
+	Increment   int `json:"increment"`
+	// PotCap is the maximum rake of a single pot, zero means no cap.
+	PotCap int `json:"potCap,omitempty"`
+	// Caps is the maximum rake of a hand by the number of players dealt
+	// in. The cap of the largest table size not above the number of
+	// players applies.
+	Caps map[int]int `json:"caps,omitempty"`
+	// NoFlopNoDrop takes no rake from hands that end before the flop.
+	NoFlopNoDrop bool `json:"noFlopNoDrop"`
+}
+
+// HandRake is the rake taken in a single hand.
+type HandRake struct {
+	HandNumber int   `json:"handNumber"`
+	Pots       []int `json:"pots"`
+	Total      int   `json:"total"`
+}
+
+// SetRake sets the rake of the table, it applies from the next pot on.
+func (pg *PokerGame) SetRake(cfg RakeConfig) error {
+	if cfg.BasisPoints < 0 || cfg.BasisPoints > 10000 {
+		return fmt.Errorf("invalid rake of %d basis points", cfg.BasisPoints)
+	}
+	if cfg.BasisPoints > 0 && cfg.Increment <= 0 {
+		return fmt.Errorf("invalid rake increment %d", cfg.Increment)
+	}
+
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	pg.rake = cfg
+
+	return nil
+}
+
+// handCap returns the rake cap of a hand with n players dealt in.
+func (cfg RakeConfig) handCap(n int) int {
+	sizes := make([]int, 0, len(cfg.Caps))
+	for size := range cfg.Caps {
+		sizes = append(sizes, size)
+	}
+	sort.Ints(sizes)
+
+	hcap := 0
+	for _, size := range sizes {
+		if size <= n {
+			hcap = cfg.Caps[size]
+		}
+	}
+
+	return hcap
+}
+
+// takeRake takes the rake from the pots of the hand before they are paid
+// out. Uncalled bets are returned in full and never raked.
+func (pg *PokerGame) takeRake() error {
+	cfg := pg.rake
+	if cfg.BasisPoints == 0 || (cfg.NoFlopNoDrop && len(pg.communityCards) == 0) {
+		return nil
+	}
+
+	_, uncalled := pg.uncalledBet()
+	left := cfg.handCap(len(pg.seatOrder()))
+
+	for i, pot := range pg.pot {
+		amount := pot.Amount
+		if i == len(pg.pot)-1 {
+			amount -= uncalled
+		}
+
+		rake := amount / cfg.Increment * cfg.Increment * cfg.BasisPoints / 10000
+		if cfg.PotCap > 0 {
+			rake = min(rake, cfg.PotCap)
+		}
+		if len(cfg.Caps) > 0 {
+			rake = min(rake, left)
+			left -= rake
+		}
+		if rake <= 0 {
+			continue
+		}
+
+		if err := pg.record(EventRakeTaken{Pot: i, Amount: rake}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RakeReport returns the rake taken in every hand that was raked.
+func (pg *PokerGame) RakeReport() []HandRake {
+	pg.mu.RLock()
+	defer pg.mu.RUnlock()
+
+	report := []HandRake{}
+	for _, r := range pg.events {
+		e, ok := r.Event.(EventRakeTaken)
+		if !ok {
+			continue
+		}
+		if n := len(report); n == 0 || report[n-1].HandNumber != r.HandNumber {
+			report = append(report, HandRake{HandNumber: r.HandNumber, Pots: []int{}})
+		}
+		hand := &report[len(report)-1]
+		for len(hand.Pots) <= e.Pot {
+			hand.Pots = append(hand.Pots, 0)
+		}
+		hand.Pots[e.Pot] += e.Amount
+		hand.Total += e.Amount
+	}
+
+	return report
+}
+
+// rakeOf returns the rake taken from the given pot of the current hand.
+func (pg *PokerGame) rakeOf(pot int) int {
+	if pot < len(pg.potRake) {
+		return pg.potRake[pot]
+	}
+	return 0
+}
@@ -0,0 +1,95 @@
+package p2p
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newRakedGame(t *testing.T, cfg RakeConfig) *PokerGame {
+	game := NewPokerGame(10, 20)
+	assert.Nil(t, game.SetRake(cfg))
+	assert.Nil(t, game.AddPlayer(":1", 1000, 0))
+	assert.Nil(t, game.AddPlayer(":2", 500, 1))
+	assert.Nil(t, game.AddPlayer(":3", 1500, 2))
+	assert.Nil(t, game.StartNewHandWithSeed(1234))
+
+	return game
+}
+
+func TestRakeAtShowdown(t *testing.T) {
+	// [:1 BB] [:2 D] [:3 SB]
+	game := newRakedGame(t, RakeConfig{BasisPoints: 500, Increment: 20, NoFlopNoDrop: true})
+	assert.Nil(t, game.PlayerAction(":2", PlayerActionRaise, 40))
+	assert.Nil(t, game.PlayerAction(":3", PlayerActionCall, 0))
+	assert.Nil(t, game.PlayerAction(":1", PlayerActionCall, 0))
+	assert.Nil(t, game.PlayerAction(":3", PlayerActionBet, 100))
+	assert.Nil(t, game.PlayerAction(":1", PlayerActionFold, 0))
+	assert.Nil(t, game.PlayerAction(":2", PlayerActionCall, 0))
+	for game.gameStarted && game.currentRound != Showdown {
+		assert.Nil(t, game.PlayerAction(game.actionOn, PlayerActionCheck, 0))
+	}
+	for game.gameStarted {
+		assert.Nil(t, game.ShowHand(game.actionOn))
+	}
+
+	// 5% of 380 taken per full 20 chips.
+	assert.Equal(t, []HandRake{{HandNumber: 1, Pots: []int{19}, Total: 19}}, game.RakeReport())
+	assert.Equal(t, 19, game.SnapshotFor("").Rake)
+	stacks := 0
+	for _, player := range game.players {
+		stacks += player.Stack
+	}
+	assert.Equal(t, 3000-19, stacks)
+
+	text, err := game.HandHistory("ggpoker", 1, ":1")
+	assert.Nil(t, err)
+	assert.Contains(t, text, "Total pot 380 | Rake 19\n")
+
+	rec, err := game.HandRecord(1)
+	assert.Nil(t, err)
+	assert.Equal(t, 500, rec.Rake.BasisPoints)
+	result, err := ReplayHand(rec)
+	assert.Nil(t, err)
+	assert.False(t, result.Diverged(), result.Divergences)
+
+	h, err := game.OpenHandHistory("ggpoker", 1, ":1")
+	assert.Nil(t, err)
+	assert.Equal(t, 380.0, h.OHH.Pots[0].Amount)
+	assert.Equal(t, 19.0, h.OHH.Pots[0].Rake)
+	result, err = ReplayOpenHandHistory(h)
+	assert.Nil(t, err)
+	assert.False(t, result.Diverged(), result.Divergences)
+}
+
+func TestRakeNoFlopNoDropAndCaps(t *testing.T) {
+	game := newRakedGame(t, RakeConfig{BasisPoints: 500, Increment: 20, NoFlopNoDrop: true})
+	assert.Nil(t, game.PlayerAction(":2", PlayerActionRaise, 40))
+	assert.Nil(t, game.PlayerAction(":3", PlayerActionFold, 0))
+	assert.Nil(t, game.PlayerAction(":1", PlayerActionFold, 0))
+	assert.Empty(t, game.RakeReport())
+
+	for _, tc := range []struct {
+		cfg  RakeConfig
+		rake int
+	}{
+		// The uncalled bet of 100 is not raked, 5% of 140 is 7.
+		{RakeConfig{BasisPoints: 500, Increment: 20}, 7},
+		{RakeConfig{BasisPoints: 500, Increment: 20, PotCap: 3}, 3},
+		{RakeConfig{BasisPoints: 500, Increment: 20, Caps: map[int]int{2: 2, 3: 5, 6: 6}}, 5},
+		{RakeConfig{BasisPoints: 500, Increment: 100}, 5},
+	} {
+		// [:1 BB] [:2 D] [:3 SB]
+		game := newRakedGame(t, tc.cfg)
+		assert.Nil(t, game.PlayerAction(":2", PlayerActionRaise, 40))
+		assert.Nil(t, game.PlayerAction(":3", PlayerActionCall, 0))
+		assert.Nil(t, game.PlayerAction(":1", PlayerActionFold, 0))
+		assert.Nil(t, game.PlayerAction(":3", PlayerActionBet, 100))
+		assert.Nil(t, game.PlayerAction(":2", PlayerActionFold, 0))
+
+		assert.Equal(t, []HandRake{{HandNumber: 1, Pots: []int{tc.rake}, Total: tc.rake}}, game.RakeReport())
+		assert.Equal(t, 1500-160+240-tc.rake, game.players[":3"].Stack)
+	}
+
+	assert.NotNil(t, game.SetRake(RakeConfig{BasisPoints: 500}))
+}
@@ -0,0 +1,330 @@
+package p2p
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// HandAction is an action of a player in a hand. Players that reconnect
+// replay the actions they missed.
+type HandAction struct {
+	Addr   string
+	Action PlayerAction
+	Amount int
+	// Show and Muck are set for the hands shown and mucked at showdown.
+	Show bool
+	Muck bool
+}
+
+// HandActions returns the number of the current or last hand and the
+// actions of the players in it. Hands turned up by the game itself when
+// players are all-in are left out.
+func (pg *PokerGame) HandActions() (int, []HandAction) {
+	pg.mu.RLock()
+	defer pg.mu.RUnlock()
+
+	actions := []HandAction{}
+	allIn := false
+	for _, r := range pg.events {
+		if r.HandNumber != pg.handNumber {
+			continue
+		}
+		switch e := r.Event.(type) {
+		case EventActionTaken:
+			actions = append(actions, HandAction{Addr: e.Addr, Action: e.Action, Amount: e.Amount})
+		case EventShowdownStarted:
+			allIn = e.AllIn
+		case EventCardsShown:
+			if !allIn {
+				actions = append(actions, HandAction{Addr: e.Addr, Show: true})
+			}
+		case EventHandMucked:
+			actions = append(actions, HandAction{Addr: e.Addr, Muck: true})
+		}
+	}
+
+	return pg.handNumber, actions
+}
+
+// SetReconnectWindow sets how long players that lost the connection keep
+// their seat. A zero window removes them right away.
+func (g *GameState) SetReconnectWindow(d time.Duration) {
+	g.awayLock.Lock()
+	defer g.awayLock.Unlock()
+
+	g.reconnectWindow = d
+}
+
+// PlayerDisconnected is getting called when the connection to a player in
+// the network is lost. The player keeps the seat during the reconnect
+// window and is removed once it is over.
+func (g *GameState) PlayerDisconnected(addr string) {
+	g.awayLock.Lock()
+	window := g.reconnectWindow
+	_, away := g.away[addr]
+	if window > 0 && !away {
+		var timer *time.Timer
+		timer = time.AfterFunc(window, func() {
+			g.awayLock.Lock()
+			expired := g.away[addr] == timer
+			g.awayLock.Unlock()
+
+			if expired {
+				g.RemovePlayer(addr)
+			}
+		})
+		g.away[addr] = timer
+	}
+	g.awayLock.Unlock()
+
+	if window <= 0 {
+		g.RemovePlayer(addr)
+		return
+	}
+	if away {
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"we":     g.listenAddr,
+		"player": addr,
+		"window": window,
+	}).Info("player lost the connection")
+}
+
+// Reconnect is getting called when a player that lost the connection is
+// back within the reconnect window. We send the player our view of the
+// table to catch up. It reports false for players that were not away.
+func (g *GameState) Reconnect(addr string) bool {
+	if !g.stopAway(addr) {
+		return false
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"we":     g.listenAddr,
+		"player": addr,
+	}).Info("player reconnected")
+
+	g.sendToPlayers(g.stateSync(addr), addr)
+
+	if GameStatus(g.currentStatus.Get()) == GameStatusPlayerReady {
+		if _, isDealer := g.getCurrentDealerAddr(); isDealer {
+			g.dealLater()
+		}
+	}
+
+	return true
+}
+
+// stopAway ends the reconnect window of the player. It reports false when
+// the player was not away.
+func (g *GameState) stopAway(addr string) bool {
+	g.awayLock.Lock()
+	defer g.awayLock.Unlock()
+
+	timer, ok := g.away[addr]
+	if !ok {
+		return false
+	}
+	timer.Stop()
+	delete(g.away, addr)
+
+	return true
+}
+
+// isAway reports whether the player is in the reconnect window.
+func (g *GameState) isAway(addr string) bool {
+	g.awayLock.Lock()
+	defer g.awayLock.Unlock()
+
+	_, ok := g.away[addr]
+	return ok
+}
+
+// isAnyoneAway reports whether a player is in the reconnect window. No hand
+// can be dealt until everyone is back or removed.
+func (g *GameState) isAnyoneAway() bool {
+	g.awayLock.Lock()
+	defer g.awayLock.Unlock()
+
+	return len(g.away) > 0
+}
+
+// stateSync returns our view of the table for a player that reconnected,
+// with the keys we revealed to the player before.
+func (g *GameState) stateSync(addr string) MessageStateSync {
+	hand, actions := g.game.HandActions()
+	msg := MessageStateSync{
+		Seats:      make(map[string]int),
+		Status:     GameStatus(g.currentStatus.Get()),
+		HandNumber: hand,
+		Actions:    actions,
+	}
+	for _, player := range g.table.Players() {
+		msg.Seats[player.addr] = player.tablePos
+	}
+	if md := g.mentalDeck(); md != nil && md.handNumber == hand {
+		msg.Keys = md.sentTo(addr)
+	}
+
+	return msg
+}
+
+// handleStateSync is getting called when a player in the network sends us
+// their view of the table after we reconnected. The seats have to agree with
+// ours, we only take the seat of the sending player when we don't know it.
+// We take the keys and replay the actions we missed, see catchUp.
+func (g *GameState) handleStateSync(from string, msg MessageStateSync) error {
+	if err := g.checkSeats(from, msg.Seats); err != nil {
+		return err
+	}
+	if pos, ok := msg.Seats[from]; ok {
+		if _, err := g.table.GetPlayer(from); err != nil {
+			if g.playersList.getIndex(from) == -1 {
+				g.playersList.add(from)
+			}
+			g.table.AddPlayerOnPosition(from, pos)
+			g.seatPlayer(from, pos)
+		}
+	}
+
+	hand, actions := g.game.HandActions()
+	if hand != msg.HandNumber {
+		return fmt.Errorf("player (%s) is at hand %d, we are at hand %d", from, msg.HandNumber, hand)
+	}
+
+	if md := g.mentalDeck(); md != nil && md.handNumber == hand {
+		if len(msg.Keys) > 0 {
+			if err := g.handleCardKeys(from, MessageCardKeys{HandNumber: hand, Keys: msg.Keys}); err != nil {
+				return err
+			}
+		}
+		// The keys we revealed to the player may have been lost as well.
+		if keys := md.sentTo(from); len(keys) > 0 {
+			g.sendToPlayers(MessageCardKeys{HandNumber: hand, Keys: keys}, from)
+		}
+	}
+
+	for i, action := range actions {
+		if i >= len(msg.Actions) {
+			break
+		}
+		if action != msg.Actions[i] {
+			return fmt.Errorf("player (%s) has action %d of hand %d as %+v, we have %+v", from, i, hand, msg.Actions[i], action)
+		}
+	}
+
+	g.syncLock.Lock()
+	defer g.syncLock.Unlock()
+
+	if g.syncHand != hand {
+		g.syncs = make(map[string]MessageStateSync)
+		g.syncHand = hand
+	}
+	g.syncs[from] = msg
+
+	return g.catchUp(from)
+}
+
+// checkSeats makes sure the seats of a state sync agree with our table. A
+// player we seat has to be at the same position, and the only player we
+// don't know may be the sending player on a free seat.
+func (g *GameState) checkSeats(from string, seats map[string]int) error {
+	taken := make(map[int]string)
+	for _, player := range g.table.Players() {
+		taken[player.tablePos] = player.addr
+		if player.addr == g.listenAddr {
+			continue
+		}
+		pos, ok := seats[player.addr]
+		if !ok {
+			return fmt.Errorf("player (%s) does not seat player (%s)", from, player.addr)
+		}
+		if pos != player.tablePos {
+			return fmt.Errorf("player (%s) seats player (%s) at %d, we have %d", from, player.addr, pos, player.tablePos)
+		}
+	}
+
+	for addr, pos := range seats {
+		if _, err := g.table.GetPlayer(addr); err == nil || addr == g.listenAddr {
+			continue
+		}
+		if addr != from {
+			return fmt.Errorf("player (%s) seats player (%s) we don't know", from, addr)
+		}
+		if other, ok := taken[pos]; ok {
+			return fmt.Errorf("player (%s) takes seat %d of player (%s)", from, pos, other)
+		}
+	}
+
+	return nil
+}
+
+// catchUp replays the actions we missed in the hand. A sync is only signed
+// by the player that sent it, so we take an action once the player that took
+// it sent it in their own sync. Until then we wait for that sync. The caller
+// holds syncLock.
+func (g *GameState) catchUp(from string) error {
+	replayed := false
+	for {
+		_, actions := g.game.HandActions()
+		action, ok := g.confirmedAction(actions)
+		if !ok {
+			break
+		}
+		if err := g.replayAction(action); err != nil {
+			return err
+		}
+		replayed = true
+	}
+	if !replayed {
+		return nil
+	}
+	g.syncStatus()
+
+	if status := GameStatus(g.currentStatus.Get()); status != g.syncs[from].Status {
+		logrus.WithFields(logrus.Fields{
+			"we":     g.listenAddr,
+			"from":   from,
+			"status": status,
+			"theirs": g.syncs[from].Status,
+		}).Warn("status differs after catching up")
+	}
+
+	return nil
+}
+
+// confirmedAction returns the action that follows the given actions in the
+// sync of the player that took it.
+func (g *GameState) confirmedAction(actions []HandAction) (HandAction, bool) {
+	next := len(actions)
+	for addr, sync := range g.syncs {
+		if len(sync.Actions) <= next || sync.Actions[next].Addr != addr {
+			continue
+		}
+		agrees := true
+		for i, action := range actions {
+			if sync.Actions[i] != action {
+				agrees = false
+				break
+			}
+		}
+		if agrees {
+			return sync.Actions[next], true
+		}
+	}
+
+	return HandAction{}, false
+}
+
+func (g *GameState) replayAction(action HandAction) error {
+	switch {
+	case action.Show:
+		return g.game.ShowHand(action.Addr)
+	case action.Muck:
+		return g.game.MuckHand(action.Addr)
+	}
+	return g.game.PlayerAction(action.Addr, action.Action, action.Amount)
+}
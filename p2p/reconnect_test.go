@@ -0,0 +1,145 @@
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// missAction sets up a hand in which a player that is not on the move loses
+// the connection and misses an action. It returns the player and the player
+// that took the action.
+func missAction(t *testing.T, network testNetwork) (*GameState, string, string) {
+	for _, addr := range []string{":3000", ":4000", ":5000"} {
+		network[addr].SetReconnectWindow(time.Minute)
+		network[addr].SetReady()
+		network.deliver(t)
+	}
+	network[":4000"].InitiateShuffleAndDeal()
+	network.deliver(t)
+
+	_, _, actionOn := network[":3000"].game.handStatus()
+	lost := ":3000"
+	if actionOn == lost {
+		lost = ":4000"
+	}
+	away := network[lost]
+	delete(network, lost)
+	for addr, g := range network {
+		g.PlayerDisconnected(lost)
+		away.PlayerDisconnected(addr)
+	}
+
+	assert.Nil(t, network[actionOn].TakeAction(PlayerActionCall, 0))
+	network.deliver(t)
+
+	return away, lost, actionOn
+}
+
+func TestGameStateCatchesUpAfterReconnect(t *testing.T) {
+	network := newTestNetwork(t, ":3000", ":4000", ":5000")
+	away, lost, actionOn := missAction(t, network)
+	_, missed := network[actionOn].game.HandActions()
+	_, actions := away.game.HandActions()
+	assert.Len(t, actions, len(missed)-1)
+
+	// Both sides send their view of the table and the player catches up.
+	network[lost] = away
+	for addr, g := range network {
+		if addr != lost {
+			assert.True(t, g.Reconnect(lost))
+			assert.True(t, away.Reconnect(addr))
+		}
+	}
+	network.deliver(t)
+	_, actions = away.game.HandActions()
+	assert.Equal(t, missed, actions)
+	assert.False(t, away.isAnyoneAway())
+
+	// The player keeps playing the hand.
+	for {
+		started, _, _ := network[":3000"].game.handStatus()
+		if !started {
+			break
+		}
+		for addr, g := range network {
+			actions := g.game.LegalActions(addr)
+			if len(actions) == 0 {
+				continue
+			}
+			switch {
+			case actions[0].Action == "SHOW":
+				assert.Nil(t, g.ShowHand(false))
+			case actions[1].Action == PlayerActionCall.String():
+				assert.Nil(t, g.TakeAction(PlayerActionCall, 0))
+			default:
+				assert.Nil(t, g.TakeAction(PlayerActionCheck, 0))
+			}
+			network.deliver(t)
+			break
+		}
+	}
+
+	stacks := map[string]int{}
+	for addr, player := range away.game.players {
+		stacks[addr] = player.Stack
+	}
+	for _, g := range network {
+		assert.Equal(t, GameStatusPlayerReady, GameStatus(g.currentStatus.Get()))
+		for addr, player := range g.game.players {
+			assert.Equal(t, stacks[addr], player.Stack)
+			for _, card := range player.HoleCards {
+				assert.False(t, card.IsFaceDown())
+			}
+		}
+	}
+}
+
+func TestStateSyncTakesActionsOfTheSenderOnly(t *testing.T) {
+	network := newTestNetwork(t, ":3000", ":4000", ":5000")
+	away, lost, actionOn := missAction(t, network)
+	other := ""
+	for addr := range network {
+		if addr != actionOn {
+			other = addr
+		}
+	}
+	_, before := away.game.HandActions()
+
+	// A player can't act for another player.
+	forged := network[other].stateSync(lost)
+	forged.Actions[len(forged.Actions)-1].Action = PlayerActionFold
+	assert.Nil(t, away.handleStateSync(other, forged))
+	_, actions := away.game.HandActions()
+	assert.Equal(t, before, actions)
+
+	// The player that took the action confirms it.
+	assert.Nil(t, away.handleStateSync(actionOn, network[actionOn].stateSync(lost)))
+	_, missed := network[actionOn].game.HandActions()
+	_, actions = away.game.HandActions()
+	assert.Equal(t, missed, actions)
+}
+
+func TestStateSyncRejectsConflictingSeats(t *testing.T) {
+	network := newTestNetwork(t, ":3000", ":4000", ":5000")
+	away, lost, actionOn := missAction(t, network)
+	_, before := away.game.HandActions()
+
+	moved := network[actionOn].stateSync(lost)
+	moved.Seats[actionOn] = 5
+	assert.NotNil(t, away.handleStateSync(actionOn, moved))
+
+	missing := network[actionOn].stateSync(lost)
+	delete(missing.Seats, actionOn)
+	assert.NotNil(t, away.handleStateSync(actionOn, missing))
+
+	unknown := network[actionOn].stateSync(lost)
+	unknown.Seats[":6000"] = 5
+	assert.NotNil(t, away.handleStateSync(actionOn, unknown))
+
+	_, actions := away.game.HandActions()
+	assert.Equal(t, before, actions)
+	_, err := away.table.GetPlayer(":6000")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,327 @@
+package p2p
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// HandRecord holds everything needed to play a single hand again: the
+// seed of the deck (or the deck itself), the seats with their stacks at the start of the hand
+// and the ordered list of actions. Result is what the hand originally
+// resulted in and is used to detect divergences.
+type HandRecord struct {
+	HandNumber int            `json:"handNumber"`
+	SmallBlind int            `json:"smallBlind"`
+	BigBlind   int            `json:"bigBlind"`
+	Ante       int            `json:"ante,omitempty"`
+	Seed       int64          `json:"seed"`
+	Deck       []string       `json:"deck,omitempty"`
+	Rake       *RakeConfig    `json:"rake,omitempty"`
+	Button     string         `json:"button"`
+	Seats      []SeatRecord   `json:"seats"`
+	Actions    []ActionRecord `json:"actions"`
+	Result     *HandResult    `json:"result,omitempty"`
+}
+
+type SeatRecord struct {
+	Addr     string `json:"addr"`
+	Position int    `json:"position"`
+	Stack    int    `json:"stack"`
+	// PostsBigBlind is set for a player returning from sitting out that
+	// posts a big blind out of position.
+	PostsBigBlind bool `json:"postsBigBlind,omitempty"`
+}
+
+// ActionRecord is a single action of a player. Besides the betting
+// actions SHOW and MUCK are used for the showdown.
+type ActionRecord struct {
+	Addr   string `json:"addr"`
+	Action string `json:"action"`
+	Amount int    `json:"amount,omitempty"`
+}
+
+type HandResult struct {
+	Stacks map[string]int `json:"stacks"`
+	Awards []PotAward     `json:"awards"`
+	Pots   []int          `json:"pots,omitempty"`
+}
+
+type PotAward struct {
+	Pot    int    `json:"pot"`
+	Addr   string `json:"addr"`
+	Amount int    `json:"amount"`
+}
+
+type ReplayStep struct {
+	Step   int           `json:"step"`
+	Action *ActionRecord `json:"action,omitempty"`
+	State  GameSnapshot  `json:"state"`
+}
+
+type ReplayResult struct {
+	Steps       []ReplayStep `json:"steps"`
+	Divergences []string     `json:"divergences"`
+}
+
+func (r *ReplayResult) Diverged() bool {
+	return len(r.Divergences) > 0
+}
+
+const (
+	actionShow = "SHOW"
+	actionMuck = "MUCK"
+)
+
+// HandRecord extracts the record of the given hand from the event log.
+func (pg *PokerGame) HandRecord(handNumber int) (*HandRecord, error) {
+	pg.mu.RLock()
+	defer pg.mu.RUnlock()
+
+	var (
+		tmp     = NewPokerGame(pg.smallBlind, pg.bigBlind)
+		rec     *HandRecord
+		allIn   bool
+		awards  = []PotAward{}
+		started bool
+	)
+
+	for _, r := range pg.events {
+		if ev, ok := r.Event.(EventHandStarted); ok && ev.HandNumber == handNumber {
+			started = true
+			smallBlind, bigBlind, ante := pg.smallBlind, pg.bigBlind, pg.ante
+			if ev.BigBlind > 0 {
+				smallBlind, bigBlind, ante = ev.SmallBlind, ev.BigBlind, ev.Ante
+			}
+			rec = &HandRecord{
+				HandNumber: handNumber,
+				SmallBlind: smallBlind,
+				BigBlind:   bigBlind,
+				Ante:       ante,
+				Seed:       ev.Seed,
+				Deck:       shortCards(ev.Deck),
+				Seats:      []SeatRecord{},
+				Actions:    []ActionRecord{},
+			}
+			if pg.rake.BasisPoints > 0 {
+				rake := pg.rake
+				rec.Rake = &rake
+			}
+			playerAddrs := tmp.seatOrder()
+			rec.Button = playerAddrs[ev.DealerPos]
+			for _, addr := range playerAddrs {
+				player := tmp.players[addr]
+				rec.Seats = append(rec.Seats, SeatRecord{Addr: addr, Position: player.Position, Stack: player.Stack})
+			}
+		}
+
+		if started {
+			switch ev := r.Event.(type) {
+			case EventBlindPosted:
+				for i := range rec.Seats {
+					if ev.Extra && rec.Seats[i].Addr == ev.Addr {
+						rec.Seats[i].PostsBigBlind = true
+					}
+				}
+			case EventActionTaken:
+				rec.Actions = append(rec.Actions, ActionRecord{Addr: ev.Addr, Action: ev.Action.String(), Amount: ev.Amount})
+			case EventShowdownStarted:
+				allIn = ev.AllIn
+			case EventCardsShown:
+				if !allIn && tmp.gameStarted {
+					rec.Actions = append(rec.Actions, ActionRecord{Addr: ev.Addr, Action: actionShow})
+				}
+			case EventHandMucked:
+				rec.Actions = append(rec.Actions, ActionRecord{Addr: ev.Addr, Action: actionMuck})
+			case EventPotAwarded:
+				awards = append(awards, PotAward{Pot: ev.Pot, Addr: ev.Addr, Amount: ev.Amount})
+			case EventCardsRevealed:
+				for pos, card := range ev.Cards {
+					if pos < len(rec.Deck) {
+						rec.Deck[pos] = card.Short()
+					}
+				}
+			}
+		}
+
+		if err := tmp.apply(r.Event); err != nil {
+			return nil, err
+		}
+
+		if _, ok := r.Event.(EventHandEnded); ok && started {
+			rec.Result = &HandResult{Stacks: make(map[string]int), Awards: awards}
+			for addr, player := range tmp.players {
+				rec.Result.Stacks[addr] = player.Stack
+			}
+			return rec, nil
+		}
+	}
+
+	if rec == nil {
+		return nil, fmt.Errorf("hand %d not found", handNumber)
+	}
+
+	return rec, nil
+}
+
+// ReplayHand plays the recorded hand again and returns the full state after
+// every step. Every difference with the recorded result is reported as a
+// divergence.
+func ReplayHand(rec *HandRecord) (*ReplayResult, error) {
+	awards := []PotAward{}
+	pg, err := newReplayGame(rec, func(r EventRecord) {
+		if ev, ok := r.Event.(EventPotAwarded); ok {
+			awards = append(awards, PotAward{Pot: ev.Pot, Addr: ev.Addr, Amount: ev.Amount})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReplayResult{
+		Steps:       []ReplayStep{{Step: 0, State: pg.fullSnapshot()}},
+		Divergences: []string{},
+	}
+
+	for i, action := range rec.Actions {
+		if err := pg.replayAction(action); err != nil {
+			result.Divergences = append(result.Divergences,
+				fmt.Sprintf("step %d: %s %s %d failed: %s", i+1, action.Addr, action.Action, action.Amount, err))
+			return result, nil
+		}
+		result.Steps = append(result.Steps, ReplayStep{
+			Step:   i + 1,
+			Action: &rec.Actions[i],
+			State:  pg.fullSnapshot(),
+		})
+	}
+
+	if rec.Result == nil {
+		return result, nil
+	}
+
+	final := result.Steps[len(result.Steps)-1].State
+	if final.GameStarted {
+		result.Divergences = append(result.Divergences, "hand did not finish after the last action")
+	}
+	for addr, stack := range rec.Result.Stacks {
+		player, ok := final.Players[addr]
+		if !ok {
+			result.Divergences = append(result.Divergences, fmt.Sprintf("player %s missing", addr))
+			continue
+		}
+		if player.Stack != stack {
+			result.Divergences = append(result.Divergences,
+				fmt.Sprintf("stack of %s is %d, recorded %d", addr, player.Stack, stack))
+		}
+	}
+	if rec.Result.Awards != nil && !slices.Equal(awards, rec.Result.Awards) {
+		result.Divergences = append(result.Divergences,
+			fmt.Sprintf("awards are %v, recorded %v", awards, rec.Result.Awards))
+	}
+	if rec.Result.Pots != nil {
+		pots := make([]int, len(final.Pots))
+		for i, pot := range final.Pots {
+			pots[i] = pot.Amount
+		}
+		if !slices.Equal(pots, rec.Result.Pots) {
+			result.Divergences = append(result.Divergences,
+				fmt.Sprintf("pots are %v, recorded %v", pots, rec.Result.Pots))
+		}
+	}
+
+	return result, nil
+}
+
+// newReplayGame seats the players of the record and starts its hand.
+func newReplayGame(rec *HandRecord, handlers ...EventHandler) (*PokerGame, error) {
+	pg := NewPokerGame(rec.SmallBlind, rec.BigBlind)
+	if rec.Ante > 0 {
+		if err := pg.SetBlinds(rec.SmallBlind, rec.BigBlind, rec.Ante); err != nil {
+			return nil, err
+		}
+	}
+	for _, seat := range rec.Seats {
+		if err := pg.AddPlayer(seat.Addr, seat.Stack, seat.Position); err != nil {
+			return nil, err
+		}
+	}
+
+	buttonPos := pg.seatIndex(rec.Button)
+	if buttonPos < 0 {
+		return nil, fmt.Errorf("button player %s is not seated", rec.Button)
+	}
+	pg.dealerPos = (buttonPos - 1 + len(rec.Seats)) % len(rec.Seats)
+	pg.handNumber = max(rec.HandNumber-1, 0)
+
+	if rec.Rake != nil {
+		if err := pg.SetRake(*rec.Rake); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, seat := range rec.Seats {
+		if seat.PostsBigBlind {
+			pg.mu.Lock()
+			err := pg.record(EventSatIn{Addr: seat.Addr, PostBigBlind: true})
+			pg.mu.Unlock()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, h := range handlers {
+		pg.Subscribe(h)
+	}
+
+	if rec.Deck == nil {
+		return pg, pg.StartNewHandWithSeed(rec.Seed)
+	}
+
+	cards, err := parseCards(rec.Deck)
+	if err != nil {
+		return nil, err
+	}
+
+	return pg, pg.StartNewHandWithDeck(cards)
+}
+
+func (pg *PokerGame) replayAction(action ActionRecord) error {
+	switch strings.ToUpper(action.Action) {
+	case actionShow:
+		return pg.ShowHand(action.Addr)
+	case actionMuck:
+		return pg.MuckHand(action.Addr)
+	}
+
+	a, err := parsePlayerAction(action.Action)
+	if err != nil {
+		return err
+	}
+
+	return pg.PlayerAction(action.Addr, a, action.Amount)
+}
+
+func (pg *PokerGame) fullSnapshot() GameSnapshot {
+	pg.mu.RLock()
+	defer pg.mu.RUnlock()
+
+	return pg.snapshot("", true)
+}
+
+func parsePlayerAction(s string) (PlayerAction, error) {
+	for _, a := range []PlayerAction{
+		PlayerActionFold,
+		PlayerActionCheck,
+		PlayerActionCall,
+		PlayerActionBet,
+		PlayerActionRaise,
+	} {
+		if strings.EqualFold(a.String(), s) {
+			return a, nil
+		}
+	}
+
+	return PlayerActionNone, fmt.Errorf("invalid action %q", s)
+}
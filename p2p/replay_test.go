@@ -0,0 +1,73 @@
+package p2p
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func playRecordedHand(t *testing.T) *PokerGame {
+	game := NewPokerGame(10, 20)
+	assert.Nil(t, game.AddPlayer(":1", 1000, 0))
+	assert.Nil(t, game.AddPlayer(":2", 500, 1))
+	assert.Nil(t, game.AddPlayer(":3", 1500, 2))
+	assert.Nil(t, game.StartNewHandWithSeed(1234))
+
+	// [:1 BB] [:2 D] [:3 SB]
+	assert.Nil(t, game.PlayerAction(":2", PlayerActionRaise, 40))
+	assert.Nil(t, game.PlayerAction(":3", PlayerActionCall, 0))
+	assert.Nil(t, game.PlayerAction(":1", PlayerActionCall, 0))
+	assert.Nil(t, game.PlayerAction(":3", PlayerActionBet, 100))
+	assert.Nil(t, game.PlayerAction(":1", PlayerActionFold, 0))
+	assert.Nil(t, game.PlayerAction(":2", PlayerActionCall, 0))
+	for game.gameStarted && game.currentRound != Showdown {
+		assert.Nil(t, game.PlayerAction(game.actionOn, PlayerActionCheck, 0))
+	}
+	for game.gameStarted {
+		assert.Nil(t, game.ShowHand(game.actionOn))
+	}
+
+	return game
+}
+
+func TestReplayHand(t *testing.T) {
+	game := playRecordedHand(t)
+
+	rec, err := game.HandRecord(1)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1234), rec.Seed)
+	assert.Equal(t, ":2", rec.Button)
+	assert.Len(t, rec.Actions, 12)
+
+	result, err := ReplayHand(rec)
+	assert.Nil(t, err)
+	assert.False(t, result.Diverged(), result.Divergences)
+	assert.Len(t, result.Steps, len(rec.Actions)+1)
+
+	final := result.Steps[len(result.Steps)-1].State
+	for addr, player := range final.Players {
+		assert.Equal(t, game.players[addr].Stack, player.Stack)
+		assert.Equal(t, game.players[addr].HoleCards, player.HoleCards)
+	}
+	assert.Equal(t, game.communityCards, final.CommunityCards)
+}
+
+func TestReplayHandDetectsDivergence(t *testing.T) {
+	game := playRecordedHand(t)
+
+	rec, err := game.HandRecord(1)
+	assert.Nil(t, err)
+
+	rec.Result.Stacks[":1"] += 10
+	result, err := ReplayHand(rec)
+	assert.Nil(t, err)
+	assert.True(t, result.Diverged())
+
+	rec, _ = game.HandRecord(1)
+	rec.Seed++
+	rec.Actions[0].Action = "CHECK"
+	result, err = ReplayHand(rec)
+	assert.Nil(t, err)
+	assert.True(t, result.Diverged())
+	assert.Len(t, result.Steps, 1)
+}
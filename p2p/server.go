@@ -1,17 +1,26 @@
 package p2p
 
 import (
-	"bytes"
-	"encoding/gob"
+	"crypto/ed25519"
 	"fmt"
-	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
-const defaultMaxPlayers = 6
+const (
+	defaultMaxPlayers    = 6
+	defaultSmallBlind    = 10
+	defaultBigBlind      = 20
+	defaultStartingStack = 1000
+
+	defaultReconnectWindow = 30 * time.Second
+	// redialInterval is how often a lost peer is dialed during the
+	// reconnect window.
+	redialInterval = time.Second
+)
 
 type GameVariant uint8
 
@@ -31,18 +40,88 @@ const (
 	Other
 )
 
+// TransportType is how the server talks to its peers.
+type TransportType uint8
+
+func (tt TransportType) String() string {
+	switch tt {
+	case TransportTCP:
+		return "tcp"
+	case TransportGRPC:
+		return "grpc"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	// TransportTCP sends gob frames over TCP connections.
+	TransportTCP TransportType = iota
+	// TransportGRPC sends protobuf messages over gRPC streams, so clients
+	// in other languages can join.
+	TransportGRPC
+)
+
 type ServerConfig struct {
 	Version       string
 	ListenAddr    string
 	APIListenAddr string
 	GameVariant   GameVariant
 	MaxPlayers    int
+	// HandHistoryDir is where the hand histories of the table are written.
+	// When empty they are only kept in memory.
+	HandHistoryDir string
+	// Timers are the action timeouts of the games of this server.
+	Timers TimerConfig
+	// BuyIn holds the buy-in limits of the games of this server.
+	BuyIn BuyInConfig
+	// Rake is taken from the pots of hosted games.
+	Rake RakeConfig
+	// SmallBlind and BigBlind are the blinds of the table.
+	SmallBlind int
+	BigBlind   int
+	// StartingStack is what players sit down with, by default the maximum
+	// buy-in.
+	StartingStack int
+	// ReconnectWindow is how long players that lost the connection keep
+	// their seat, 30 seconds by default.
+	ReconnectWindow time.Duration
+	// Transport is how the server talks to its peers, TCP by default. All
+	// players of a table have to use the same one.
+	Transport TransportType
+	// DealDelay is how long the dealer waits before dealing a hand, 8
+	// seconds by default. A negative delay deals right away.
+	DealDelay time.Duration
+	// Identity is the key the server authenticates itself and signs its
+	// messages with. Its public key is our player ID, see PlayerID. A new
+	// one is made when empty.
+	Identity ed25519.PrivateKey
+	// TrustedPeers pins the identity keys of the players we connect with.
+	// When empty we trust on first use: every player that holds the key of
+	// its certificate is accepted, and is known by that key from then on.
+	// Anyone can take a seat then, so tables that are not open to everyone
+	// should pin the keys of their players.
+	TrustedPeers []ed25519.PublicKey
+	// Tournament makes the table a Sit & Go with this structure. The
+	// players register as they get ready, the cash game settings of the
+	// table are not used then.
+	Tournament *TournamentConfig
 }
 
 type Server struct {
 	ServerConfig
 
-	transport   *TCPTransport
+	// id is our player ID, made from the identity key.
+	id string
+	// seq is the sequence number of the last message we sent. It starts at
+	// the time we started, so it keeps counting up when we restart.
+	seq atomic.Uint64
+
+	seqLock sync.Mutex
+	// seqs are the sequence numbers we got by player.
+	seqs map[string]*seqWindow
+
+	transport   Transport
 	peerLock    sync.RWMutex
 	peers       map[string]*Peer
 	addPeer     chan *Peer
@@ -52,12 +131,66 @@ type Server struct {
 
 	// gameState *GameState
 	gameState *GameState
+	histories *HandHistoryWriter
+	apiServer *APIServer
 }
 
+// NewServer creates a server talking to its peers over the transport of
+// the config, encrypted and authenticated with TLS.
 func NewServer(cfg ServerConfig) *Server {
+	if cfg.Identity == nil {
+		cfg.Identity = newIdentity()
+	}
+	tlsConfig, err := NewTLSConfig(cfg.Identity, cfg.TrustedPeers)
+	if err != nil {
+		panic(err)
+	}
+	if len(cfg.TrustedPeers) == 0 {
+		logrus.WithFields(logrus.Fields{
+			"listenAddr": cfg.ListenAddr,
+		}).Warn("no trusted peers, accepting the key of any player")
+	}
+
+	switch cfg.Transport {
+	case TransportGRPC:
+		return NewServerWithTransport(cfg, NewGRPCTransport(cfg.ListenAddr, tlsConfig))
+	default:
+		return NewServerWithTransport(cfg, NewTLSTransport(cfg.ListenAddr, tlsConfig))
+	}
+}
+
+// NewServerWithTransport creates a server talking to its peers over tr,
+// for instance the transport of a MemoryNetwork.
+func NewServerWithTransport(cfg ServerConfig, tr Transport) *Server {
+	if cfg.Identity == nil {
+		cfg.Identity = newIdentity()
+	}
 	if cfg.MaxPlayers == 0 {
 		cfg.MaxPlayers = defaultMaxPlayers
 	}
+	if cfg.BigBlind == 0 {
+		cfg.SmallBlind, cfg.BigBlind = defaultSmallBlind, defaultBigBlind
+	}
+	if cfg.StartingStack == 0 {
+		cfg.StartingStack = cfg.BuyIn.Max
+	}
+	if cfg.StartingStack == 0 {
+		cfg.StartingStack = defaultStartingStack
+	}
+	if cfg.ReconnectWindow == 0 {
+		cfg.ReconnectWindow = defaultReconnectWindow
+	}
+	var tournament *Tournament
+	if cfg.Tournament != nil {
+		t, err := NewTournament(*cfg.Tournament)
+		if err != nil {
+			panic(err)
+		}
+		tournament = t
+		// The entries pay the buy-in of the tournament, pots aren't raked.
+		cfg.StartingStack = cfg.Tournament.StartingStack
+		cfg.BuyIn, cfg.Rake = BuyInConfig{}, RakeConfig{}
+	}
 
 	s := &Server{
 		ServerConfig: cfg,
@@ -66,44 +199,104 @@ func NewServer(cfg ServerConfig) *Server {
 		delPeer:      make(chan *Peer),
 		msgCh:        make(chan *Message, 100),
 		broadcastch:  make(chan BroadcastTo, 100),
+		transport:    tr,
+		id:           PlayerID(cfg.Identity.Public().(ed25519.PublicKey)),
+		seqs:         make(map[string]*seqWindow),
+	}
+	s.seq.Store(uint64(time.Now().UnixNano()))
+	game := NewPokerGame(cfg.SmallBlind, cfg.BigBlind)
+	if tournament != nil {
+		game = tournament.Game()
 	}
-	// s.gameState = NewGameState(s.ListenAddr, s.broadcastch)
-	s.gameState = NewGame(s.ListenAddr, s.broadcastch)
+	// Players are known by their ID at the table, the listen address is
+	// only used to connect.
+	s.gameState = NewGame(s.id, s.broadcastch, game, cfg.StartingStack)
+	s.gameState.SetTournament(tournament)
+	s.gameState.SetReconnectWindow(cfg.ReconnectWindow)
+	if cfg.DealDelay != 0 {
+		s.gameState.SetDealDelay(max(cfg.DealDelay, 0))
+	}
+	s.histories = NewHandHistoryWriter(s.id, cfg.HandHistoryDir, defaultHandHistoryMaxBytes)
+	s.histories.Attach(game, s.id)
 
 	// if s.ListenAddr == ":3000" {
 	// 	s.gameState.isDealer = true // just for testing!
 	// }
 
-	tr := NewTCPTransport(s.ListenAddr)
-	s.transport = tr
-
-	tr.AddPeer = s.addPeer
-	tr.DelPeer = s.addPeer
-
+	s.apiServer = NewAPIServer(cfg.APIListenAddr, s.gameState, s.histories)
+	s.AttachGame(game)
+	if cfg.APIListenAddr == "" {
+		// Tables in a single process don't need the API.
+		return s
+	}
 	go func(s *Server) {
-		apiServer := NewAPIServer(cfg.APIListenAddr, s.gameState)
-
 		logrus.WithFields(logrus.Fields{
 			"listenAddr": cfg.APIListenAddr,
 		}).Info("starting API server")
 
-		apiServer.Run()
+		s.apiServer.Run()
 
 	}(s)
 
 	return s
 }
 
-func (s *Server) Start() {
+// Start listens for peers and handles them in the background. Once it
+// returns other players can connect.
+func (s *Server) Start() error {
+	if err := s.transport.Listen(s.addPeer); err != nil {
+		return err
+	}
 	go s.loop()
 
 	logrus.WithFields(logrus.Fields{
 		"port":       s.ListenAddr,
+		"id":         s.id,
 		"variant":    s.GameVariant,
 		"maxPlayers": s.MaxPlayers,
 	}).Info("started new game server")
 
-	s.transport.ListenAndAccept()
+	return nil
+}
+
+// ID is the player ID of the server, see PlayerID.
+func (s *Server) ID() string {
+	return s.id
+}
+
+// newMessage makes a message from us of the given hand, signed with our
+// identity key.
+func (s *Server) newMessage(payload any, hand int) (*Message, error) {
+	msg := NewMessage(s.id, payload)
+	msg.Hand = hand
+	msg.Seq = s.seq.Add(1)
+	if err := msg.Sign(s.Identity); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// acceptSeq reports whether the message is not one we got before from the
+// player, see seqWindow.
+func (s *Server) acceptSeq(msg *Message) bool {
+	s.seqLock.Lock()
+	defer s.seqLock.Unlock()
+
+	w, ok := s.seqs[msg.From]
+	if !ok {
+		w = newSeqWindow()
+		s.seqs[msg.From] = w
+	}
+	return w.accept(msg.Seq)
+}
+
+// checkHand makes sure the action of a player is of the hand we are at, so
+// actions of other hands can't be played again.
+func (s *Server) checkHand(msg *Message) error {
+	if hand := s.gameState.game.currentHand(); msg.Hand != hand {
+		return fmt.Errorf("%T of hand %d from %s, we are at hand %d", msg.Payload, msg.Hand, msg.From, hand)
+	}
+	return nil
 }
 
 func (s *Server) sendPeerList(p *Peer) error {
@@ -111,7 +304,7 @@ func (s *Server) sendPeerList(p *Peer) error {
 		Peers: []string{},
 	}
 
-	peers := s.Peers()
+	peers := s.peerAddrs()
 	for i := 0; i < len(peers); i++ {
 		if peers[i] != p.listenAddr {
 			peerList.Peers = append(peerList.Peers, peers[i])
@@ -122,54 +315,90 @@ func (s *Server) sendPeerList(p *Peer) error {
 		return nil
 	}
 
-	msg := NewMessage(s.ListenAddr, peerList)
-	buf := new(bytes.Buffer)
-	if err := gob.NewEncoder(buf).Encode(msg); err != nil {
+	msg, err := s.newMessage(peerList, s.gameState.game.currentHand())
+	if err != nil {
 		return err
 	}
-
-	return p.Send(buf.Bytes())
+	return p.Send(msg)
 }
 
 func (s *Server) AddPeer(p *Peer) {
 	s.peerLock.Lock()
 	defer s.peerLock.Unlock()
 
-	s.peers[p.listenAddr] = p
+	s.peers[p.id] = p
 }
 
+// Peers returns the player IDs of the peers.
 func (s *Server) Peers() []string {
 	s.peerLock.RLock()
 	defer s.peerLock.RUnlock()
 
 	peers := make([]string, len(s.peers))
 	it := 0
-	for _, peer := range s.peers {
-		peers[it] = peer.listenAddr
+	for id := range s.peers {
+		peers[it] = id
 		it++
 	}
 
 	return peers
 }
 
+// peerAddrs returns the listen addresses of the peers, as the peers told
+// us in the handshake.
+func (s *Server) peerAddrs() []string {
+	s.peerLock.RLock()
+	defer s.peerLock.RUnlock()
+
+	addrs := make([]string, 0, len(s.peers))
+	for _, peer := range s.peers {
+		addrs = append(addrs, peer.listenAddr)
+	}
+
+	return addrs
+}
+
 func (s *Server) SendHandshake(p *Peer) error {
-	hs := &Handshake{
+	msg, err := s.handshakeMessage()
+	if err != nil {
+		return err
+	}
+	return p.Send(msg)
+}
+
+func (s *Server) handshakeMessage() (*Message, error) {
+	hs := Handshake{
 		GameVariant: s.GameVariant,
 		Version:     s.Version,
 		GameStatus:  GameStatus(s.gameState.currentStatus.Get()),
 		ListenAddr:  s.ListenAddr,
 	}
 
-	buf := new(bytes.Buffer)
-	if err := gob.NewEncoder(buf).Encode(hs); err != nil {
+	return s.newMessage(hs, s.gameState.game.currentHand())
+}
+
+// replyHandshake sends our handshake to a player that dialed us and
+// registers the peer along with it, so no broadcast reaches the player
+// before the handshake and none after it misses the player.
+func (s *Server) replyHandshake(p *Peer) error {
+	msg, err := s.handshakeMessage()
+	if err != nil {
 		return err
 	}
 
-	return p.Send(buf.Bytes())
+	s.peerLock.Lock()
+	defer s.peerLock.Unlock()
+
+	if err := p.Send(msg); err != nil {
+		return err
+	}
+	s.peers[p.id] = p
+
+	return nil
 }
 
 func (s *Server) isInPeerList(addr string) bool {
-	peers := s.Peers()
+	peers := s.peerAddrs()
 	for i := 0; i < len(peers); i++ {
 		if peers[i] == addr {
 			return true
@@ -185,37 +414,47 @@ func (s *Server) Connect(addr string) error {
 		return nil
 	}
 
-	conn, err := net.DialTimeout("tcp", addr, 1*time.Second)
+	conn, err := s.transport.Dial(addr)
 	if err != nil {
 		return err
 	}
 
-	peer := &Peer{
-		conn:     conn,
-		outbound: true,
-	}
+	peer := NewPeer(conn, true)
 
 	s.addPeer <- peer
 
-	return s.SendHandshake(peer)
+	if err := s.SendHandshake(peer); err != nil {
+		return err
+	}
+
+	// Wait until the peer is registered, so the peer list we send to
+	// players connecting to us includes it.
+	return <-peer.handshake
 }
 
+// loop registers and unregisters peers. Broadcasts and messages are each
+// handled one after the other in their own goroutine, so the messages of a
+// player are handled in the order the player sent them.
 func (s *Server) loop() {
+	go func() {
+		for msg := range s.broadcastch {
+			if err := s.Broadcast(msg); err != nil {
+				logrus.Errorf("broadcast error: %s", err)
+			}
+		}
+	}()
+	go func() {
+		for msg := range s.msgCh {
+			if err := s.handleMessage(msg); err != nil {
+				logrus.Errorf("handle msg error: %s", err)
+			}
+		}
+	}()
+
 	for {
 		select {
-		case msg := <-s.broadcastch:
-			go func() {
-				if err := s.Broadcast(msg); err != nil {
-					logrus.Errorf("broadcast error: %s", err)
-				}
-			}()
-
 		case peer := <-s.delPeer:
-			logrus.WithFields(logrus.Fields{
-				"addr": peer.conn.RemoteAddr(),
-			}).Info("new player disconnected")
-
-			delete(s.peers, peer.conn.RemoteAddr().String())
+			s.removePeer(peer)
 
 			// If a new peer connects to the server we send our handshake message and wait
 			// for his reply.
@@ -223,33 +462,42 @@ func (s *Server) loop() {
 			if err := s.handleNewPeer(peer); err != nil {
 				logrus.Errorf("handle peer error: %s", err)
 			}
-
-		case msg := <-s.msgCh:
-			go func() {
-				if err := s.handleMessage(msg); err != nil {
-					logrus.Errorf("handle msg error: %s", err)
-				}
-			}()
 		}
 	}
 }
 
 func (s *Server) handleNewPeer(peer *Peer) error {
+	err := s.registerPeer(peer)
+	peer.handshake <- err
+
+	return err
+}
+
+func (s *Server) registerPeer(peer *Peer) error {
 	_, err := s.handshake(peer)
 	if err != nil {
 		peer.conn.Close()
-		delete(s.peers, peer.conn.RemoteAddr().String())
 
 		return fmt.Errorf("%s:handshake with incoming player failed: %s ", s.ListenAddr, err)
 	}
 
-	// NOTE: this readLoop always needs to start after the handshake!
-	go peer.ReadLoop(s.msgCh)
+	// New players are seated in the list before we reply, so once the
+	// player has our handshake the messages either of us sends find the
+	// other. Players that are away catch up after it.
+	away := s.gameState.isAway(peer.id)
+	known := s.gameState.playersList.getIndex(peer.id) != -1
+	if !away {
+		s.gameState.AddPlayer(peer.id)
+	}
 
-	if !peer.outbound {
-		if err := s.SendHandshake(peer); err != nil {
+	if peer.outbound {
+		s.AddPeer(peer)
+	} else {
+		if err := s.replyHandshake(peer); err != nil {
+			if !away && !known {
+				s.gameState.playersList.remove(peer.id)
+			}
 			peer.conn.Close()
-			delete(s.peers, peer.conn.RemoteAddr().String())
 
 			return fmt.Errorf("failed to send handshake with peer: %s", err)
 		}
@@ -264,33 +512,104 @@ func (s *Server) handleNewPeer(peer *Peer) error {
 	logrus.WithFields(logrus.Fields{
 		"peer":       peer.conn.RemoteAddr(),
 		"listenAddr": peer.listenAddr,
+		"id":         peer.id,
 		"we":         s.ListenAddr,
 	}).Info("handshake successfull: new player connected")
 
-	s.AddPeer(peer)
+	// NOTE: this readLoop always needs to start after the handshake!
+	go peer.ReadLoop(s.msgCh, s.delPeer)
 
-	s.gameState.AddPlayer(peer.listenAddr)
+	if away && !s.gameState.Reconnect(peer.id) {
+		// The reconnect window ran out in the meantime.
+		s.gameState.AddPlayer(peer.id)
+	}
 
 	return nil
 }
 
-func (s *Server) Broadcast(broadcastMsg BroadcastTo) error {
-	msg := NewMessage(s.ListenAddr, broadcastMsg.Payload)
+// removePeer unregisters a peer of which the connection is closed. The
+// player keeps the seat during the reconnect window, in which we dial the
+// peer again if we dialed it in the first place.
+func (s *Server) removePeer(peer *Peer) {
+	s.peerLock.Lock()
+	if s.peers[peer.id] != peer {
+		// The peer never finished the handshake or connected again.
+		s.peerLock.Unlock()
+		return
+	}
+	delete(s.peers, peer.id)
+	s.peerLock.Unlock()
+
+	logrus.WithFields(logrus.Fields{
+		"addr":       peer.conn.RemoteAddr(),
+		"listenAddr": peer.listenAddr,
+		"id":         peer.id,
+		"we":         s.ListenAddr,
+	}).Info("player disconnected")
+
+	s.gameState.PlayerDisconnected(peer.id)
+	if peer.outbound {
+		go s.redial(peer.listenAddr)
+	}
+}
 
-	buf := new(bytes.Buffer)
-	if err := gob.NewEncoder(buf).Encode(msg); err != nil {
+// redial dials a lost peer until it is back or the reconnect window is
+// over. The handshake tells the peer we are the player that left.
+func (s *Server) redial(addr string) {
+	deadline := time.Now().Add(s.ReconnectWindow)
+	for time.Now().Before(deadline) {
+		if err := s.Connect(addr); err == nil {
+			return
+		}
+		time.Sleep(redialInterval)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"we":   s.ListenAddr,
+		"peer": addr,
+	}).Info("could not reconnect to peer")
+}
+
+// handlePlayerDisconnected is getting called when a player in the network
+// lost the connection to another player. The player is taken off the table
+// of all nodes alike, so we hang up as well.
+func (s *Server) handlePlayerDisconnected(msg MessagePlayerDisconnected) error {
+	if msg.Addr == s.id {
+		return fmt.Errorf("disconnected from the table")
+	}
+
+	s.peerLock.RLock()
+	peer, ok := s.peers[msg.Addr]
+	s.peerLock.RUnlock()
+	if ok {
+		peer.conn.Close()
+	}
+
+	s.gameState.RemovePlayer(msg.Addr)
+
+	return nil
+}
+
+func (s *Server) Broadcast(broadcastMsg BroadcastTo) error {
+	msg, err := s.newMessage(broadcastMsg.Payload, broadcastMsg.Hand)
+	if err != nil {
 		return err
 	}
 
+	s.peerLock.RLock()
+	peers := []*Peer{}
 	for _, addr := range broadcastMsg.To {
-		peer, ok := s.peers[addr]
-
-		if ok {
-			go func(peer *Peer) {
-				if err := peer.Send(buf.Bytes()); err != nil {
-					logrus.Errorf("broadcast to peer error: %s", err)
-				}
-			}(peer)
+		if peer, ok := s.peers[addr]; ok {
+			peers = append(peers, peer)
+		}
+	}
+	s.peerLock.RUnlock()
+
+	// Messages are sent in order, a player may only act on one once it
+	// got the ones before.
+	for _, peer := range peers {
+		if err := peer.Send(msg); err != nil {
+			logrus.Errorf("broadcast to peer error: %s", err)
 		}
 	}
 
@@ -298,14 +617,37 @@ func (s *Server) Broadcast(broadcastMsg BroadcastTo) error {
 }
 
 func (s *Server) handshake(p *Peer) (*Handshake, error) {
-	if len(s.peers) > s.MaxPlayers {
+	if len(s.Peers()) > s.MaxPlayers {
 		return nil, fmt.Errorf("max players exceeded (%d)", s.MaxPlayers)
 	}
 
-	hs := &Handshake{}
-	if err := gob.NewDecoder(p.conn).Decode(hs); err != nil {
+	msg, err := p.conn.Receive()
+	if err != nil {
+		return nil, err
+	}
+	hs, ok := msg.Payload.(Handshake)
+	if !ok {
+		return nil, fmt.Errorf("expected handshake, got %T", msg.Payload)
+	}
+	if err := msg.Verify(); err != nil {
 		return nil, err
 	}
+	if msg.From == s.id {
+		return nil, fmt.Errorf("connected to ourselves")
+	}
+	if !s.acceptSeq(msg) {
+		return nil, fmt.Errorf("replayed handshake %d of %s", msg.Seq, msg.From)
+	}
+	// The player has to be who the transport authenticated, if it does.
+	if conn, ok := p.conn.(authenticatedConn); ok && conn.Authenticated() {
+		key := conn.PeerKey()
+		if key == nil {
+			return nil, fmt.Errorf("player %s did not authenticate", msg.From)
+		}
+		if PlayerID(key) != msg.From {
+			return nil, fmt.Errorf("player %s authenticated as %s", msg.From, PlayerID(key))
+		}
+	}
 
 	if s.GameVariant != hs.GameVariant {
 		return nil, fmt.Errorf("gamevariant does not match %s", hs.GameVariant)
@@ -314,37 +656,144 @@ func (s *Server) handshake(p *Peer) (*Handshake, error) {
 		return nil, fmt.Errorf("invalid version %s", hs.Version)
 	}
 
+	p.id = msg.From
 	p.listenAddr = hs.ListenAddr
 
-	return hs, nil
+	return &hs, nil
 }
 
 func (s *Server) handleMessage(msg *Message) error {
+	if err := msg.Verify(); err != nil {
+		return err
+	}
+	s.peerLock.RLock()
+	_, ok := s.peers[msg.From]
+	s.peerLock.RUnlock()
+	if !ok {
+		return fmt.Errorf("message from unknown player %s", msg.From)
+	}
+	if !s.acceptSeq(msg) {
+		return fmt.Errorf("replayed %T %d of %s", msg.Payload, msg.Seq, msg.From)
+	}
+
 	switch v := msg.Payload.(type) {
 	case MessagePreFlop:
-		return s.handleMsgPreFlop(msg.From)
+		return s.handleMsgPreFlop(msg.From, v)
 	case MessagePeerList:
-		return s.handlePeerList(v)
+		// Dialing the peers waits for their handshakes, which must not hold
+		// up the messages of other players.
+		go func() {
+			if err := s.handlePeerList(v); err != nil {
+				logrus.Errorf("peerlist error: %s", err)
+			}
+		}()
+		return nil
 	case MessageEncDeck:
 		return s.handleMsgEncDeck(msg.From, v)
 	case MessageReady:
 		return s.handleMsgReady(msg.From)
+	case MessageSitOut:
+		return s.gameState.SetPlayerSittingOut(msg.From, true, v.NextBigBlind)
+	case MessageSitIn:
+		return s.gameState.SetPlayerSittingOut(msg.From, false, v.WaitForBigBlind)
+	case MessageChipsAdded:
+		return s.gameState.handleChipsAdded(msg.From, v)
 	case MessagePlayerAction:
+		if err := s.checkHand(msg); err != nil {
+			return err
+		}
 		return s.handleGetMsgPlayerAction(msg.From, v)
+	case MessageShowHand:
+		if err := s.checkHand(msg); err != nil {
+			return err
+		}
+		return s.gameState.handleShowHand(msg.From, v)
+	case MessageDealer:
+		return s.gameState.handleDealer(msg.From, v)
+	case MessageCardKeys:
+		return s.gameState.handleCardKeys(msg.From, v)
+	case MessagePlayerDisconnected:
+		return s.handlePlayerDisconnected(v)
+	case MessageStateSync:
+		return s.gameState.handleStateSync(msg.From, v)
+	case MessageTimerStarted, MessageTimerStopped:
+		return s.handleMsgTimer(msg.From, v)
+	case MessageDealProposed:
+		return s.gameState.handleDealProposed(msg.From, v)
+	case MessageDealAnswer:
+		return s.gameState.handleDealAnswer(msg.From, v)
 	}
 	return nil
 }
 
-func (s *Server) handleGetMsgPlayerAction(from string, msg MessagePlayerAction) error {
-	return s.gameState.handlePlayerAction(from, msg)
+// AttachGame configures the game like the server and keeps peers and
+// websocket clients up to date with it. The returned function detaches the
+// game again.
+func (s *Server) AttachGame(pg *PokerGame) func() {
+	pg.SetTimers(s.Timers)
+	pg.SetBuyIn(s.BuyIn)
+	if err := pg.SetRake(s.Rake); err != nil {
+		logrus.Errorf("rake config error: %s", err)
+	}
+
+	return pg.Subscribe(func(r EventRecord) {
+		// The game is locked while handlers run, so the snapshot is taken
+		// without locking it again.
+		s.apiServer.PublishState(pg.snapshot(s.id, false))
+
+		var (
+			msg  any
+			addr string
+		)
+		switch e := r.Event.(type) {
+		case EventTimerStarted:
+			msg, addr = MessageTimerStarted{Addr: e.Addr, Started: e.Started, Timeout: e.Timeout, TimeBank: e.TimeBank}, e.Addr
+		case EventTimerStopped:
+			msg, addr = MessageTimerStopped{Addr: e.Addr, TimeBankUsed: e.TimeBankUsed, TimedOut: e.TimedOut}, e.Addr
+		default:
+			return
+		}
+
+		s.apiServer.Publish(r)
+		// Every node runs the clocks of all players, only the node of the
+		// player tells the others what its clock says.
+		if addr == s.id {
+			s.broadcastch <- BroadcastTo{To: s.Peers(), Payload: msg, Hand: r.HandNumber}
+		}
+	})
 }
 
-func (s *Server) handleMsgPreFlop(from string) error {
-	s.gameState.SetStatus(GameStatusPreFlop)
+// handleMsgTimer is getting called when the clock of a player started or
+// stopped on the node of that player. We take the time bank used off the
+// bank of the player, so it is the same on every node.
+func (s *Server) handleMsgTimer(from string, msg any) error {
+	switch v := msg.(type) {
+	case MessageTimerStarted:
+		if v.Addr != from {
+			return fmt.Errorf("player (%s) started the clock of player (%s)", from, v.Addr)
+		}
+	case MessageTimerStopped:
+		if v.Addr != from {
+			return fmt.Errorf("player (%s) stopped the clock of player (%s)", from, v.Addr)
+		}
+		// A timeout is applied with the action it comes with, see
+		// GameState.handleTimeout.
+		if !v.TimedOut {
+			return s.gameState.game.StopTimer(from, v.TimeBankUsed)
+		}
+	}
 
 	return nil
 }
 
+func (s *Server) handleGetMsgPlayerAction(from string, msg MessagePlayerAction) error {
+	return s.gameState.handlePlayerAction(from, msg)
+}
+
+func (s *Server) handleMsgPreFlop(from string, msg MessagePreFlop) error {
+	return s.gameState.StartHand(from, msg.Deck)
+}
+
 func (s *Server) handleMsgReady(from string) error {
 	s.gameState.SetPlayerReady(from)
 
@@ -357,7 +806,7 @@ func (s *Server) handleMsgEncDeck(from string, msg MessageEncDeck) error {
 		"from": from,
 	}) // .Info("recv env deck")
 
-	return s.gameState.ShuffleAndEncrypt(from, msg.Deck)
+	return s.gameState.ShuffleAndEncrypt(from, msg)
 }
 
 // TODO FIXME: (@anthdm) maybe goroutine??
@@ -376,11 +825,3 @@ func (s *Server) handlePeerList(l MessagePeerList) error {
 
 	return nil
 }
-
-func init() {
-	gob.Register(MessagePeerList{})
-	gob.Register(MessageEncDeck{})
-	gob.Register(MessageReady{})
-	gob.Register(MessagePreFlop{})
-	gob.Register(MessagePlayerAction{})
-}
@@ -0,0 +1,299 @@
+package p2p
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// nodeEvent is an event recorded by the game of a node.
+type nodeEvent struct {
+	node   int
+	record EventRecord
+}
+
+// watchServers sends the events of the games of the servers to a channel.
+func watchServers(servers []*Server) chan nodeEvent {
+	events := make(chan nodeEvent, 4096)
+	for i, s := range servers {
+		s.gameState.game.Subscribe(func(r EventRecord) {
+			events <- nodeEvent{node: i, record: r}
+		})
+	}
+	return events
+}
+
+// nextEvent returns the next event of any node. The timeout only keeps a
+// broken game from hanging the test.
+func nextEvent(t *testing.T, events chan nodeEvent) nodeEvent {
+	t.Helper()
+
+	select {
+	case ev := <-events:
+		return ev
+	case <-time.After(30 * time.Second):
+		t.Fatal("no event from any node")
+	}
+	return nodeEvent{}
+}
+
+// playTurn lets a server act once every node has it on the move: it shows
+// at showdown and calls or checks otherwise. Actions of different players
+// travel on different connections, so a player acting before every node
+// has the action before may reach a node out of turn.
+func playTurn(t *testing.T, servers []*Server, s *Server) {
+	for _, other := range servers {
+		if len(other.gameState.game.LegalActions(s.ID())) == 0 {
+			return
+		}
+	}
+
+	actions := s.gameState.game.LegalActions(s.ID())
+	switch {
+	case actions[0].Action == "SHOW":
+		assert.Nil(t, s.gameState.ShowHand(false))
+	case actions[1].Action == PlayerActionCall.String():
+		assert.Nil(t, s.gameState.TakeAction(PlayerActionCall, 0))
+	default:
+		assert.Nil(t, s.gameState.TakeAction(PlayerActionCheck, 0))
+	}
+}
+
+// replicated leaves out what every node records at its own time: the keys
+// of the cards arrive while the players go on, so a card may be written
+// down face down on one node and face up on another.
+func replicated(records []EventRecord) []GameEvent {
+	events := []GameEvent{}
+	for _, r := range records {
+		switch e := r.Event.(type) {
+		case EventCardsRevealed:
+		case EventCardsDealt:
+			events = append(events, EventCardsDealt{Addr: e.Addr})
+		case EventStreetDealt:
+			events = append(events, EventStreetDealt{Round: e.Round})
+		case EventCardsShown:
+			events = append(events, EventCardsShown{Addr: e.Addr})
+		default:
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+func TestServersPlayOverMemoryNetwork(t *testing.T) {
+	network := NewMemoryNetwork()
+	addrs := []string{":3000", ":4000", ":5000"}
+	servers := []*Server{}
+	for _, addr := range addrs {
+		// The hand is dealt by the test once everyone is seated.
+		s := NewServerWithTransport(ServerConfig{Version: "test", ListenAddr: addr, DealDelay: time.Hour}, network.Transport(addr))
+		assert.Nil(t, s.Start())
+		servers = append(servers, s)
+	}
+	assert.NotNil(t, servers[0].Start())
+	events := watchServers(servers)
+
+	// Every player we get in a peer list is connected already. Both sides
+	// have registered the other once Connect returns.
+	assert.Nil(t, servers[0].Connect(":4000"))
+	assert.Nil(t, servers[0].Connect(":5000"))
+	assert.Nil(t, servers[1].Connect(":5000"))
+	for _, s := range servers {
+		assert.Len(t, s.Peers(), 2)
+	}
+
+	// Players get ready one after the other, so every node seats them in
+	// the same order.
+	for _, s := range servers {
+		s.gameState.SetReady()
+		for seated := 0; seated < len(servers); {
+			if _, ok := nextEvent(t, events).record.Event.(EventPlayerJoined); ok {
+				seated++
+			}
+		}
+	}
+	dealer, _ := servers[0].gameState.getCurrentDealerAddr()
+	for _, s := range servers {
+		if s.ID() == dealer {
+			s.gameState.maybeDeal()
+		}
+	}
+
+	for ended := 0; ended < len(servers); {
+		if _, ok := nextEvent(t, events).record.Event.(EventHandEnded); ok {
+			ended++
+			continue
+		}
+		for _, s := range servers {
+			playTurn(t, servers, s)
+		}
+	}
+
+	// Keys may still be on their way to nodes that are done with the hand.
+	want := servers[0].gameState.game
+	want.mu.RLock()
+	defer want.mu.RUnlock()
+	for _, s := range servers[1:] {
+		game := s.gameState.game
+		game.mu.RLock()
+		defer game.mu.RUnlock()
+		for addr, player := range want.players {
+			assert.Equal(t, player.Stack, game.players[addr].Stack)
+			assert.Equal(t, player.HoleCards, game.players[addr].HoleCards)
+		}
+		assert.Equal(t, want.communityCards, game.communityCards)
+		assert.Equal(t, replicated(want.events), replicated(game.events))
+	}
+
+	_, err := network.Transport(":6000").Dial(":7000")
+	assert.NotNil(t, err)
+}
+
+func TestDealerDealsWhenReadyLast(t *testing.T) {
+	network := NewMemoryNetwork()
+	servers := []*Server{}
+	for _, addr := range []string{":3000", ":4000"} {
+		s := NewServerWithTransport(ServerConfig{Version: "test", ListenAddr: addr, DealDelay: -1}, network.Transport(addr))
+		assert.Nil(t, s.Start())
+		servers = append(servers, s)
+	}
+	events := watchServers(servers)
+	assert.Nil(t, servers[0].Connect(":4000"))
+
+	// The player seated last gets the button, so it readies last.
+	sort.Slice(servers, func(i, j int) bool { return servers[i].ID() < servers[j].ID() })
+	for _, s := range servers {
+		s.gameState.SetReady()
+		for seated := 0; seated < len(servers); {
+			if _, ok := nextEvent(t, events).record.Event.(EventPlayerJoined); ok {
+				seated++
+			}
+		}
+	}
+	dealer, _ := servers[0].gameState.getCurrentDealerAddr()
+	assert.Equal(t, servers[1].ID(), dealer)
+
+	for started := 0; started < len(servers); {
+		if _, ok := nextEvent(t, events).record.Event.(EventHandStarted); ok {
+			started++
+		}
+	}
+	for _, s := range servers {
+		started, round, _ := s.gameState.game.handStatus()
+		assert.True(t, started)
+		assert.Equal(t, PreFlop, round)
+	}
+}
+
+func TestServerSendsOnlyItsOwnClock(t *testing.T) {
+	network := NewMemoryNetwork()
+	s := NewServerWithTransport(ServerConfig{
+		Version:    "test",
+		ListenAddr: ":3000",
+		Timers:     TimerConfig{ActionTimeout: time.Hour, TimeBank: time.Minute},
+	}, network.Transport(":3000"))
+
+	game := NewPokerGame(10, 20)
+	s.AttachGame(game)
+	assert.Nil(t, game.AddPlayer(s.ID(), 1000, 0))
+	assert.Nil(t, game.AddPlayer(":4000", 1000, 1))
+	assert.Nil(t, game.StartNewHandWithSeed(42))
+	for _, action := range []PlayerAction{PlayerActionCall, PlayerActionCheck} {
+		_, _, actionOn := game.handStatus()
+		assert.Nil(t, game.PlayerAction(actionOn, action, 0))
+	}
+
+	assert.NotEmpty(t, s.broadcastch)
+	for len(s.broadcastch) > 0 {
+		switch msg := (<-s.broadcastch).Payload.(type) {
+		case MessageTimerStarted:
+			assert.Equal(t, s.ID(), msg.Addr)
+		case MessageTimerStopped:
+			assert.Equal(t, s.ID(), msg.Addr)
+		}
+	}
+}
+
+func TestServersAgreeOnTournamentDeal(t *testing.T) {
+	network := NewMemoryNetwork()
+	servers := []*Server{}
+	for _, addr := range []string{":3000", ":4000"} {
+		s := NewServerWithTransport(ServerConfig{
+			Version:    "test",
+			ListenAddr: addr,
+			DealDelay:  time.Hour,
+			Tournament: &TournamentConfig{
+				Seats:         2,
+				StartingStack: 1500,
+				Levels:        []BlindLevel{{SmallBlind: 10, BigBlind: 20}},
+				BuyIn:         100,
+				Payouts:       []int{65, 35},
+				StartWhenFull: true,
+			},
+		}, network.Transport(addr))
+		assert.Nil(t, s.Start())
+		servers = append(servers, s)
+	}
+	events := watchServers(servers)
+	assert.Nil(t, servers[0].Connect(":4000"))
+
+	for _, s := range servers {
+		s.gameState.SetReady()
+		for seated := 0; seated < len(servers); {
+			if _, ok := nextEvent(t, events).record.Event.(EventPlayerJoined); ok {
+				seated++
+			}
+		}
+	}
+	for _, s := range servers {
+		assert.Equal(t, TournamentRunning, s.gameState.tournament.Status())
+	}
+
+	hasDeal := func(want bool) func() bool {
+		return func() bool {
+			for _, s := range servers {
+				if (s.gameState.tournament.Deal() != nil) != want {
+					return false
+				}
+			}
+			return true
+		}
+	}
+
+	_, err := servers[0].gameState.ProposeDeal(DealChipChop)
+	assert.Nil(t, err)
+	assert.Eventually(t, hasDeal(true), 5*time.Second, time.Millisecond)
+
+	// No hand is dealt while the deal is open.
+	for _, s := range servers {
+		if _, isDealer := s.gameState.getCurrentDealerAddr(); isDealer {
+			s.gameState.maybeDeal()
+		}
+		assert.Equal(t, GameStatusPlayerReady, GameStatus(s.gameState.currentStatus.Get()))
+	}
+
+	assert.Nil(t, servers[1].gameState.AnswerDeal(false))
+	assert.Eventually(t, hasDeal(false), 5*time.Second, time.Millisecond)
+
+	_, err = servers[1].gameState.ProposeDeal(DealICM)
+	assert.Nil(t, err)
+	assert.Eventually(t, hasDeal(true), 5*time.Second, time.Millisecond)
+	for _, s := range servers {
+		assert.Nil(t, s.gameState.AnswerDeal(true))
+	}
+	assert.Eventually(t, func() bool {
+		for _, s := range servers {
+			if s.gameState.tournament.Status() != TournamentFinished {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, time.Millisecond)
+
+	standings := servers[0].gameState.tournament.Standings()
+	assert.Len(t, standings, 2)
+	assert.Equal(t, standings, servers[1].gameState.tournament.Standings())
+	assert.Equal(t, 200, standings[0].Prize+standings[1].Prize)
+}
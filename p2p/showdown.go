@@ -0,0 +1,369 @@
+package p2p
+
+import (
+	"fmt"
+
+	"github.com/koshiq/ggpoker/deck"
+)
+
+// showdown starts the showdown, as soon as every card it needs is face up.
+func (pg *PokerGame) showdown(allIn bool) error {
+	if pg.hasFaceDownCards(allIn) {
+		return pg.record(EventRevealPending{AllIn: allIn})
+	}
+
+	return pg.startShowdown(allIn)
+}
+
+// startShowdown determines the order in which the remaining players show
+// their hands. The last aggressor of the final betting round shows first,
+// if there was none the first player left of the button starts. When the
+// board was run out because players are all-in every hand is turned face
+// up right away.
+func (pg *PokerGame) startShowdown(allIn bool) error {
+	playerAddrs := pg.seatOrder()
+	first := ""
+	if p, ok := pg.players[pg.lastRaise]; ok && !p.Folded {
+		first = pg.lastRaise
+	} else {
+		for i := 1; i <= len(playerAddrs); i++ {
+			addr := playerAddrs[(pg.dealerPos+i)%len(playerAddrs)]
+			if !pg.players[addr].Folded {
+				first = addr
+				break
+			}
+		}
+	}
+
+	start := pg.seatIndex(first)
+	order := make([]string, 0)
+	for i := 0; i < len(playerAddrs); i++ {
+		addr := playerAddrs[(start+i)%len(playerAddrs)]
+		if !pg.players[addr].Folded {
+			order = append(order, addr)
+		}
+	}
+
+	if err := pg.record(EventShowdownStarted{Order: order, AllIn: allIn}); err != nil {
+		return err
+	}
+
+	if allIn {
+		for _, addr := range order {
+			if err := pg.record(EventCardsShown{Addr: addr, Cards: pg.players[addr].HoleCards}); err != nil {
+				return err
+			}
+		}
+		return pg.finishShowdown()
+	}
+
+	return nil
+}
+
+// ShowHand exposes the hole cards of the player whose turn it is at showdown.
+func (pg *PokerGame) ShowHand(addr string) error {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	if err := pg.showHand(addr); err != nil {
+		return err
+	}
+
+	return pg.startTimer()
+}
+
+func (pg *PokerGame) showHand(addr string) error {
+	player, err := pg.showdownPlayer(addr)
+	if err != nil {
+		return err
+	}
+
+	if err := pg.stopTimer(addr, false); err != nil {
+		return err
+	}
+
+	if err := pg.record(EventCardsShown{Addr: addr, Cards: player.HoleCards}); err != nil {
+		return err
+	}
+
+	return pg.maybeFinishShowdown()
+}
+
+// MuckHand throws away the hand of the player whose turn it is at showdown.
+// Mucking is only possible when the hand is already beaten by a shown hand
+// in every pot the player is contesting. Only the player knows a hand that
+// is mucked, the other players take the muck as it is.
+func (pg *PokerGame) MuckHand(addr string) error {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	if err := pg.muckHand(addr); err != nil {
+		return err
+	}
+
+	return pg.startTimer()
+}
+
+func (pg *PokerGame) muckHand(addr string) error {
+	if _, err := pg.showdownPlayer(addr); err != nil {
+		return err
+	}
+
+	if !pg.mayMuck(addr) {
+		return fmt.Errorf("player %s is not beaten and has to show", addr)
+	}
+
+	if err := pg.stopTimer(addr, false); err != nil {
+		return err
+	}
+
+	if err := pg.record(EventHandMucked{Addr: addr}); err != nil {
+		return err
+	}
+
+	return pg.maybeFinishShowdown()
+}
+
+// ShowCards lets the player that won the last hand uncontested show some
+// of the hole cards, e.g. only one of them.
+func (pg *PokerGame) ShowCards(addr string, cardIndexes ...int) error {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	if pg.gameStarted || pg.foldWinner != addr {
+		return fmt.Errorf("player %s did not win the last hand uncontested", addr)
+	}
+
+	player := pg.players[addr]
+	cards := make([]deck.Card, 0, len(cardIndexes))
+	for _, i := range cardIndexes {
+		if i < 0 || i >= len(player.HoleCards) {
+			return fmt.Errorf("invalid card index %d", i)
+		}
+		cards = append(cards, player.HoleCards[i])
+	}
+
+	return pg.record(EventCardsShown{Addr: addr, Cards: cards})
+}
+
+func (pg *PokerGame) showdownPlayer(addr string) (*PlayerState, error) {
+	player, exists := pg.players[addr]
+	if !exists {
+		return nil, fmt.Errorf("player %s not found", addr)
+	}
+
+	if !pg.gameStarted || pg.currentRound != Showdown {
+		return nil, fmt.Errorf("not at showdown")
+	}
+
+	if pg.actionOn != addr {
+		return nil, fmt.Errorf("player %s acting out of turn, waiting for %s", addr, pg.actionOn)
+	}
+
+	return player, nil
+}
+
+// advanceShowdown moves the action to the next player in the showdown
+// order once addr has shown or mucked.
+func (pg *PokerGame) advanceShowdown(addr string) {
+	if !pg.gameStarted || pg.currentRound != Showdown || pg.actionOn != addr {
+		return
+	}
+
+	pg.showdownPos++
+	pg.actionOn = ""
+	if pg.showdownPos < len(pg.showdownOrder) {
+		pg.actionOn = pg.showdownOrder[pg.showdownPos]
+	}
+}
+
+// maybeFinishShowdown finishes the showdown once every player showed or
+// mucked and the hands shown are turned up.
+func (pg *PokerGame) maybeFinishShowdown() error {
+	if !pg.gameStarted || pg.showdownPos < len(pg.showdownOrder) || pg.shownFaceDown() {
+		return nil
+	}
+
+	return pg.finishShowdown()
+}
+
+func (pg *PokerGame) finishShowdown() error {
+	if err := pg.takeRake(); err != nil {
+		return err
+	}
+
+	for i, pot := range pg.pot {
+		if err := pg.determineWinner(i, pot); err != nil {
+			return err
+		}
+	}
+
+	if err := pg.awardBounties(); err != nil {
+		return err
+	}
+
+	return pg.record(EventHandEnded{})
+}
+
+// hasShown reports whether the player has turned over the complete hand.
+func (pg *PokerGame) hasShown(addr string) bool {
+	player := pg.players[addr]
+	return !player.Mucked && len(player.ShownCards) == len(player.HoleCards)
+}
+
+// mayMuck reports whether the player may muck at showdown: the hand is
+// beaten, or only the player knows it and we can't tell.
+func (pg *PokerGame) mayMuck(addr string) bool {
+	return !pg.isKnown(addr) || pg.isBeaten(addr)
+}
+
+// isKnown reports whether all hole cards of the player are face up to us.
+func (pg *PokerGame) isKnown(addr string) bool {
+	return !containsFaceDown(pg.players[addr].HoleCards)
+}
+
+func (pg *PokerGame) handOf(addr string) deck.Hand {
+	player := pg.players[addr]
+	cards := make([]deck.Card, 0, len(player.HoleCards)+len(pg.communityCards))
+	cards = append(cards, player.HoleCards...)
+	cards = append(cards, pg.communityCards...)
+
+	return deck.EvaluateHand(cards)
+}
+
+// isBeaten reports whether every pot the player is eligible for contains a
+// shown hand that is better than the player's hand. Hands shown that are
+// not turned up yet don't count.
+func (pg *PokerGame) isBeaten(addr string) bool {
+	hand := pg.handOf(addr)
+
+	for _, pot := range pg.pot {
+		if !containsAddr(pot.Players, addr) {
+			continue
+		}
+
+		beaten := false
+		for _, other := range pot.Players {
+			if other == addr || !pg.hasShown(other) || !pg.isKnown(other) {
+				continue
+			}
+			if deck.CompareHands(pg.handOf(other), hand) > 0 {
+				beaten = true
+				break
+			}
+		}
+		if !beaten {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (pg *PokerGame) determineWinner(potIndex int, pot Pot) error {
+	// Find players that are still contesting this pot
+	activePlayers := make([]string, 0)
+	for _, addr := range pot.Players {
+		if pg.hasShown(addr) {
+			activePlayers = append(activePlayers, addr)
+		}
+	}
+
+	if len(activePlayers) == 0 {
+		return nil
+	}
+
+	if len(activePlayers) == 1 {
+		// Last player standing wins
+		return pg.record(EventPotAwarded{Pot: potIndex, Addr: activePlayers[0], Amount: pot.Amount})
+	}
+
+	// Evaluate hands for all active players
+	playerHands := make(map[string]deck.Hand)
+	for _, addr := range activePlayers {
+		playerHands[addr] = pg.handOf(addr)
+	}
+
+	// Find winner(s)
+	winners := make([]string, 0)
+	var bestHand deck.Hand
+
+	for _, addr := range activePlayers {
+		hand := playerHands[addr]
+		if len(winners) == 0 {
+			winners = append(winners, addr)
+			bestHand = hand
+		} else {
+			comparison := deck.CompareHands(hand, bestHand)
+			if comparison > 0 {
+				// New winner
+				winners = winners[:0]
+				winners = append(winners, addr)
+				bestHand = hand
+			} else if comparison == 0 {
+				// Tie
+				winners = append(winners, addr)
+			}
+		}
+	}
+
+	return pg.splitPot(potIndex, pot.Amount, winners)
+}
+
+// splitPot divides the amount evenly among the winners. Odd chips that
+// cannot be split go one by one to the winners in orderFromButton order, so
+// every peer ends up with the exact same stacks.
+func (pg *PokerGame) splitPot(potIndex int, amount int, winners []string) error {
+	if len(winners) == 0 {
+		return nil
+	}
+
+	winners = pg.orderFromButton(winners)
+	splitAmount := amount / len(winners)
+	remainder := amount % len(winners)
+
+	for i, winner := range winners {
+		share := splitAmount
+		if i < remainder {
+			share++
+		}
+		if err := pg.record(EventPotAwarded{Pot: potIndex, Addr: winner, Amount: share}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// orderFromButton returns the given players sorted by seat, starting with
+// the first seat left of the button.
+func (pg *PokerGame) orderFromButton(addrs []string) []string {
+	playerAddrs := pg.seatOrder()
+	ordered := make([]string, 0, len(addrs))
+	for i := 1; i <= len(playerAddrs); i++ {
+		addr := playerAddrs[(pg.dealerPos+i)%len(playerAddrs)]
+		if containsAddr(addrs, addr) {
+			ordered = append(ordered, addr)
+		}
+	}
+
+	return ordered
+}
+
+func containsCard(cards []deck.Card, card deck.Card) bool {
+	for _, c := range cards {
+		if c == card {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAddr(addrs []string, addr string) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
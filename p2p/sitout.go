@@ -0,0 +1,170 @@
+package p2p
+
+import "fmt"
+
+// SitOut takes the player out of play. During a hand the player sits out
+// once the hand is over. With nextBigBlind set the player keeps playing
+// until it is their turn to pay the big blind.
+func (pg *PokerGame) SitOut(addr string, nextBigBlind bool) error {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	player, exists := pg.players[addr]
+	if !exists {
+		return fmt.Errorf("player %s not found", addr)
+	}
+
+	if player.SittingOut && !player.WaitForBigBlind && !player.SitInNextHand {
+		return fmt.Errorf("player %s is already sitting out", addr)
+	}
+
+	if !pg.gameStarted && !nextBigBlind {
+		return pg.record(EventSatOut{Addr: addr})
+	}
+
+	return pg.record(EventSitOutRequested{Addr: addr, NextBigBlind: nextBigBlind})
+}
+
+// SitIn returns the player to play. Players that missed a hand post a big
+// blind at the next hand, unless they choose to wait until the big blind
+// reaches them. SitIn also cancels a pending sit out.
+func (pg *PokerGame) SitIn(addr string, waitForBigBlind bool) error {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	player, exists := pg.players[addr]
+	if !exists {
+		return fmt.Errorf("player %s not found", addr)
+	}
+
+	if player.Stack == 0 {
+		return fmt.Errorf("player %s is busted and has to rebuy", addr)
+	}
+
+	if !player.SittingOut {
+		if !player.SitOutNextHand && !player.SitOutNextBigBlind {
+			return fmt.Errorf("player %s is not sitting out", addr)
+		}
+		return pg.record(EventSitInRequested{Addr: addr})
+	}
+
+	if !pg.gameStarted && !waitForBigBlind {
+		return pg.record(EventSatIn{Addr: addr, PostBigBlind: pg.handNumber >= player.SatOutHand})
+	}
+
+	return pg.record(EventSitInRequested{Addr: addr, WaitForBigBlind: waitForBigBlind})
+}
+
+// prepareSeats sits players out and in before the next hand is dealt.
+func (pg *PokerGame) prepareSeats() error {
+	for _, addr := range pg.allSeats() {
+		player := pg.players[addr]
+		switch {
+		case player.Stack == 0 && (!player.SittingOut || player.SitInNextHand || player.WaitForBigBlind):
+			// Busted players sit out until they rebuy.
+			if err := pg.record(EventSatOut{Addr: addr}); err != nil {
+				return err
+			}
+		case player.SitOutNextHand:
+			if err := pg.record(EventSatOut{Addr: addr}); err != nil {
+				return err
+			}
+		case player.SitInNextHand:
+			if err := pg.record(EventSatIn{Addr: addr, PostBigBlind: pg.handNumber >= player.SatOutHand}); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Players that wait for the big blind are dealt in when it reaches
+	// them, players that don't want to pay it sit out. Either changes who
+	// pays the big blind, so this runs until nothing changes.
+	for {
+		playerAddrs := pg.seatOrder()
+		if len(playerAddrs) < 2 {
+			// Without a game going on there is no big blind to wait for.
+			for _, addr := range pg.allSeats() {
+				if pg.players[addr].WaitForBigBlind {
+					if err := pg.record(EventSatIn{Addr: addr}); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}
+
+		bigBlind := pg.dueBigBlind(playerAddrs)
+
+		switch {
+		case bigBlind.SitOutNextBigBlind:
+			if err := pg.record(EventSatOut{Addr: bigBlind.Addr}); err != nil {
+				return err
+			}
+		case bigBlind.WaitForBigBlind:
+			return pg.record(EventSatIn{Addr: bigBlind.Addr})
+		default:
+			return nil
+		}
+	}
+}
+
+// nextButton returns the index in playerAddrs of the button of the next
+// hand, the first player seated after the last button.
+func (pg *PokerGame) nextButton(playerAddrs []string) int {
+	if pg.buttonSeat < 0 {
+		return (pg.dealerPos + 1) % len(playerAddrs)
+	}
+
+	for i, addr := range playerAddrs {
+		if pg.players[addr].Position > pg.buttonSeat {
+			return i
+		}
+	}
+
+	return 0
+}
+
+// NextButton returns the player that gets the button at the next hand and
+// the number of that hand. The button moves to the first player seated
+// after the last one, so players joining or leaving don't make it skip or
+// repeat anybody.
+func (pg *PokerGame) NextButton() (string, int) {
+	pg.mu.RLock()
+	defer pg.mu.RUnlock()
+
+	playerAddrs := pg.seatOrder()
+	if len(playerAddrs) < 2 {
+		return "", pg.handNumber + 1
+	}
+
+	return playerAddrs[pg.nextButton(playerAddrs)], pg.handNumber + 1
+}
+
+// dueBigBlind returns the player that pays the big blind when the next
+// hand is dealt to playerAddrs.
+func (pg *PokerGame) dueBigBlind(playerAddrs []string) *PlayerState {
+	button := pg.nextButton(playerAddrs)
+	smallBlind := pg.players[playerAddrs[(button+1)%len(playerAddrs)]]
+	return pg.nextBigBlind(smallBlind.Position)
+}
+
+// nextBigBlind returns the first player after the small blind that is in
+// play or waiting for the big blind.
+func (pg *PokerGame) nextBigBlind(smallBlindSeat int) *PlayerState {
+	seats := pg.allSeats()
+	start := 0
+	for i, addr := range seats {
+		if pg.players[addr].Position == smallBlindSeat {
+			start = i
+		}
+	}
+
+	for i := 1; i <= len(seats); i++ {
+		player := pg.players[seats[(start+i)%len(seats)]]
+		if !player.SittingOut || player.WaitForBigBlind {
+			return player
+		}
+	}
+
+	return pg.players[seats[start]]
+}
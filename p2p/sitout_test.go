@@ -0,0 +1,149 @@
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func foldToBigBlind(t *testing.T, game *PokerGame) {
+	for game.gameStarted {
+		assert.Nil(t, game.PlayerAction(game.actionOn, PlayerActionFold, 0))
+	}
+}
+
+func TestSitOutAndPostBigBlindOnReturn(t *testing.T) {
+	// [:1] [:2 D] [:3 SB] [:4 BB]
+	game := newTestGame(t, 4)
+
+	assert.Nil(t, game.SitOut(":1", false))
+	assert.Equal(t, "NEXT_HAND", game.SnapshotFor("").Players[":1"].SitOut)
+	assert.False(t, game.players[":1"].SittingOut)
+	foldToBigBlind(t, game)
+
+	// [:1 out] [:2 BB] [:3 D] [:4 SB]
+	assert.Nil(t, game.StartNewHand())
+	assert.True(t, game.players[":1"].SittingOut)
+	assert.Empty(t, game.players[":1"].HoleCards)
+	assert.Equal(t, []string{":2", ":3", ":4"}, game.seatOrder())
+	assert.True(t, game.players[":3"].IsDealer)
+	assert.True(t, game.players[":2"].IsBigBlind)
+	assert.NotNil(t, game.SitOut(":1", false))
+	foldToBigBlind(t, game)
+
+	// [:1 out] [:2 SB] [:3 BB] [:4 D]
+	assert.Nil(t, game.StartNewHand())
+	foldToBigBlind(t, game)
+	assert.Nil(t, game.SitIn(":1", false))
+	assert.True(t, game.players[":1"].PostBigBlind)
+
+	// [:1 D] [:2 SB] [:3 BB] [:4], :1 posts a big blind on the button.
+	assert.Nil(t, game.StartNewHand())
+	assert.True(t, game.players[":1"].IsDealer)
+	assert.Equal(t, 20, game.players[":1"].Bet)
+	assert.Equal(t, ":4", game.actionOn)
+	assert.Nil(t, game.PlayerAction(":4", PlayerActionFold, 0))
+	assert.Equal(t, []LegalAction{{Action: "FOLD"}, {Action: "CHECK"}, {Action: "RAISE", Min: 20, Max: game.players[":1"].Stack}}, game.LegalActions(":1"))
+	foldToBigBlind(t, game)
+	assert.Equal(t, 4, game.handNumber)
+
+	rec, err := game.HandRecord(4)
+	assert.Nil(t, err)
+	assert.True(t, rec.Seats[0].PostsBigBlind)
+	result, err := ReplayHand(rec)
+	assert.Nil(t, err)
+	assert.False(t, result.Diverged(), result.Divergences)
+}
+
+func TestSitOutNextBigBlindAndWaitForBigBlind(t *testing.T) {
+	// [:1] [:2 D] [:3 SB] [:4 BB]
+	game := newTestGame(t, 4)
+	assert.Nil(t, game.SitOut(":1", true))
+	foldToBigBlind(t, game)
+
+	// :1 would be the big blind and sits out instead.
+	assert.Nil(t, game.StartNewHand())
+	assert.True(t, game.players[":1"].SittingOut)
+	assert.True(t, game.players[":2"].IsBigBlind)
+	foldToBigBlind(t, game)
+
+	assert.Nil(t, game.SitIn(":1", true))
+	assert.Equal(t, "WAIT_FOR_BIG_BLIND", game.SnapshotFor("").Players[":1"].SitOut)
+	for i := 0; i < 2; i++ {
+		assert.Nil(t, game.StartNewHand())
+		assert.True(t, game.players[":1"].SittingOut)
+		foldToBigBlind(t, game)
+	}
+
+	// The big blind reached :1, who plays again without an extra blind.
+	assert.Nil(t, game.StartNewHand())
+	assert.False(t, game.players[":1"].SittingOut)
+	assert.True(t, game.players[":1"].IsBigBlind)
+	assert.Equal(t, 20, game.players[":1"].Bet)
+}
+
+func TestSitOutAfterTimeOuts(t *testing.T) {
+	game := newTimedGame(t, TimerConfig{ActionTimeout: 10 * time.Millisecond, SitOutAfterTimeOuts: 1})
+
+	assert.Eventually(t, func() bool {
+		return !game.SnapshotFor("").GameStarted
+	}, time.Second, 5*time.Millisecond)
+
+	// :2 timed out during the hand and sits out after it, :3 timed out
+	// with the last action of the hand and sits out right away.
+	assert.Equal(t, "NEXT_HAND", game.SnapshotFor("").Players[":2"].SitOut)
+	assert.True(t, game.SnapshotFor("").Players[":3"].SittingOut)
+
+	assert.NotNil(t, game.StartNewHand())
+	assert.True(t, game.players[":2"].SittingOut)
+}
+
+func TestJoinDuringHandWaitsForBigBlind(t *testing.T) {
+	game := newTestGame(t, 3)
+	assert.Nil(t, game.AddPlayer(":4", 1000, 3))
+
+	player := game.SnapshotFor("").Players[":4"]
+	assert.True(t, player.SittingOut)
+	assert.Equal(t, "WAIT_FOR_BIG_BLIND", player.SitOut)
+	assert.Len(t, game.seatOrder(), 3)
+}
+
+func TestSitOutKeepsChipsInPlay(t *testing.T) {
+	// [:1] [:2 D] [:3 SB] [:4 BB]
+	game := newTestGame(t, 4)
+	assert.Nil(t, game.SitOut(":1", false))
+	foldToBigBlind(t, game)
+
+	// The pots of a hand that ended are already paid out.
+	totalChips := func() int {
+		total := 0
+		for _, player := range game.players {
+			total += player.Stack
+		}
+		for _, pot := range game.pot {
+			if game.gameStarted {
+				total += pot.Amount
+			}
+		}
+		return total
+	}
+
+	// [:1 out] [:2 BB] [:3 D] [:4 SB], the big blind wins when the others
+	// fold.
+	assert.Nil(t, game.StartNewHand())
+	stack := game.players[":2"].Stack
+	foldToBigBlind(t, game)
+	assert.Equal(t, stack+30, game.players[":2"].Stack)
+	assert.Equal(t, 1000, game.players[":1"].Stack)
+	assert.Equal(t, 4000, totalChips())
+
+	// [:1 out] [:2 SB] [:3 BB] [:4 D], the betting round ends once the
+	// players dealt in called.
+	assert.Nil(t, game.StartNewHand())
+	assert.Nil(t, game.PlayerAction(":4", PlayerActionCall, 0))
+	assert.Nil(t, game.PlayerAction(":2", PlayerActionCall, 0))
+	assert.Nil(t, game.PlayerAction(":3", PlayerActionCheck, 0))
+	assert.Equal(t, Flop, game.currentRound)
+	assert.Equal(t, 4000, totalChips())
+}
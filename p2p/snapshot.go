@@ -0,0 +1,194 @@
+package p2p
+
+import (
+	"time"
+
+	"github.com/koshiq/ggpoker/deck"
+)
+
+// GameSnapshot is the state of a PokerGame as seen by a single viewer.
+// Hole cards of other players are only included once they are shown.
+type GameSnapshot struct {
+	HandNumber     int         `json:"handNumber"`
+	GameStarted    bool        `json:"gameStarted"`
+	CurrentRound   string      `json:"currentRound"`
+	CommunityCards []deck.Card `json:"communityCards"`
+	Pots           []Pot       `json:"pots"`
+	Rake           int         `json:"rake"`
+	SmallBlind     int         `json:"smallBlind"`
+	BigBlind       int         `json:"bigBlind"`
+	Ante           int         `json:"ante"`
+	CurrentBet     int         `json:"currentBet"`
+	MinRaise       int         `json:"minRaise"`
+	ActionOn       string      `json:"actionOn"`
+	// ActionDeadline is when the time of actionOn runs out, after that
+	// the time bank of the player is used.
+	ActionDeadline *time.Time                `json:"actionDeadline,omitempty"`
+	LegalActions   []LegalAction             `json:"legalActions"`
+	ShowdownOrder  []string                  `json:"showdownOrder"`
+	Players        map[string]PlayerSnapshot `json:"players"`
+}
+
+type PlayerSnapshot struct {
+	Addr         string      `json:"addr"`
+	Position     int         `json:"position"`
+	Stack        int         `json:"stack"`
+	Bet          int         `json:"bet"`
+	TotalBet     int         `json:"totalBet"`
+	Folded       bool        `json:"folded"`
+	AllIn        bool        `json:"allIn"`
+	Mucked       bool        `json:"mucked"`
+	LastAction   string      `json:"lastAction"`
+	IsDealer     bool        `json:"isDealer"`
+	IsSmallBlind bool        `json:"isSmallBlind"`
+	IsBigBlind   bool        `json:"isBigBlind"`
+	HoleCards    []deck.Card `json:"holeCards"`
+	TimeBankMs   int64       `json:"timeBankMs"`
+	SittingOut   bool        `json:"sittingOut"`
+	Bounty       int         `json:"bounty,omitempty"`
+	BountiesWon  int         `json:"bountiesWon,omitempty"`
+	// SitOut is the pending sit out or sit in choice of the player, one of
+	// NEXT_HAND, NEXT_BIG_BLIND, SIT_IN or WAIT_FOR_BIG_BLIND.
+	SitOut string `json:"sitOut,omitempty"`
+}
+
+// LegalAction is an action the viewer can take right now. For bets and
+// raises Min and Max are the bounds of the amount passed to PlayerAction,
+// for calls Min is the amount to call.
+type LegalAction struct {
+	Action string `json:"action"`
+	Min    int    `json:"min,omitempty"`
+	Max    int    `json:"max,omitempty"`
+}
+
+// SnapshotFor returns the state of the game as seen by viewerAddr. Pass an
+// empty address to get the view of a spectator.
+func (pg *PokerGame) SnapshotFor(viewerAddr string) GameSnapshot {
+	pg.mu.RLock()
+	defer pg.mu.RUnlock()
+
+	return pg.snapshot(viewerAddr, false)
+}
+
+// snapshot builds the view of viewerAddr. With revealAll set every hole
+// card is included, which is only meant for debugging and replays.
+func (pg *PokerGame) snapshot(viewerAddr string, revealAll bool) GameSnapshot {
+	pots := make([]Pot, len(pg.pot))
+	for i, pot := range pg.pot {
+		pots[i] = Pot{Amount: pot.Amount, Players: append([]string{}, pot.Players...)}
+	}
+
+	snapshot := GameSnapshot{
+		HandNumber:     pg.handNumber,
+		GameStarted:    pg.gameStarted,
+		CurrentRound:   pg.currentRound.String(),
+		CommunityCards: append([]deck.Card{}, pg.communityCards...),
+		Pots:           pots,
+		SmallBlind:     pg.smallBlind,
+		BigBlind:       pg.bigBlind,
+		Ante:           pg.ante,
+		CurrentBet:     pg.currentBet,
+		MinRaise:       pg.minRaise,
+		ActionOn:       pg.actionOn,
+		LegalActions:   pg.legalActions(viewerAddr),
+		ShowdownOrder:  append([]string{}, pg.showdownOrder...),
+		Players:        make(map[string]PlayerSnapshot),
+	}
+	for _, rake := range pg.potRake {
+		snapshot.Rake += rake
+	}
+	if !pg.actionStarted.IsZero() {
+		deadline := pg.actionStarted.Add(pg.actionTimeout)
+		snapshot.ActionDeadline = &deadline
+	}
+
+	for addr, player := range pg.players {
+		holeCards := player.ShownCards
+		if addr == viewerAddr || revealAll {
+			holeCards = player.HoleCards
+		}
+
+		snapshot.Players[addr] = PlayerSnapshot{
+			Addr:         addr,
+			Position:     player.Position,
+			Stack:        player.Stack,
+			Bet:          player.Bet,
+			TotalBet:     player.TotalBet,
+			Folded:       player.Folded,
+			AllIn:        player.AllIn,
+			Mucked:       player.Mucked,
+			LastAction:   player.LastAction.String(),
+			IsDealer:     player.IsDealer,
+			IsSmallBlind: player.IsSmallBlind,
+			IsBigBlind:   player.IsBigBlind,
+			HoleCards:    append([]deck.Card{}, holeCards...),
+			TimeBankMs:   player.TimeBank.Milliseconds(),
+			SittingOut:   player.SittingOut,
+			SitOut:       pendingSitOut(player),
+			Bounty:       player.Bounty,
+			BountiesWon:  player.BountiesWon,
+		}
+	}
+
+	return snapshot
+}
+
+// LegalActions returns the actions the given player can take right now.
+func (pg *PokerGame) LegalActions(addr string) []LegalAction {
+	pg.mu.RLock()
+	defer pg.mu.RUnlock()
+
+	return pg.legalActions(addr)
+}
+
+func (pg *PokerGame) legalActions(addr string) []LegalAction {
+	actions := []LegalAction{}
+
+	player, ok := pg.players[addr]
+	if !ok || !pg.gameStarted || pg.actionOn != addr {
+		return actions
+	}
+
+	if pg.currentRound == Showdown {
+		actions = append(actions, LegalAction{Action: "SHOW"})
+		if pg.mayMuck(addr) {
+			actions = append(actions, LegalAction{Action: "MUCK"})
+		}
+		return actions
+	}
+
+	toCall := pg.currentBet - player.Bet
+	actions = append(actions, LegalAction{Action: PlayerActionFold.String()})
+	if toCall == 0 {
+		actions = append(actions, LegalAction{Action: PlayerActionCheck.String()})
+	} else {
+		actions = append(actions, LegalAction{Action: PlayerActionCall.String(), Min: min(toCall, player.Stack)})
+	}
+
+	if player.Stack > toCall {
+		maxAmount := player.Stack - toCall
+		minAmount := min(pg.minRaise, maxAmount)
+		action := PlayerActionRaise
+		if pg.currentBet == 0 {
+			action = PlayerActionBet
+		}
+		actions = append(actions, LegalAction{Action: action.String(), Min: minAmount, Max: maxAmount})
+	}
+
+	return actions
+}
+
+func pendingSitOut(player *PlayerState) string {
+	switch {
+	case player.SitOutNextHand:
+		return "NEXT_HAND"
+	case player.SitOutNextBigBlind:
+		return "NEXT_BIG_BLIND"
+	case player.SitInNextHand:
+		return "SIT_IN"
+	case player.WaitForBigBlind:
+		return "WAIT_FOR_BIG_BLIND"
+	default:
+		return ""
+	}
+}
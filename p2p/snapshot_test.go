@@ -0,0 +1,65 @@
+package p2p
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotForHidesOpponentCards(t *testing.T) {
+	// [:1 BB] [:2 D] [:3 SB]
+	game := newTestGame(t, 3)
+
+	snapshot := game.SnapshotFor(":2")
+	assert.Equal(t, game.players[":2"].HoleCards, snapshot.Players[":2"].HoleCards)
+	assert.Len(t, snapshot.Players[":1"].HoleCards, 0)
+	assert.Len(t, snapshot.Players[":3"].HoleCards, 0)
+
+	assert.Equal(t, ":2", snapshot.ActionOn)
+	assert.Equal(t, 1, snapshot.HandNumber)
+	assert.Equal(t, []LegalAction{
+		{Action: "FOLD"},
+		{Action: "CALL", Min: 20},
+		{Action: "RAISE", Min: 20, Max: 980},
+	}, snapshot.LegalActions)
+
+	// Players that are not on the move have nothing to do.
+	assert.Len(t, game.SnapshotFor(":1").LegalActions, 0)
+
+	b, err := json.Marshal(game.SnapshotFor(""))
+	assert.Nil(t, err)
+	fields := map[string]any{}
+	assert.Nil(t, json.Unmarshal(b, &fields))
+	for _, field := range []string{"handNumber", "currentRound", "communityCards", "pots", "actionOn", "legalActions", "players"} {
+		assert.Contains(t, fields, field)
+	}
+	assert.Equal(t, []any{}, fields["players"].(map[string]any)[":2"].(map[string]any)["holeCards"])
+}
+
+func TestAPIServerServesSnapshotOfPlayer(t *testing.T) {
+	game := newTestGame(t, 3)
+	api := NewAPIServer("", &GameState{listenAddr: ":2", game: game}, nil)
+
+	w := httptest.NewRecorder()
+	assert.Nil(t, api.handleGameState(w, httptest.NewRequest(http.MethodGet, "/state", nil)))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	snapshot := GameSnapshot{}
+	assert.Nil(t, json.NewDecoder(w.Body).Decode(&snapshot))
+	assert.Len(t, snapshot.Players[":2"].HoleCards, 2)
+	assert.Len(t, snapshot.Players[":1"].HoleCards, 0)
+
+	// Websocket clients get the same view pushed.
+	ch := make(chan any, 1)
+	api.clients[nil] = ch
+	api.PublishState(game.SnapshotFor(":2"))
+	b, err := json.Marshal(<-ch)
+	assert.Nil(t, err)
+	update := map[string]any{}
+	assert.Nil(t, json.Unmarshal(b, &update))
+	assert.Equal(t, "game_state", update["type"])
+	assert.Equal(t, ":2", update["data"].(map[string]any)["actionOn"])
+}
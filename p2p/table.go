@@ -11,6 +11,7 @@ type Player struct {
 	currentAction PlayerAction
 	gameStatus    GameStatus
 	tablePos      int
+	sittingOut    bool
 }
 
 func NewPlayer(addr string) *Player {
@@ -83,8 +84,8 @@ func (t *Table) GetPlayerBefore(addr string) (*Player, error) {
 		}
 
 		i--
-		if i <= 0 {
-			i = t.maxSeats
+		if i < 0 {
+			i = t.maxSeats - 1
 		}
 	}
 }
@@ -174,6 +175,19 @@ func (t *Table) SetPlayerStatus(addr string, s GameStatus) {
 	p.gameStatus = s
 }
 
+func (t *Table) SetPlayerSittingOut(addr string, sittingOut bool) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	p, err := t.getPlayer(addr)
+	if err != nil {
+		return err
+	}
+	p.sittingOut = sittingOut
+
+	return nil
+}
+
 func (t *Table) AddPlayerOnPosition(addr string, pos int) error {
 	t.lock.Lock()
 	defer t.lock.Unlock()
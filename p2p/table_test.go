@@ -22,6 +22,14 @@ func TestTableGetPlayerBefore(t *testing.T) {
 	prevPlayer, err = table.GetPlayerBefore("1")
 	assert.Nil(t, err)
 	assert.Equal(t, prevPlayer.addr, "2")
+
+	// The first seat is before a player further down the table.
+	table = NewTable(maxSeats)
+	assert.Nil(t, table.AddPlayerOnPosition("1", 0))
+	assert.Nil(t, table.AddPlayerOnPosition("3", 2))
+	prevPlayer, err = table.GetPlayerBefore("3")
+	assert.Nil(t, err)
+	assert.Equal(t, prevPlayer.addr, "1")
 }
 
 func TestTableGetPlayerAfter(t *testing.T) {
@@ -1,8 +1,13 @@
 package p2p
 
 import (
-	"encoding/gob"
+	"context"
+	"crypto/ed25519"
+	"crypto/tls"
+	"errors"
+	"io"
 	"net"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -12,37 +17,109 @@ type NetAddr string
 func (n NetAddr) String() string  { return string(n) }
 func (n NetAddr) Network() string { return "tcp" }
 
+// MessageConn sends and receives whole messages, over a framed TCP
+// connection or a gRPC stream.
+type MessageConn interface {
+	Send(msg *Message) error
+	Receive() (*Message, error)
+	RemoteAddr() net.Addr
+	Close() error
+}
+
+// tcpConn is a TCP connection carrying frames, see Codec.
+type tcpConn struct {
+	net.Conn
+	codec *Codec
+}
+
+func newTCPConn(conn net.Conn) MessageConn {
+	return &tcpConn{
+		Conn:  conn,
+		codec: NewCodec(conn, defaultMaxFrameSize),
+	}
+}
+
+func (c *tcpConn) Send(msg *Message) error    { return c.codec.Encode(msg) }
+func (c *tcpConn) Receive() (*Message, error) { return c.codec.Decode() }
+
+// Authenticated reports whether the connection is a TLS one.
+func (c *tcpConn) Authenticated() bool {
+	_, ok := c.Conn.(*tls.Conn)
+	return ok
+}
+
+// PeerKey returns the identity key the peer authenticated with over TLS.
+func (c *tcpConn) PeerKey() ed25519.PublicKey {
+	tlsConn, ok := c.Conn.(*tls.Conn)
+	if !ok {
+		return nil
+	}
+	return certificateKey(tlsConn.ConnectionState())
+}
+
 type Peer struct {
-	conn       net.Conn
-	outbound   bool
+	conn     MessageConn
+	outbound bool
+	// id is the player ID of the peer and listenAddr is where it listens,
+	// both are learned in the handshake.
+	id         string
 	listenAddr string
+	// handshake receives the result of the handshake with the peer.
+	handshake chan error
+}
+
+func NewPeer(conn MessageConn, outbound bool) *Peer {
+	return &Peer{
+		conn:      conn,
+		outbound:  outbound,
+		handshake: make(chan error, 1),
+	}
 }
 
-func (p *Peer) Send(b []byte) error {
-	_, err := p.conn.Write(b)
-	return err
+// Send writes the message to the peer. The connection is closed when the
+// message can't be written, the stream is broken after that.
+func (p *Peer) Send(msg *Message) error {
+	if err := p.conn.Send(msg); err != nil {
+		p.conn.Close()
+		return err
+	}
+	return nil
 }
 
-func (p *Peer) ReadLoop(msgch chan *Message) {
+// ReadLoop reads messages from the peer until the connection is closed or
+// broken, then the peer is passed on to delch to be unregistered.
+func (p *Peer) ReadLoop(msgch chan *Message, delch chan *Peer) {
 	for {
-		msg := new(Message)
-		if err := gob.NewDecoder(p.conn).Decode(msg); err != nil {
-			logrus.Errorf("decode message error: %s", err)
+		msg, err := p.conn.Receive()
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				logrus.Errorf("decode message error: %s", err)
+			}
 			break
 		}
 
+		// Every message is from the player on the other end, others can't
+		// be passed on in their name.
+		if msg.From != p.id {
+			logrus.WithFields(logrus.Fields{
+				"peer": p.id,
+				"from": msg.From,
+			}).Warn("dropping message sent in the name of another player")
+			continue
+		}
+
 		msgch <- msg
 	}
 
-	// TODO(@anthdm): unregister this peer!!!
 	p.conn.Close()
+	delch <- p
 }
 
 type TCPTransport struct {
 	listenAddr string
 	listener   net.Listener
-	AddPeer    chan *Peer
-	DelPeer    chan *Peer
+	// tlsConfig encrypts and authenticates the connections when set.
+	tlsConfig *tls.Config
 }
 
 func NewTCPTransport(addr string) *TCPTransport {
@@ -51,7 +128,16 @@ func NewTCPTransport(addr string) *TCPTransport {
 	}
 }
 
-func (t *TCPTransport) ListenAndAccept() error {
+// NewTLSTransport creates a TCP transport of which the connections are
+// encrypted and authenticated with cfg, see NewTLSConfig.
+func NewTLSTransport(addr string, cfg *tls.Config) *TCPTransport {
+	return &TCPTransport{
+		listenAddr: addr,
+		tlsConfig:  cfg,
+	}
+}
+
+func (t *TCPTransport) Listen(addPeer chan<- *Peer) error {
 	ln, err := net.Listen("tcp", t.listenAddr)
 	if err != nil {
 		return err
@@ -59,18 +145,68 @@ func (t *TCPTransport) ListenAndAccept() error {
 
 	t.listener = ln
 
-	for {
-		conn, err := ln.Accept()
-		if err != nil {
-			logrus.Error(err)
-			continue
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			if err != nil {
+				logrus.Error(err)
+				continue
+			}
+
+			if t.tlsConfig == nil {
+				addPeer <- NewPeer(newTCPConn(conn), false)
+				continue
+			}
+			go t.authenticate(conn, addPeer)
 		}
+	}()
+
+	return nil
+}
 
-		peer := &Peer{
-			conn:     conn,
-			outbound: false,
+// authenticate runs the TLS handshake with a connecting peer. Peers that
+// fail it are hung up on before they get to send anything else.
+func (t *TCPTransport) authenticate(conn net.Conn, addPeer chan<- *Peer) {
+	tlsConn := tls.Server(conn, t.tlsConfig)
+
+	ctx, cancel := context.WithTimeout(context.Background(), tlsHandshakeTimeout)
+	defer cancel()
+
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"we":   t.listenAddr,
+			"addr": conn.RemoteAddr(),
+		}).Warnf("refused peer: %s", err)
+		conn.Close()
+		return
+	}
+
+	addPeer <- NewPeer(newTCPConn(tlsConn), false)
+}
+
+func (t *TCPTransport) Dial(addr string) (MessageConn, error) {
+	dialer := &net.Dialer{Timeout: 1 * time.Second}
+	if t.tlsConfig == nil {
+		conn, err := dialer.Dial("tcp", addr)
+		if err != nil {
+			return nil, err
 		}
+		return newTCPConn(conn), nil
+	}
+
+	conn, err := tls.DialWithDialer(dialer, "tcp", addr, t.tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	return newTCPConn(conn), nil
+}
 
-		t.AddPeer <- peer
+func (t *TCPTransport) Close() error {
+	if t.listener == nil {
+		return nil
 	}
+	return t.listener.Close()
 }
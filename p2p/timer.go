@@ -0,0 +1,258 @@
+package p2p
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TimerConfig holds the action timeouts of a table. A player has Timeout
+// to act, after that the time bank is used. When the time bank runs out
+// as well the player checks if possible, otherwise folds. At showdown the
+// hand is mucked when it is beaten and shown otherwise.
+type TimerConfig struct {
+	ActionTimeout time.Duration
+	// TimeBank is the time bank players start with, it is never refilled
+	// above this.
+	TimeBank time.Duration
+	// TimeBankRefill is added to the time bank of every player at the start
+	// of each hand.
+	TimeBankRefill time.Duration
+	// SitOutAfterTimeOuts sits a player out after this many consecutive
+	// timeouts, zero never does.
+	SitOutAfterTimeOuts int
+}
+
+type actionTimer struct {
+	addr     string
+	started  time.Time
+	timeout  time.Duration
+	timeBank time.Duration
+	t        *time.Timer
+}
+
+// SetTimers enables action timeouts, a zero ActionTimeout disables them.
+// The timers apply from the next player to act on.
+func (pg *PokerGame) SetTimers(cfg TimerConfig) {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	pg.timers = cfg
+}
+
+// startTimer starts the timer of the player that is on the move, if it is
+// not running already. Disconnected players act right away.
+func (pg *PokerGame) startTimer() error {
+	if err := pg.actForDisconnected(); err != nil {
+		return err
+	}
+
+	if pg.timers.ActionTimeout <= 0 || !pg.gameStarted || pg.actionOn == "" {
+		return nil
+	}
+
+	if pg.timer != nil {
+		if pg.timer.addr == pg.actionOn {
+			return nil
+		}
+		pg.timer.t.Stop()
+		pg.timer = nil
+	}
+
+	player := pg.players[pg.actionOn]
+	ev := EventTimerStarted{
+		Addr:     player.Addr,
+		Started:  time.Now(),
+		Timeout:  pg.timers.ActionTimeout,
+		TimeBank: player.TimeBank,
+	}
+	if err := pg.record(ev); err != nil {
+		return err
+	}
+
+	timer := &actionTimer{
+		addr:     ev.Addr,
+		started:  ev.Started,
+		timeout:  ev.Timeout,
+		timeBank: ev.TimeBank,
+	}
+	timer.t = time.AfterFunc(ev.Timeout+ev.TimeBank, func() {
+		pg.expireTimer(timer)
+	})
+	pg.timer = timer
+
+	return nil
+}
+
+// stopTimer stops the timer of the given player once that player acted.
+// Time spent beyond the timeout is taken from the time bank.
+func (pg *PokerGame) stopTimer(addr string, timedOut bool) error {
+	if pg.timer == nil || pg.timer.addr != addr {
+		return nil
+	}
+
+	timer := pg.timer
+	timer.t.Stop()
+	pg.timer = nil
+
+	used := timer.timeBank
+	if elapsed := time.Since(timer.started) - timer.timeout; !timedOut && elapsed < used {
+		used = max(elapsed, 0)
+	}
+
+	return pg.record(EventTimerStopped{
+		Addr:         addr,
+		TimeBankUsed: used,
+		TimedOut:     timedOut,
+	})
+}
+
+// StopTimer stops the timer of the player on the move with the time bank
+// used as the node of the player measured it, so every peer takes the same
+// time off the bank. The action of the player follows it.
+func (pg *PokerGame) StopTimer(addr string, used time.Duration) error {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	if !pg.gameStarted || pg.actionOn != addr {
+		return fmt.Errorf("player (%s) is not on the move", addr)
+	}
+	if pg.timer == nil || pg.timer.addr != addr {
+		return nil
+	}
+
+	timer := pg.timer
+	timer.t.Stop()
+	pg.timer = nil
+
+	if used < 0 {
+		used = 0
+	}
+	if used > timer.timeBank {
+		used = timer.timeBank
+	}
+
+	return pg.record(EventTimerStopped{
+		Addr:         addr,
+		TimeBankUsed: used,
+	})
+}
+
+// OnTimeout hands expired timers to h instead of acting on them. h is
+// called without the game locked and has to call TimeOut for the player.
+// Replicated games use it, so only the node of the player acts and the
+// action reaches every peer.
+func (pg *PokerGame) OnTimeout(h func(addr string)) {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	pg.onTimeout = h
+}
+
+// expireTimer acts on behalf of a player that ran out of time, unless a
+// timeout handler does.
+func (pg *PokerGame) expireTimer(timer *actionTimer) {
+	pg.mu.Lock()
+
+	// The player acted just before the timer fired.
+	if pg.timer != timer {
+		pg.mu.Unlock()
+		return
+	}
+
+	if h := pg.onTimeout; h != nil {
+		pg.mu.Unlock()
+		h(timer.addr)
+		return
+	}
+	defer pg.mu.Unlock()
+
+	if _, err := pg.timeOut(timer.addr, pg.mayMuckOnTimeOut(timer.addr)); err != nil {
+		logrus.Errorf("timeout action error for %s: %s", timer.addr, err)
+	}
+}
+
+// TimeOut plays for a player that ran out of time: the player checks if
+// possible and folds otherwise, at showdown a beaten hand is mucked and
+// any other shown. It returns the action taken, at showdown PlayerActionFold
+// for a hand mucked and PlayerActionNone for a hand shown.
+func (pg *PokerGame) TimeOut(addr string) (PlayerAction, error) {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	return pg.timeOut(addr, pg.mayMuckOnTimeOut(addr))
+}
+
+// TimedOut plays the timeout of a player like the node of the player did.
+// Only that node knows whether the hand is beaten at showdown, the hand is
+// mucked when it says so.
+func (pg *PokerGame) TimedOut(addr string, muck bool) (PlayerAction, error) {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+
+	return pg.timeOut(addr, muck)
+}
+
+func (pg *PokerGame) mayMuckOnTimeOut(addr string) bool {
+	return pg.gameStarted && pg.currentRound == Showdown && pg.mayMuck(addr)
+}
+
+func (pg *PokerGame) timeOut(addr string, muck bool) (PlayerAction, error) {
+	if !pg.gameStarted || pg.actionOn != addr {
+		return PlayerActionNone, fmt.Errorf("player (%s) is not on the move", addr)
+	}
+
+	// The whole time bank is used, whether our own timer ran or not, so
+	// every peer ends up with the same time banks.
+	if pg.timer != nil {
+		pg.timer.t.Stop()
+		pg.timer = nil
+	}
+	player := pg.players[addr]
+	if err := pg.record(EventTimerStopped{
+		Addr:         addr,
+		TimeBankUsed: player.TimeBank,
+		TimedOut:     true,
+	}); err != nil {
+		return PlayerActionNone, err
+	}
+
+	action := PlayerActionNone
+	var err error
+	switch {
+	case pg.currentRound == Showdown && muck:
+		action = PlayerActionFold
+		err = pg.muckHand(addr)
+	case pg.currentRound == Showdown:
+		err = pg.showHand(addr)
+	case player.Bet >= pg.currentBet:
+		action = PlayerActionCheck
+		err = pg.playerAction(addr, action, 0)
+	default:
+		action = PlayerActionFold
+		err = pg.playerAction(addr, action, 0)
+	}
+	if err != nil {
+		return PlayerActionNone, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"player": addr,
+		"hand":   pg.handNumber,
+	}).Info("player timed out")
+
+	if n := pg.timers.SitOutAfterTimeOuts; n > 0 && player.TimeOuts >= n && !player.SitOutNextHand {
+		var err error
+		if pg.gameStarted {
+			err = pg.record(EventSitOutRequested{Addr: addr})
+		} else {
+			err = pg.record(EventSatOut{Addr: addr})
+		}
+		if err != nil {
+			return action, err
+		}
+	}
+
+	return action, pg.startTimer()
+}
@@ -0,0 +1,100 @@
+package p2p
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTimedGame(t *testing.T, cfg TimerConfig) *PokerGame {
+	game := NewPokerGame(10, 20)
+	game.SetTimers(cfg)
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, game.AddPlayer(fmt.Sprintf(":%d", i+1), 1000, i))
+	}
+	assert.Nil(t, game.StartNewHandWithSeed(42))
+
+	return game
+}
+
+func TestTimerAutoFoldAndCheck(t *testing.T) {
+	// [:1 BB] [:2 D] [:3 SB]
+	game := newTimedGame(t, TimerConfig{ActionTimeout: 10 * time.Millisecond})
+
+	assert.NotNil(t, game.SnapshotFor("").ActionDeadline)
+
+	// :2 and :3 are facing the big blind and fold, :1 wins uncontested.
+	assert.Eventually(t, func() bool {
+		return !game.SnapshotFor("").GameStarted
+	}, time.Second, 5*time.Millisecond)
+
+	state := game.SnapshotFor("")
+	assert.Nil(t, state.ActionDeadline)
+	assert.Equal(t, "FOLD", state.Players[":2"].LastAction)
+	assert.Equal(t, "FOLD", state.Players[":3"].LastAction)
+	assert.Equal(t, 1010, state.Players[":1"].Stack)
+
+	timedOut := []string{}
+	for _, r := range game.Events() {
+		if e, ok := r.Event.(EventTimerStopped); ok && e.TimedOut {
+			timedOut = append(timedOut, e.Addr)
+		}
+	}
+	assert.Equal(t, []string{":2", ":3"}, timedOut)
+	assert.Equal(t, 1, game.players[":2"].TimeOuts)
+
+	// A player that can check checks instead of folding.
+	assert.Nil(t, game.StartNewHandWithSeed(43))
+	// [:1 SB] [:2 BB] [:3 D]
+	assert.Nil(t, game.PlayerAction(":3", PlayerActionCall, 0))
+	assert.Nil(t, game.PlayerAction(":1", PlayerActionCall, 0))
+	assert.Eventually(t, func() bool {
+		return game.SnapshotFor("").CurrentRound == Flop.String()
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, "CHECK", game.SnapshotFor("").Players[":2"].LastAction)
+}
+
+func TestTimerUsesTimeBank(t *testing.T) {
+	game := newTimedGame(t, TimerConfig{
+		ActionTimeout:  10 * time.Millisecond,
+		TimeBank:       time.Second,
+		TimeBankRefill: 100 * time.Millisecond,
+	})
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Nil(t, game.PlayerAction(":2", PlayerActionCall, 0))
+
+	bank := game.SnapshotFor("").Players[":2"].TimeBankMs
+	assert.Less(t, bank, int64(1000))
+	assert.Greater(t, bank, int64(500))
+	assert.Equal(t, 0, game.players[":2"].TimeOuts)
+
+	// Acting within the timeout leaves the time bank alone.
+	assert.Nil(t, game.PlayerAction(":3", PlayerActionCall, 0))
+	assert.Equal(t, int64(1000), game.SnapshotFor("").Players[":3"].TimeBankMs)
+
+	// The time bank is refilled up to its maximum at the next hand.
+	assert.Nil(t, game.PlayerAction(":1", PlayerActionFold, 0))
+	assert.Nil(t, game.PlayerAction(":3", PlayerActionFold, 0))
+	assert.Nil(t, game.StartNewHandWithSeed(43))
+	assert.Equal(t, int64(1000), game.SnapshotFor("").Players[":2"].TimeBankMs)
+}
+
+func TestStopTimerTakesReportedTimeBank(t *testing.T) {
+	// [:1 BB] [:2 D] [:3 SB]
+	game := newTimedGame(t, TimerConfig{ActionTimeout: time.Hour, TimeBank: 10 * time.Second})
+
+	assert.NotNil(t, game.StopTimer(":3", time.Second))
+	assert.Nil(t, game.StopTimer(":2", 3*time.Second))
+	assert.Equal(t, 7*time.Second, game.players[":2"].TimeBank)
+
+	// The action does not take any more time off the bank.
+	assert.Nil(t, game.PlayerAction(":2", PlayerActionCall, 0))
+	assert.Equal(t, 7*time.Second, game.players[":2"].TimeBank)
+
+	// No more than what is left of the bank is used.
+	assert.Nil(t, game.StopTimer(":3", time.Minute))
+	assert.Equal(t, time.Duration(0), game.players[":3"].TimeBank)
+}
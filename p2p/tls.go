@@ -0,0 +1,113 @@
+package p2p
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// tlsHandshakeTimeout is how long a peer has to authenticate.
+const tlsHandshakeTimeout = 5 * time.Second
+
+// certificateLifetime is how long the certificates we make for our identity
+// are valid. They are made again on every start.
+const certificateLifetime = 365 * 24 * time.Hour
+
+// NewTLSConfig makes the TLS config of a peer with the identity key. Peers
+// authenticate each other with self-signed certificates of their identity
+// keys, there is no certificate authority. When trusted is empty any peer
+// that holds the key of its certificate is accepted, trusting the key on
+// first use. Otherwise the key has to be one of trusted.
+func NewTLSConfig(identity ed25519.PrivateKey, trusted []ed25519.PublicKey) (*tls.Config, error) {
+	cert, err := selfSignedCertificate(identity)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS13,
+		// Both sides send a certificate, which verifyPeerCertificate checks
+		// in place of the usual chain verification.
+		ClientAuth:            tls.RequireAnyClientCert,
+		InsecureSkipVerify:    true,
+		VerifyPeerCertificate: verifyPeerCertificate(trusted),
+	}, nil
+}
+
+func selfSignedCertificate(identity ed25519.PrivateKey) (tls.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(certificateLifetime),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		// The certificate signs itself.
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, identity.Public(), identity)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: identity}, nil
+}
+
+// verifyPeerCertificate accepts a single certificate signed by its own
+// ed25519 key, which has to be trusted if any keys are. Without trusted keys
+// it only proves that the peer holds the key.
+func verifyPeerCertificate(trusted []ed25519.PublicKey) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) != 1 {
+			return fmt.Errorf("expected a single certificate, got %d", len(rawCerts))
+		}
+		cert, err := x509.ParseCertificate(rawCerts[0])
+		if err != nil {
+			return err
+		}
+
+		key, ok := cert.PublicKey.(ed25519.PublicKey)
+		if !ok {
+			return fmt.Errorf("certificate has no ed25519 key but %T", cert.PublicKey)
+		}
+		if err := cert.CheckSignatureFrom(cert); err != nil {
+			return fmt.Errorf("certificate is not self-signed: %s", err)
+		}
+		if now := time.Now(); now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+			return errors.New("certificate is expired or not yet valid")
+		}
+
+		if len(trusted) == 0 {
+			return nil
+		}
+		for _, k := range trusted {
+			if bytes.Equal(k, key) {
+				return nil
+			}
+		}
+		return fmt.Errorf("untrusted peer key %x", []byte(key))
+	}
+}
+
+// certificateKey returns the identity key of the certificate the peer of a
+// TLS connection authenticated with.
+func certificateKey(state tls.ConnectionState) ed25519.PublicKey {
+	if len(state.PeerCertificates) == 0 {
+		return nil
+	}
+	key, _ := state.PeerCertificates[0].PublicKey.(ed25519.PublicKey)
+	return key
+}
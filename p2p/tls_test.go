@@ -0,0 +1,173 @@
+package p2p
+
+import (
+	"crypto/ed25519"
+	"crypto/tls"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTLSConfig(t *testing.T, trusted ...ed25519.PublicKey) (*tls.Config, ed25519.PublicKey) {
+	pub, key, err := ed25519.GenerateKey(nil)
+	assert.Nil(t, err)
+	cfg, err := NewTLSConfig(key, trusted)
+	assert.Nil(t, err)
+
+	return cfg, pub
+}
+
+func freeAddr(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer ln.Close()
+
+	return ln.Addr().String()
+}
+
+func TestTLSTransportAuthenticatesPeers(t *testing.T) {
+	clientCfg, clientKey := newTestTLSConfig(t)
+	serverCfg, serverKey := newTestTLSConfig(t, clientKey)
+	clientCfg.VerifyPeerCertificate = verifyPeerCertificate([]ed25519.PublicKey{serverKey})
+
+	addr := freeAddr(t)
+	server := NewTLSTransport(addr, serverCfg)
+	addPeer := make(chan *Peer, 1)
+	assert.Nil(t, server.Listen(addPeer))
+	defer server.Close()
+
+	conn, err := NewTLSTransport(":3000", clientCfg).Dial(addr)
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	hs := NewMessage(":3000", Handshake{Version: "v1", ListenAddr: ":3000"})
+	assert.Nil(t, conn.Send(hs))
+	peer := <-addPeer
+	msg, err := peer.conn.Receive()
+	assert.Nil(t, err)
+	assert.Equal(t, hs, msg)
+
+	// A player with another key is hung up on before the handshake.
+	strangerCfg, _ := newTestTLSConfig(t)
+	conn, err = NewTLSTransport(":4000", strangerCfg).Dial(addr)
+	if err == nil {
+		conn.Send(hs)
+		_, err = conn.Receive()
+		conn.Close()
+	}
+	assert.NotNil(t, err)
+	select {
+	case <-addPeer:
+		t.Fatal("untrusted player was accepted")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// We don't talk to a server with another key either.
+	_, strangerKey := newTestTLSConfig(t)
+	pinnedCfg := clientCfg.Clone()
+	pinnedCfg.VerifyPeerCertificate = verifyPeerCertificate([]ed25519.PublicKey{strangerKey})
+	_, err = NewTLSTransport(":3000", pinnedCfg).Dial(addr)
+	assert.ErrorContains(t, err, "untrusted peer key")
+
+	// Plain TCP is refused as well.
+	conn, err = NewTCPTransport(":6000").Dial(addr)
+	assert.Nil(t, err)
+	conn.Send(hs)
+	_, err = conn.Receive()
+	assert.NotNil(t, err)
+	conn.Close()
+	select {
+	case <-addPeer:
+		t.Fatal("unencrypted player was accepted")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestGRPCTransportAuthenticatesPeers(t *testing.T) {
+	clientCfg, clientKey := newTestTLSConfig(t)
+	serverCfg, serverKey := newTestTLSConfig(t, clientKey)
+
+	addr := freeAddr(t)
+	server := NewGRPCTransport(addr, serverCfg)
+	addPeer := make(chan *Peer, 1)
+	assert.Nil(t, server.Listen(addPeer))
+	defer server.Close()
+
+	conn, err := NewGRPCTransport(":3000", clientCfg).Dial(addr)
+	assert.Nil(t, err)
+	defer conn.Close()
+	hs := NewMessage(":3000", Handshake{Version: "v1", ListenAddr: ":3000"})
+	assert.Nil(t, conn.Send(hs))
+	peer := <-addPeer
+	msg, err := peer.conn.Receive()
+	assert.Nil(t, err)
+	assert.Equal(t, hs, msg)
+
+	// Both sides know the key of the other one.
+	assert.True(t, conn.(authenticatedConn).Authenticated())
+	assert.Equal(t, serverKey, conn.(authenticatedConn).PeerKey())
+	assert.True(t, peer.conn.(authenticatedConn).Authenticated())
+	assert.Equal(t, clientKey, peer.conn.(authenticatedConn).PeerKey())
+
+	strangerCfg, _ := newTestTLSConfig(t)
+	_, err = NewGRPCTransport(":4000", strangerCfg).Dial(addr)
+	assert.NotNil(t, err)
+	select {
+	case <-addPeer:
+		t.Fatal("untrusted player was accepted")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestServerTrustsUnpinnedKeysOnFirstUse(t *testing.T) {
+	addr := freeAddr(t)
+	s := NewServer(ServerConfig{Version: "test", ListenAddr: addr})
+	assert.Nil(t, s.Start())
+	defer s.transport.Close()
+
+	// Without pinned keys a player we never saw before takes a seat, and is
+	// known by the key of its certificate.
+	cfg, pub := newTestTLSConfig(t)
+	conn, err := NewTLSTransport(":4000", cfg).Dial(addr)
+	assert.Nil(t, err)
+	defer conn.Close()
+	hs := NewMessage(PlayerID(pub), Handshake{Version: "test", ListenAddr: ":4000"})
+	assert.Nil(t, hs.Sign(cfg.Certificates[0].PrivateKey.(ed25519.PrivateKey)))
+	assert.Nil(t, conn.Send(hs))
+	reply, err := conn.Receive()
+	assert.Nil(t, err)
+	assert.Equal(t, s.ID(), reply.From)
+	assert.Eventually(t, func() bool { return len(s.Peers()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{PlayerID(pub)}, s.Peers())
+
+	// Once keys are pinned, the same player is refused.
+	_, other := newTestTLSConfig(t)
+	pinned := NewServer(ServerConfig{Version: "test", ListenAddr: freeAddr(t), TrustedPeers: []ed25519.PublicKey{other}})
+	assert.Nil(t, pinned.Start())
+	defer pinned.transport.Close()
+	conn, err = NewTLSTransport(":4000", cfg).Dial(pinned.ListenAddr)
+	if err == nil {
+		conn.Send(hs)
+		_, err = conn.Receive()
+		conn.Close()
+	}
+	assert.NotNil(t, err)
+	assert.Empty(t, pinned.Peers())
+}
+
+func TestVerifyPeerCertificate(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(nil)
+	assert.Nil(t, err)
+	cert, err := selfSignedCertificate(key)
+	assert.Nil(t, err)
+
+	other, _, err := ed25519.GenerateKey(nil)
+	assert.Nil(t, err)
+	assert.Nil(t, verifyPeerCertificate(nil)(cert.Certificate, nil))
+	assert.Nil(t, verifyPeerCertificate([]ed25519.PublicKey{other, key.Public().(ed25519.PublicKey)})(cert.Certificate, nil))
+	assert.ErrorContains(t, verifyPeerCertificate([]ed25519.PublicKey{other})(cert.Certificate, nil), "untrusted peer key")
+	assert.NotNil(t, verifyPeerCertificate(nil)(nil, nil))
+	assert.NotNil(t, verifyPeerCertificate(nil)([][]byte{{1, 2, 3}}, nil))
+}
@@ -0,0 +1,572 @@
+package p2p
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// BlindLevel is a single level of the blind structure of a tournament.
+type BlindLevel struct {
+	SmallBlind int           `json:"smallBlind"`
+	BigBlind   int           `json:"bigBlind"`
+	Ante       int           `json:"ante"`
+	Duration   time.Duration `json:"duration"`
+}
+
+type TournamentConfig struct {
+	// Seats is the size of the table, a Sit & Go can't have more entries.
+	Seats         int
+	StartingStack int
+	// Levels is the blind structure, the last level lasts until the end.
+	Levels []BlindLevel
+	// BuyIn is what every entry adds to the prize pool.
+	BuyIn int
+	// Payouts are the percentages of the prize pool paid to each place,
+	// starting with the winner.
+	Payouts []int
+	// StartWhenFull starts the tournament once every seat is taken,
+	// otherwise it is started with Start.
+	StartWhenFull bool
+	// LateRegistration is how long after the start players can still
+	// register, as long as there is a free seat.
+	LateRegistration time.Duration
+	// Bounty is put on the head of every player, apart from the buy-in.
+	Bounty BountyConfig
+}
+
+type TournamentStatus int
+
+const (
+	TournamentRegistering TournamentStatus = iota
+	TournamentRunning
+	TournamentFinished
+)
+
+func (s TournamentStatus) String() string {
+	switch s {
+	case TournamentRegistering:
+		return "REGISTERING"
+	case TournamentRunning:
+		return "RUNNING"
+	case TournamentFinished:
+		return "FINISHED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// TournamentFinish is the finishing place of a player and the prize won.
+type TournamentFinish struct {
+	Addr  string `json:"addr"`
+	Place int    `json:"place"`
+	Prize int    `json:"prize"`
+	// Bounties are the bounties won, paid on top of the prize.
+	Bounties int `json:"bounties,omitempty"`
+	// Deal is set when the prize was agreed in a deal.
+	Deal bool `json:"deal,omitempty"`
+}
+
+// Tournament runs a single table tournament on top of a PokerGame. The
+// blinds go up between hands and players are knocked out once they have no
+// chips left.
+type Tournament struct {
+	mu      sync.Mutex
+	cfg     TournamentConfig
+	game    *PokerGame
+	status  TournamentStatus
+	entries []string
+	started time.Time
+	level   int
+	// finishes holds the players knocked out, in the order they went out.
+	finishes []TournamentFinish
+	// handStacks are the stacks at the start of the last hand, players
+	// knocked out in the same hand finish in the order of these stacks.
+	handStacks map[string]int
+	// deal is the deal proposed to the players left, if any.
+	deal *Deal
+
+	// auto is set while Run deals the hands.
+	auto  bool
+	delay time.Duration
+
+	now func() time.Time
+}
+
+func NewTournament(cfg TournamentConfig) (*Tournament, error) {
+	if cfg.Seats < 2 || cfg.Seats > defaultMaxPlayers {
+		return nil, fmt.Errorf("tournament needs between 2 and %d seats", defaultMaxPlayers)
+	}
+	if cfg.StartingStack <= 0 {
+		return nil, fmt.Errorf("invalid starting stack %d", cfg.StartingStack)
+	}
+	if len(cfg.Payouts) > cfg.Seats {
+		return nil, fmt.Errorf("tournament pays more places than the %d seats", cfg.Seats)
+	}
+	if err := validateStructure(cfg.Levels, cfg.Payouts); err != nil {
+		return nil, err
+	}
+
+	first := cfg.Levels[0]
+	game := NewPokerGame(first.SmallBlind, first.BigBlind)
+	if err := game.SetBlinds(first.SmallBlind, first.BigBlind, first.Ante); err != nil {
+		return nil, err
+	}
+	game.SetBounty(cfg.Bounty)
+
+	return &Tournament{
+		cfg:        cfg,
+		game:       game,
+		status:     TournamentRegistering,
+		entries:    []string{},
+		finishes:   []TournamentFinish{},
+		handStacks: make(map[string]int),
+		now:        time.Now,
+	}, nil
+}
+
+// validateStructure checks the blind levels and the payouts of a
+// tournament.
+func validateStructure(levels []BlindLevel, payouts []int) error {
+	if len(levels) == 0 {
+		return fmt.Errorf("tournament needs at least one blind level")
+	}
+	for i, level := range levels {
+		if level.Duration <= 0 && i < len(levels)-1 {
+			return fmt.Errorf("blind level %d has no duration", i+1)
+		}
+	}
+	if len(payouts) == 0 {
+		return fmt.Errorf("tournament needs to pay at least one place")
+	}
+	total := 0
+	for _, pct := range payouts {
+		if pct <= 0 {
+			return fmt.Errorf("invalid payout %d%%", pct)
+		}
+		total += pct
+	}
+	if total != 100 {
+		return fmt.Errorf("payouts add up to %d%% instead of 100%%", total)
+	}
+	return nil
+}
+
+// Game returns the game the tournament is played on.
+func (t *Tournament) Game() *PokerGame {
+	return t.game
+}
+
+func (t *Tournament) Status() TournamentStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.status
+}
+
+// Register takes a seat for the player. Once the tournament is running
+// players can only register during the late registration.
+func (t *Tournament) Register(addr string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	position, ok := freeSeat(t.game, t.cfg.Seats)
+	if !ok {
+		return fmt.Errorf("tournament is full")
+	}
+
+	return t.register(addr, position)
+}
+
+// Seat registers the player on the given seat, for tables where the
+// players take their own seat.
+func (t *Tournament) Seat(addr string, position int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if position < 0 || position >= t.cfg.Seats {
+		return fmt.Errorf("tournament has no seat %d", position)
+	}
+	if seatTaken(t.game, position) {
+		return fmt.Errorf("seat %d is taken", position)
+	}
+
+	return t.register(addr, position)
+}
+
+func (t *Tournament) register(addr string, position int) error {
+	switch t.status {
+	case TournamentFinished:
+		return fmt.Errorf("tournament is finished")
+	case TournamentRunning:
+		if !t.now().Before(t.started.Add(t.cfg.LateRegistration)) {
+			return fmt.Errorf("registration is closed")
+		}
+	}
+
+	for _, entry := range t.entries {
+		if entry == addr {
+			return fmt.Errorf("player %s is already registered", addr)
+		}
+	}
+
+	if err := t.game.AddPlayer(addr, t.cfg.StartingStack, position); err != nil {
+		return err
+	}
+	t.entries = append(t.entries, addr)
+
+	logrus.WithFields(logrus.Fields{
+		"player":  addr,
+		"entries": len(t.entries),
+	}).Info("player registered for tournament")
+
+	if t.status == TournamentRegistering && t.cfg.StartWhenFull && len(t.entries) == t.cfg.Seats {
+		t.start()
+	}
+
+	return nil
+}
+
+// seatTaken reports whether a player of the game sits on the seat.
+func seatTaken(game *PokerGame, position int) bool {
+	game.mu.RLock()
+	defer game.mu.RUnlock()
+
+	for _, player := range game.players {
+		if player.Position == position {
+			return true
+		}
+	}
+
+	return false
+}
+
+// freeSeat returns the lowest seat of the game that isn't taken.
+func freeSeat(game *PokerGame, seats int) (int, bool) {
+	game.mu.RLock()
+	defer game.mu.RUnlock()
+
+	taken := make(map[int]bool)
+	for _, player := range game.players {
+		taken[player.Position] = true
+	}
+	for position := 0; position < seats; position++ {
+		if !taken[position] {
+			return position, true
+		}
+	}
+
+	return 0, false
+}
+
+// Start starts a tournament that doesn't wait for a full table.
+func (t *Tournament) Start() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.status != TournamentRegistering {
+		return fmt.Errorf("tournament is %s", t.status)
+	}
+	if len(t.entries) < 2 {
+		return fmt.Errorf("need at least 2 players to start a tournament")
+	}
+
+	t.start()
+
+	return nil
+}
+
+func (t *Tournament) start() {
+	t.status = TournamentRunning
+	t.started = t.now()
+	t.level = 0
+
+	logrus.WithField("players", len(t.entries)).Info("tournament started")
+
+	if t.auto {
+		go t.dealAfter(t.delay)
+	}
+}
+
+// NextHand knocks out the players that lost their last chip in the last
+// hand, moves the blinds up when the level is over and deals the next
+// hand. Once a single player is left the tournament is finished and no
+// hand is dealt.
+func (t *Tournament) NextHand() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.prepareHand(); err != nil || t.status == TournamentFinished {
+		return err
+	}
+
+	return t.game.StartNewHand()
+}
+
+// PrepareHand does what NextHand does before dealing, for tables that deal
+// the hands themselves.
+func (t *Tournament) PrepareHand() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.prepareHand()
+}
+
+func (t *Tournament) prepareHand() error {
+	if t.status != TournamentRunning {
+		return fmt.Errorf("tournament is %s", t.status)
+	}
+	if t.deal != nil {
+		return fmt.Errorf("waiting for the players to accept or reject the deal")
+	}
+
+	if err := t.knockOut(); err != nil {
+		return err
+	}
+	if t.status == TournamentFinished {
+		return nil
+	}
+
+	if level := levelAt(t.cfg.Levels, t.started, t.now()); level != t.level {
+		blinds := t.cfg.Levels[level]
+		if err := t.game.SetBlinds(blinds.SmallBlind, blinds.BigBlind, blinds.Ante); err != nil {
+			return err
+		}
+		t.level = level
+
+		logrus.WithFields(logrus.Fields{
+			"level":  level + 1,
+			"blinds": fmt.Sprintf("%d/%d", blinds.SmallBlind, blinds.BigBlind),
+			"ante":   blinds.Ante,
+		}).Info("tournament blinds up")
+	}
+
+	t.game.mu.RLock()
+	t.handStacks = make(map[string]int)
+	for addr, player := range t.game.players {
+		t.handStacks[addr] = player.Stack
+	}
+	t.game.mu.RUnlock()
+
+	return nil
+}
+
+// knockOut takes the players without chips off the table and gives them
+// their finishing place.
+func (t *Tournament) knockOut() error {
+	busted, err := bustedPlayers(t.game)
+	if err != nil {
+		return err
+	}
+	sortKnockouts(busted, t.handStacks)
+
+	t.game.mu.RLock()
+	left := len(t.game.players)
+	t.game.mu.RUnlock()
+
+	for _, addr := range busted {
+		bounties := bountiesOf(t.game, addr)
+		if err := t.game.RemovePlayer(addr); err != nil {
+			return err
+		}
+		t.finishes = append(t.finishes, TournamentFinish{Addr: addr, Place: left, Bounties: bounties})
+		left--
+
+		logrus.WithFields(logrus.Fields{
+			"player": addr,
+			"place":  left + 1,
+		}).Info("player knocked out of tournament")
+	}
+
+	if left == 1 {
+		t.game.mu.RLock()
+		for addr, player := range t.game.players {
+			// The winner also collects the own bounty.
+			t.finishes = append(t.finishes, TournamentFinish{Addr: addr, Place: 1, Bounties: player.BountiesWon + player.Bounty})
+		}
+		t.game.mu.RUnlock()
+		t.status = TournamentFinished
+
+		logrus.WithField("winner", t.finishes[len(t.finishes)-1].Addr).Info("tournament finished")
+	}
+
+	return nil
+}
+
+// bustedPlayers returns the players of the game without chips. It fails
+// while a hand is in progress.
+func bustedPlayers(game *PokerGame) ([]string, error) {
+	game.mu.RLock()
+	defer game.mu.RUnlock()
+
+	if game.gameStarted {
+		return nil, fmt.Errorf("hand %d is still in progress", game.handNumber)
+	}
+
+	busted := []string{}
+	for addr, player := range game.players {
+		if player.Stack == 0 {
+			busted = append(busted, addr)
+		}
+	}
+
+	return busted, nil
+}
+
+// sortKnockouts orders players knocked out in the same hand, worst place
+// first. The player that started the hand with fewer chips finishes behind.
+func sortKnockouts(busted []string, handStacks map[string]int) {
+	sort.Slice(busted, func(i, j int) bool {
+		if handStacks[busted[i]] != handStacks[busted[j]] {
+			return handStacks[busted[i]] < handStacks[busted[j]]
+		}
+		return busted[i] < busted[j]
+	})
+}
+
+// levelAt returns the blind level that is played at the given time.
+func levelAt(levels []BlindLevel, started, now time.Time) int {
+	end := started
+	for i, level := range levels[:len(levels)-1] {
+		end = end.Add(level.Duration)
+		if now.Before(end) {
+			return i
+		}
+	}
+
+	return len(levels) - 1
+}
+
+// Level returns the blind level being played, counting from 0.
+func (t *Tournament) Level() (int, BlindLevel) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.level, t.cfg.Levels[t.level]
+}
+
+// PrizePool returns the sum of all buy-ins.
+func (t *Tournament) PrizePool() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.cfg.BuyIn * len(t.entries)
+}
+
+// Standings returns the players that finished, best place first, with the
+// prize of their place.
+func (t *Tournament) Standings() []TournamentFinish {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	standings := make([]TournamentFinish, len(t.finishes))
+	for i, finish := range t.finishes {
+		if !finish.Deal {
+			finish.Prize = t.prize(finish.Place)
+		}
+		standings[len(t.finishes)-1-i] = finish
+	}
+
+	return standings
+}
+
+func (t *Tournament) prize(place int) int {
+	return payout(t.cfg.BuyIn*len(t.entries), t.cfg.Payouts, place)
+}
+
+// payout returns the prize of the given place. The winner also gets what
+// can't be divided.
+func payout(pool int, payouts []int, place int) int {
+	if place > len(payouts) {
+		return 0
+	}
+
+	prize := pool * payouts[place-1] / 100
+	if place == 1 {
+		rest := pool
+		for _, pct := range payouts {
+			rest -= pool * pct / 100
+		}
+		prize += rest
+	}
+
+	return prize
+}
+
+// Run deals the hands of the tournament, waiting delay after each hand. The
+// returned function stops dealing.
+func (t *Tournament) Run(delay time.Duration) func() {
+	t.mu.Lock()
+	t.auto = true
+	t.delay = delay
+	running := t.status == TournamentRunning
+	t.mu.Unlock()
+
+	unsubscribe := t.game.Subscribe(func(r EventRecord) {
+		if _, ok := r.Event.(EventHandEnded); ok {
+			go t.dealAfter(delay)
+		}
+	})
+	if running {
+		go t.dealAfter(0)
+	}
+
+	return func() {
+		unsubscribe()
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.auto = false
+	}
+}
+
+func (t *Tournament) dealAfter(delay time.Duration) {
+	time.Sleep(delay)
+
+	t.mu.Lock()
+	auto := t.auto
+	t.mu.Unlock()
+	if !auto {
+		return
+	}
+
+	if err := t.NextHand(); err != nil {
+		logrus.Errorf("tournament failed to deal the next hand: %s", err)
+	}
+}
+
+// SetTournament plays the tournament at the table, on the game of the
+// tournament. Players register as they get ready.
+func (g *GameState) SetTournament(t *Tournament) {
+	g.tournament = t
+}
+
+// tournamentDeals reports whether the tournament lets the next hand be
+// dealt. Tables without a tournament always deal.
+func (g *GameState) tournamentDeals() bool {
+	if g.tournament == nil {
+		return true
+	}
+
+	return g.tournament.Status() == TournamentRunning && g.tournament.Deal() == nil
+}
+
+// nextTournamentHand knocks out the players that lost their last chip and
+// moves the blinds up. Every node does so once the hand is over, the
+// players knocked out are not dealt in anymore.
+func (g *GameState) nextTournamentHand() {
+	if g.tournament == nil {
+		return
+	}
+
+	if err := g.tournament.PrepareHand(); err != nil {
+		logrus.Errorf("tournament error: %s", err)
+		return
+	}
+	for _, finish := range g.tournament.Standings() {
+		g.table.RemovePlayerByAddr(finish.Addr)
+	}
+}
@@ -0,0 +1,189 @@
+package p2p
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTournament(t *testing.T, cfg TournamentConfig, now *time.Time) *Tournament {
+	tournament, err := NewTournament(cfg)
+	assert.Nil(t, err)
+	tournament.now = func() time.Time { return *now }
+
+	return tournament
+}
+
+// shoveOrCall plays the hand with everybody all-in.
+func shoveOrCall(t *testing.T, game *PokerGame) {
+	for game.gameStarted {
+		addr := game.actionOn
+		actions := game.LegalActions(addr)
+		last := actions[len(actions)-1]
+		switch last.Action {
+		case "SHOW", "MUCK":
+			assert.Nil(t, game.ShowHand(addr))
+		case PlayerActionBet.String(), PlayerActionRaise.String():
+			action := PlayerActionRaise
+			if last.Action == PlayerActionBet.String() {
+				action = PlayerActionBet
+			}
+			assert.Nil(t, game.PlayerAction(addr, action, last.Max))
+		default:
+			assert.Nil(t, game.PlayerAction(addr, PlayerActionCall, 0))
+		}
+	}
+}
+
+func TestTournamentConfig(t *testing.T) {
+	levels := []BlindLevel{{SmallBlind: 5, BigBlind: 10}}
+
+	_, err := NewTournament(TournamentConfig{Seats: 3, StartingStack: 100, Levels: levels, Payouts: []int{70, 20}})
+	assert.NotNil(t, err)
+	_, err = NewTournament(TournamentConfig{Seats: 3, StartingStack: 100, Payouts: []int{100}})
+	assert.NotNil(t, err)
+	_, err = NewTournament(TournamentConfig{Seats: 3, StartingStack: 100, Levels: append(levels, levels...), Payouts: []int{100}})
+	assert.NotNil(t, err)
+	_, err = NewTournament(TournamentConfig{Seats: 3, StartingStack: 100, Levels: levels, Payouts: []int{100}})
+	assert.Nil(t, err)
+}
+
+func TestTournamentSitAndGo(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tournament := newTestTournament(t, TournamentConfig{
+		Seats:         3,
+		StartingStack: 100,
+		Levels: []BlindLevel{
+			{SmallBlind: 5, BigBlind: 10, Duration: time.Minute},
+			{SmallBlind: 10, BigBlind: 20, Ante: 5},
+		},
+		BuyIn:         10,
+		Payouts:       []int{70, 30},
+		StartWhenFull: true,
+	}, &now)
+	game := tournament.Game()
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, TournamentRegistering, tournament.Status())
+		assert.Nil(t, tournament.Register(fmt.Sprintf(":%d", i+1)))
+	}
+	assert.NotNil(t, tournament.Register(":1"))
+	assert.Equal(t, TournamentRunning, tournament.Status())
+	assert.NotNil(t, tournament.Register(":4"))
+	assert.Equal(t, 30, tournament.PrizePool())
+
+	assert.Nil(t, tournament.NextHand())
+	assert.NotNil(t, tournament.NextHand())
+	assert.Equal(t, 10, game.bigBlind)
+	foldToBigBlind(t, game)
+
+	// The blinds go up at the first hand after the level is over.
+	now = now.Add(time.Minute)
+	assert.Nil(t, tournament.NextHand())
+	level, blinds := tournament.Level()
+	assert.Equal(t, 1, level)
+	assert.Equal(t, 5, blinds.Ante)
+	assert.Equal(t, 20, game.bigBlind)
+	assert.Equal(t, 5, game.ante)
+	shoveOrCall(t, game)
+
+	for hands := 0; tournament.Status() == TournamentRunning && hands < 100; hands++ {
+		assert.Nil(t, tournament.NextHand())
+		shoveOrCall(t, game)
+	}
+
+	assert.Equal(t, TournamentFinished, tournament.Status())
+	assert.NotNil(t, tournament.NextHand())
+
+	standings := tournament.Standings()
+	assert.Len(t, standings, 3)
+	for i, finish := range standings {
+		assert.Equal(t, i+1, finish.Place)
+	}
+	assert.Equal(t, 21, standings[0].Prize)
+	assert.Equal(t, 9, standings[1].Prize)
+	assert.Equal(t, 0, standings[2].Prize)
+	assert.Equal(t, 300, game.players[standings[0].Addr].Stack)
+	assert.Len(t, game.players, 1)
+}
+
+func TestTournamentLateRegistration(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tournament := newTestTournament(t, TournamentConfig{
+		Seats:            3,
+		StartingStack:    100,
+		Levels:           []BlindLevel{{SmallBlind: 5, BigBlind: 10}},
+		Payouts:          []int{100},
+		LateRegistration: 10 * time.Minute,
+	}, &now)
+
+	assert.Nil(t, tournament.Register(":1"))
+	assert.NotNil(t, tournament.Start())
+	assert.Nil(t, tournament.Register(":2"))
+	assert.Nil(t, tournament.Start())
+	assert.Nil(t, tournament.NextHand())
+
+	// Players registering during a hand wait for the big blind.
+	now = now.Add(5 * time.Minute)
+	assert.Nil(t, tournament.Register(":3"))
+	assert.True(t, tournament.Game().players[":3"].SittingOut)
+	assert.Equal(t, 2, tournament.Game().players[":3"].Position)
+
+	now = now.Add(5 * time.Minute)
+	assert.NotNil(t, tournament.Register(":4"))
+}
+
+func TestTournamentSeat(t *testing.T) {
+	now := time.Now()
+	tournament := newTestTournament(t, TournamentConfig{
+		Seats:         2,
+		StartingStack: 100,
+		Levels:        []BlindLevel{{SmallBlind: 5, BigBlind: 10}},
+		Payouts:       []int{100},
+		StartWhenFull: true,
+	}, &now)
+
+	assert.NotNil(t, tournament.Seat(":1", 2))
+	assert.Nil(t, tournament.Seat(":1", 1))
+	assert.NotNil(t, tournament.Seat(":2", 1))
+	assert.Nil(t, tournament.Seat(":2", 0))
+	assert.Equal(t, TournamentRunning, tournament.Status())
+	assert.Equal(t, 1, tournament.Game().players[":1"].Position)
+
+	// Tables that deal the hands themselves only get the blinds and the
+	// knockouts between hands.
+	assert.Nil(t, tournament.PrepareHand())
+	assert.False(t, tournament.Game().gameStarted)
+}
+
+func TestTournamentKnockOutOrder(t *testing.T) {
+	now := time.Now()
+	tournament := newTestTournament(t, TournamentConfig{
+		Seats:         4,
+		StartingStack: 100,
+		Levels:        []BlindLevel{{SmallBlind: 5, BigBlind: 10}},
+		BuyIn:         10,
+		Payouts:       []int{50, 30, 20},
+		StartWhenFull: true,
+	}, &now)
+	game := tournament.Game()
+	for i := 0; i < 4; i++ {
+		assert.Nil(t, tournament.Register(fmt.Sprintf(":%d", i+1)))
+	}
+
+	// :2 started the hand with more chips than :3, and finishes ahead.
+	tournament.handStacks = map[string]int{":1": 100, ":2": 80, ":3": 20, ":4": 200}
+	game.players[":2"].Stack = 0
+	game.players[":3"].Stack = 0
+	game.players[":4"].Stack = 300
+	assert.Nil(t, tournament.knockOut())
+
+	assert.Equal(t, TournamentRunning, tournament.Status())
+	assert.Equal(t, []TournamentFinish{
+		{Addr: ":2", Place: 3, Prize: 8},
+		{Addr: ":3", Place: 4},
+	}, tournament.Standings())
+	assert.Len(t, game.players, 2)
+}
@@ -0,0 +1,23 @@
+package p2p
+
+import "crypto/ed25519"
+
+// Transport connects the server to the other players of the table, over
+// TCP, gRPC or in memory.
+type Transport interface {
+	// Listen starts accepting peers at the listen address of the transport
+	// and passes them on to addPeer. It returns once the transport listens.
+	Listen(addPeer chan<- *Peer) error
+	// Dial connects to the player listening at addr.
+	Dial(addr string) (MessageConn, error)
+	// Close stops accepting peers.
+	Close() error
+}
+
+// authenticatedConn is a connection of which the transport may authenticate
+// the identity key of the peer. Authenticated reports whether it does, then
+// PeerKey is the key of the peer.
+type authenticatedConn interface {
+	Authenticated() bool
+	PeerKey() ed25519.PublicKey
+}
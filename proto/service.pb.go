@@ -1,34 +1,1925 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.5
+// 	protoc        (unknown)
 // source: proto/service.proto
 
 package proto
 
 import (
-	fmt "fmt"
-	math "math"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GameVariant int32
+
+const (
+	GameVariant_GAME_VARIANT_TEXAS_HOLDEM GameVariant = 0
+	GameVariant_GAME_VARIANT_OTHER        GameVariant = 1
+)
+
+// Enum value maps for GameVariant.
+var (
+	GameVariant_name = map[int32]string{
+		0: "GAME_VARIANT_TEXAS_HOLDEM",
+		1: "GAME_VARIANT_OTHER",
+	}
+	GameVariant_value = map[string]int32{
+		"GAME_VARIANT_TEXAS_HOLDEM": 0,
+		"GAME_VARIANT_OTHER":        1,
+	}
+)
+
+func (x GameVariant) Enum() *GameVariant {
+	p := new(GameVariant)
+	*p = x
+	return p
+}
+
+func (x GameVariant) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (GameVariant) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_service_proto_enumTypes[0].Descriptor()
+}
+
+func (GameVariant) Type() protoreflect.EnumType {
+	return &file_proto_service_proto_enumTypes[0]
+}
+
+func (x GameVariant) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use GameVariant.Descriptor instead.
+func (GameVariant) EnumDescriptor() ([]byte, []int) {
+	return file_proto_service_proto_rawDescGZIP(), []int{0}
+}
+
+type GameStatus int32
+
+const (
+	GameStatus_GAME_STATUS_CONNECTED    GameStatus = 0
+	GameStatus_GAME_STATUS_PLAYER_READY GameStatus = 1
+	GameStatus_GAME_STATUS_DEALING      GameStatus = 2
+	GameStatus_GAME_STATUS_PRE_FLOP     GameStatus = 3
+	GameStatus_GAME_STATUS_FLOP         GameStatus = 4
+	GameStatus_GAME_STATUS_TURN         GameStatus = 5
+	GameStatus_GAME_STATUS_RIVER        GameStatus = 6
+	GameStatus_GAME_STATUS_SHOWDOWN     GameStatus = 7
+)
+
+// Enum value maps for GameStatus.
+var (
+	GameStatus_name = map[int32]string{
+		0: "GAME_STATUS_CONNECTED",
+		1: "GAME_STATUS_PLAYER_READY",
+		2: "GAME_STATUS_DEALING",
+		3: "GAME_STATUS_PRE_FLOP",
+		4: "GAME_STATUS_FLOP",
+		5: "GAME_STATUS_TURN",
+		6: "GAME_STATUS_RIVER",
+		7: "GAME_STATUS_SHOWDOWN",
+	}
+	GameStatus_value = map[string]int32{
+		"GAME_STATUS_CONNECTED":    0,
+		"GAME_STATUS_PLAYER_READY": 1,
+		"GAME_STATUS_DEALING":      2,
+		"GAME_STATUS_PRE_FLOP":     3,
+		"GAME_STATUS_FLOP":         4,
+		"GAME_STATUS_TURN":         5,
+		"GAME_STATUS_RIVER":        6,
+		"GAME_STATUS_SHOWDOWN":     7,
+	}
+)
+
+func (x GameStatus) Enum() *GameStatus {
+	p := new(GameStatus)
+	*p = x
+	return p
+}
+
+func (x GameStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
 
-	proto "github.com/golang/protobuf/proto"
+func (GameStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_service_proto_enumTypes[1].Descriptor()
+}
+
+func (GameStatus) Type() protoreflect.EnumType {
+	return &file_proto_service_proto_enumTypes[1]
+}
+
+func (x GameStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use GameStatus.Descriptor instead.
+func (GameStatus) EnumDescriptor() ([]byte, []int) {
+	return file_proto_service_proto_rawDescGZIP(), []int{1}
+}
+
+type Action int32
+
+const (
+	Action_ACTION_NONE  Action = 0
+	Action_ACTION_FOLD  Action = 1
+	Action_ACTION_CHECK Action = 2
+	Action_ACTION_CALL  Action = 3
+	Action_ACTION_BET   Action = 4
+	Action_ACTION_RAISE Action = 5
+)
+
+// Enum value maps for Action.
+var (
+	Action_name = map[int32]string{
+		0: "ACTION_NONE",
+		1: "ACTION_FOLD",
+		2: "ACTION_CHECK",
+		3: "ACTION_CALL",
+		4: "ACTION_BET",
+		5: "ACTION_RAISE",
+	}
+	Action_value = map[string]int32{
+		"ACTION_NONE":  0,
+		"ACTION_FOLD":  1,
+		"ACTION_CHECK": 2,
+		"ACTION_CALL":  3,
+		"ACTION_BET":   4,
+		"ACTION_RAISE": 5,
+	}
 )
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ = proto.Marshal
-var _ = fmt.Errorf
-var _ = math.Inf
+func (x Action) Enum() *Action {
+	p := new(Action)
+	*p = x
+	return p
+}
+
+func (x Action) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Action) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_service_proto_enumTypes[2].Descriptor()
+}
+
+func (Action) Type() protoreflect.EnumType {
+	return &file_proto_service_proto_enumTypes[2]
+}
+
+func (x Action) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Action.Descriptor instead.
+func (Action) EnumDescriptor() ([]byte, []int) {
+	return file_proto_service_proto_rawDescGZIP(), []int{2}
+}
+
+// Envelope carries a message, the player ID of its sender and the signature
+// of the sender. The signature is over the canonical encoding of the
+// envelope without it, see canonicalBytes in the p2p package.
+type Envelope struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	From      string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	Signature []byte                 `protobuf:"bytes,18,opt,name=signature,proto3" json:"signature,omitempty"`
+	// hand is the hand the sender was at and seq counts up the messages of
+	// the sender, so replayed messages are dropped.
+	Hand int64  `protobuf:"varint,19,opt,name=hand,proto3" json:"hand,omitempty"`
+	Seq  uint64 `protobuf:"varint,20,opt,name=seq,proto3" json:"seq,omitempty"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*Envelope_Handshake
+	//	*Envelope_PeerList
+	//	*Envelope_EncDeck
+	//	*Envelope_Ready
+	//	*Envelope_SitOut
+	//	*Envelope_SitIn
+	//	*Envelope_ChipsAdded
+	//	*Envelope_PreFlop
+	//	*Envelope_PlayerAction
+	//	*Envelope_ShowHand
+	//	*Envelope_Dealer
+	//	*Envelope_CardKeys
+	//	*Envelope_PlayerDisconnected
+	//	*Envelope_StateSync
+	//	*Envelope_TimerStarted
+	//	*Envelope_TimerStopped
+	//	*Envelope_DealProposed
+	//	*Envelope_DealAnswer
+	Payload       isEnvelope_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Envelope) Reset() {
+	*x = Envelope{}
+	mi := &file_proto_service_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Envelope) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Envelope) ProtoMessage() {}
+
+func (x *Envelope) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_service_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Envelope.ProtoReflect.Descriptor instead.
+func (*Envelope) Descriptor() ([]byte, []int) {
+	return file_proto_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Envelope) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *Envelope) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+func (x *Envelope) GetHand() int64 {
+	if x != nil {
+		return x.Hand
+	}
+	return 0
+}
+
+func (x *Envelope) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *Envelope) GetPayload() isEnvelope_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Envelope) GetHandshake() *Handshake {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_Handshake); ok {
+			return x.Handshake
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetPeerList() *PeerList {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_PeerList); ok {
+			return x.PeerList
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetEncDeck() *EncDeck {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_EncDeck); ok {
+			return x.EncDeck
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetReady() *Ready {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_Ready); ok {
+			return x.Ready
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetSitOut() *SitOut {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_SitOut); ok {
+			return x.SitOut
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetSitIn() *SitIn {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_SitIn); ok {
+			return x.SitIn
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetChipsAdded() *ChipsAdded {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_ChipsAdded); ok {
+			return x.ChipsAdded
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetPreFlop() *PreFlop {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_PreFlop); ok {
+			return x.PreFlop
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetPlayerAction() *PlayerAction {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_PlayerAction); ok {
+			return x.PlayerAction
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetShowHand() *ShowHand {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_ShowHand); ok {
+			return x.ShowHand
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetDealer() *Dealer {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_Dealer); ok {
+			return x.Dealer
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetCardKeys() *CardKeys {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_CardKeys); ok {
+			return x.CardKeys
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetPlayerDisconnected() *PlayerDisconnected {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_PlayerDisconnected); ok {
+			return x.PlayerDisconnected
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetStateSync() *StateSync {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_StateSync); ok {
+			return x.StateSync
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetTimerStarted() *TimerStarted {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_TimerStarted); ok {
+			return x.TimerStarted
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetTimerStopped() *TimerStopped {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_TimerStopped); ok {
+			return x.TimerStopped
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetDealProposed() *DealProposed {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_DealProposed); ok {
+			return x.DealProposed
+		}
+	}
+	return nil
+}
+
+func (x *Envelope) GetDealAnswer() *DealAnswer {
+	if x != nil {
+		if x, ok := x.Payload.(*Envelope_DealAnswer); ok {
+			return x.DealAnswer
+		}
+	}
+	return nil
+}
+
+type isEnvelope_Payload interface {
+	isEnvelope_Payload()
+}
+
+type Envelope_Handshake struct {
+	Handshake *Handshake `protobuf:"bytes,2,opt,name=handshake,proto3,oneof"`
+}
+
+type Envelope_PeerList struct {
+	PeerList *PeerList `protobuf:"bytes,3,opt,name=peer_list,json=peerList,proto3,oneof"`
+}
+
+type Envelope_EncDeck struct {
+	EncDeck *EncDeck `protobuf:"bytes,4,opt,name=enc_deck,json=encDeck,proto3,oneof"`
+}
+
+type Envelope_Ready struct {
+	Ready *Ready `protobuf:"bytes,5,opt,name=ready,proto3,oneof"`
+}
+
+type Envelope_SitOut struct {
+	SitOut *SitOut `protobuf:"bytes,6,opt,name=sit_out,json=sitOut,proto3,oneof"`
+}
+
+type Envelope_SitIn struct {
+	SitIn *SitIn `protobuf:"bytes,7,opt,name=sit_in,json=sitIn,proto3,oneof"`
+}
+
+type Envelope_ChipsAdded struct {
+	ChipsAdded *ChipsAdded `protobuf:"bytes,8,opt,name=chips_added,json=chipsAdded,proto3,oneof"`
+}
+
+type Envelope_PreFlop struct {
+	PreFlop *PreFlop `protobuf:"bytes,9,opt,name=pre_flop,json=preFlop,proto3,oneof"`
+}
+
+type Envelope_PlayerAction struct {
+	PlayerAction *PlayerAction `protobuf:"bytes,10,opt,name=player_action,json=playerAction,proto3,oneof"`
+}
+
+type Envelope_ShowHand struct {
+	ShowHand *ShowHand `protobuf:"bytes,11,opt,name=show_hand,json=showHand,proto3,oneof"`
+}
+
+type Envelope_Dealer struct {
+	Dealer *Dealer `protobuf:"bytes,12,opt,name=dealer,proto3,oneof"`
+}
+
+type Envelope_CardKeys struct {
+	CardKeys *CardKeys `protobuf:"bytes,13,opt,name=card_keys,json=cardKeys,proto3,oneof"`
+}
+
+type Envelope_PlayerDisconnected struct {
+	PlayerDisconnected *PlayerDisconnected `protobuf:"bytes,14,opt,name=player_disconnected,json=playerDisconnected,proto3,oneof"`
+}
+
+type Envelope_StateSync struct {
+	StateSync *StateSync `protobuf:"bytes,15,opt,name=state_sync,json=stateSync,proto3,oneof"`
+}
+
+type Envelope_TimerStarted struct {
+	TimerStarted *TimerStarted `protobuf:"bytes,16,opt,name=timer_started,json=timerStarted,proto3,oneof"`
+}
+
+type Envelope_TimerStopped struct {
+	TimerStopped *TimerStopped `protobuf:"bytes,17,opt,name=timer_stopped,json=timerStopped,proto3,oneof"`
+}
+
+type Envelope_DealProposed struct {
+	DealProposed *DealProposed `protobuf:"bytes,21,opt,name=deal_proposed,json=dealProposed,proto3,oneof"`
+}
+
+type Envelope_DealAnswer struct {
+	DealAnswer *DealAnswer `protobuf:"bytes,22,opt,name=deal_answer,json=dealAnswer,proto3,oneof"`
+}
+
+func (*Envelope_Handshake) isEnvelope_Payload() {}
+
+func (*Envelope_PeerList) isEnvelope_Payload() {}
+
+func (*Envelope_EncDeck) isEnvelope_Payload() {}
+
+func (*Envelope_Ready) isEnvelope_Payload() {}
+
+func (*Envelope_SitOut) isEnvelope_Payload() {}
+
+func (*Envelope_SitIn) isEnvelope_Payload() {}
+
+func (*Envelope_ChipsAdded) isEnvelope_Payload() {}
+
+func (*Envelope_PreFlop) isEnvelope_Payload() {}
+
+func (*Envelope_PlayerAction) isEnvelope_Payload() {}
+
+func (*Envelope_ShowHand) isEnvelope_Payload() {}
+
+func (*Envelope_Dealer) isEnvelope_Payload() {}
+
+func (*Envelope_CardKeys) isEnvelope_Payload() {}
+
+func (*Envelope_PlayerDisconnected) isEnvelope_Payload() {}
+
+func (*Envelope_StateSync) isEnvelope_Payload() {}
+
+func (*Envelope_TimerStarted) isEnvelope_Payload() {}
+
+func (*Envelope_TimerStopped) isEnvelope_Payload() {}
+
+func (*Envelope_DealProposed) isEnvelope_Payload() {}
+
+func (*Envelope_DealAnswer) isEnvelope_Payload() {}
+
+type Handshake struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	GameVariant   GameVariant            `protobuf:"varint,2,opt,name=game_variant,json=gameVariant,proto3,enum=GameVariant" json:"game_variant,omitempty"`
+	GameStatus    GameStatus             `protobuf:"varint,3,opt,name=game_status,json=gameStatus,proto3,enum=GameStatus" json:"game_status,omitempty"`
+	ListenAddr    string                 `protobuf:"bytes,4,opt,name=listen_addr,json=listenAddr,proto3" json:"listen_addr,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Handshake) Reset() {
+	*x = Handshake{}
+	mi := &file_proto_service_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Handshake) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Handshake) ProtoMessage() {}
+
+func (x *Handshake) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_service_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Handshake.ProtoReflect.Descriptor instead.
+func (*Handshake) Descriptor() ([]byte, []int) {
+	return file_proto_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Handshake) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *Handshake) GetGameVariant() GameVariant {
+	if x != nil {
+		return x.GameVariant
+	}
+	return GameVariant_GAME_VARIANT_TEXAS_HOLDEM
+}
+
+func (x *Handshake) GetGameStatus() GameStatus {
+	if x != nil {
+		return x.GameStatus
+	}
+	return GameStatus_GAME_STATUS_CONNECTED
+}
+
+func (x *Handshake) GetListenAddr() string {
+	if x != nil {
+		return x.ListenAddr
+	}
+	return ""
+}
+
+type PeerList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Peers         []string               `protobuf:"bytes,1,rep,name=peers,proto3" json:"peers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PeerList) Reset() {
+	*x = PeerList{}
+	mi := &file_proto_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PeerList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeerList) ProtoMessage() {}
+
+func (x *PeerList) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeerList.ProtoReflect.Descriptor instead.
+func (*PeerList) Descriptor() ([]byte, []int) {
+	return file_proto_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PeerList) GetPeers() []string {
+	if x != nil {
+		return x.Peers
+	}
+	return nil
+}
+
+// EncDeck passes the deck on to the next player, to be shuffled or locked.
+type EncDeck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Deck          [][]byte               `protobuf:"bytes,1,rep,name=deck,proto3" json:"deck,omitempty"`
+	Locked        bool                   `protobuf:"varint,2,opt,name=locked,proto3" json:"locked,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EncDeck) Reset() {
+	*x = EncDeck{}
+	mi := &file_proto_service_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EncDeck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EncDeck) ProtoMessage() {}
+
+func (x *EncDeck) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_service_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EncDeck.ProtoReflect.Descriptor instead.
+func (*EncDeck) Descriptor() ([]byte, []int) {
+	return file_proto_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *EncDeck) GetDeck() [][]byte {
+	if x != nil {
+		return x.Deck
+	}
+	return nil
+}
+
+func (x *EncDeck) GetLocked() bool {
+	if x != nil {
+		return x.Locked
+	}
+	return false
+}
+
+type Ready struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Ready) Reset() {
+	*x = Ready{}
+	mi := &file_proto_service_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Ready) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ready) ProtoMessage() {}
+
+func (x *Ready) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_service_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ready.ProtoReflect.Descriptor instead.
+func (*Ready) Descriptor() ([]byte, []int) {
+	return file_proto_service_proto_rawDescGZIP(), []int{4}
+}
+
+type SitOut struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NextBigBlind  bool                   `protobuf:"varint,1,opt,name=next_big_blind,json=nextBigBlind,proto3" json:"next_big_blind,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SitOut) Reset() {
+	*x = SitOut{}
+	mi := &file_proto_service_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SitOut) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SitOut) ProtoMessage() {}
+
+func (x *SitOut) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_service_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SitOut.ProtoReflect.Descriptor instead.
+func (*SitOut) Descriptor() ([]byte, []int) {
+	return file_proto_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SitOut) GetNextBigBlind() bool {
+	if x != nil {
+		return x.NextBigBlind
+	}
+	return false
+}
+
+type SitIn struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	WaitForBigBlind bool                   `protobuf:"varint,1,opt,name=wait_for_big_blind,json=waitForBigBlind,proto3" json:"wait_for_big_blind,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SitIn) Reset() {
+	*x = SitIn{}
+	mi := &file_proto_service_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SitIn) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SitIn) ProtoMessage() {}
+
+func (x *SitIn) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_service_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SitIn.ProtoReflect.Descriptor instead.
+func (*SitIn) Descriptor() ([]byte, []int) {
+	return file_proto_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SitIn) GetWaitForBigBlind() bool {
+	if x != nil {
+		return x.WaitForBigBlind
+	}
+	return false
+}
+
+type ChipsAdded struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Amount int64                  `protobuf:"varint,1,opt,name=amount,proto3" json:"amount,omitempty"`
+	// reason is BUY_IN, REBUY or TOP_UP.
+	Reason        string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChipsAdded) Reset() {
+	*x = ChipsAdded{}
+	mi := &file_proto_service_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChipsAdded) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChipsAdded) ProtoMessage() {}
+
+func (x *ChipsAdded) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_service_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChipsAdded.ProtoReflect.Descriptor instead.
+func (*ChipsAdded) Descriptor() ([]byte, []int) {
+	return file_proto_service_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ChipsAdded) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *ChipsAdded) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// PreFlop starts a hand dealt from the deck encrypted by all players.
+type PreFlop struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Deck          [][]byte               `protobuf:"bytes,1,rep,name=deck,proto3" json:"deck,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PreFlop) Reset() {
+	*x = PreFlop{}
+	mi := &file_proto_service_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PreFlop) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PreFlop) ProtoMessage() {}
+
+func (x *PreFlop) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_service_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PreFlop.ProtoReflect.Descriptor instead.
+func (*PreFlop) Descriptor() ([]byte, []int) {
+	return file_proto_service_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *PreFlop) GetDeck() [][]byte {
+	if x != nil {
+		return x.Deck
+	}
+	return nil
+}
+
+type PlayerAction struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	CurrentGameStatus GameStatus             `protobuf:"varint,1,opt,name=current_game_status,json=currentGameStatus,proto3,enum=GameStatus" json:"current_game_status,omitempty"`
+	Action            Action                 `protobuf:"varint,2,opt,name=action,proto3,enum=Action" json:"action,omitempty"`
+	Value             int64                  `protobuf:"varint,3,opt,name=value,proto3" json:"value,omitempty"`
+	TimedOut          bool                   `protobuf:"varint,4,opt,name=timed_out,json=timedOut,proto3" json:"timed_out,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *PlayerAction) Reset() {
+	*x = PlayerAction{}
+	mi := &file_proto_service_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlayerAction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayerAction) ProtoMessage() {}
+
+func (x *PlayerAction) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_service_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayerAction.ProtoReflect.Descriptor instead.
+func (*PlayerAction) Descriptor() ([]byte, []int) {
+	return file_proto_service_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PlayerAction) GetCurrentGameStatus() GameStatus {
+	if x != nil {
+		return x.CurrentGameStatus
+	}
+	return GameStatus_GAME_STATUS_CONNECTED
+}
+
+func (x *PlayerAction) GetAction() Action {
+	if x != nil {
+		return x.Action
+	}
+	return Action_ACTION_NONE
+}
+
+func (x *PlayerAction) GetValue() int64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *PlayerAction) GetTimedOut() bool {
+	if x != nil {
+		return x.TimedOut
+	}
+	return false
+}
+
+type ShowHand struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Muck          bool                   `protobuf:"varint,1,opt,name=muck,proto3" json:"muck,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShowHand) Reset() {
+	*x = ShowHand{}
+	mi := &file_proto_service_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShowHand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShowHand) ProtoMessage() {}
+
+func (x *ShowHand) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_service_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShowHand.ProtoReflect.Descriptor instead.
+func (*ShowHand) Descriptor() ([]byte, []int) {
+	return file_proto_service_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ShowHand) GetMuck() bool {
+	if x != nil {
+		return x.Muck
+	}
+	return false
+}
+
+type Dealer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	HandNumber    int64                  `protobuf:"varint,1,opt,name=hand_number,json=handNumber,proto3" json:"hand_number,omitempty"`
+	Dealer        string                 `protobuf:"bytes,2,opt,name=dealer,proto3" json:"dealer,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Dealer) Reset() {
+	*x = Dealer{}
+	mi := &file_proto_service_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Dealer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Dealer) ProtoMessage() {}
+
+func (x *Dealer) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_service_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Dealer.ProtoReflect.Descriptor instead.
+func (*Dealer) Descriptor() ([]byte, []int) {
+	return file_proto_service_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Dealer) GetHandNumber() int64 {
+	if x != nil {
+		return x.HandNumber
+	}
+	return 0
+}
+
+func (x *Dealer) GetDealer() string {
+	if x != nil {
+		return x.Dealer
+	}
+	return ""
+}
+
+// CardKeys reveals keys of the sender for cards at positions in the deck.
+type CardKeys struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	HandNumber    int64                  `protobuf:"varint,1,opt,name=hand_number,json=handNumber,proto3" json:"hand_number,omitempty"`
+	Keys          map[int32][]byte       `protobuf:"bytes,2,rep,name=keys,proto3" json:"keys,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the proto package it is being compiled against.
-// A compilation error at this line likely means your copy of the
-// proto package needs to be updated.
-const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+func (x *CardKeys) Reset() {
+	*x = CardKeys{}
+	mi := &file_proto_service_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CardKeys) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CardKeys) ProtoMessage() {}
+
+func (x *CardKeys) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_service_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CardKeys.ProtoReflect.Descriptor instead.
+func (*CardKeys) Descriptor() ([]byte, []int) {
+	return file_proto_service_proto_rawDescGZIP(), []int{12}
+}
 
-func init() { proto.RegisterFile("proto/service.proto", fileDescriptor_c33392ef2c1961ba) }
+func (x *CardKeys) GetHandNumber() int64 {
+	if x != nil {
+		return x.HandNumber
+	}
+	return 0
+}
+
+func (x *CardKeys) GetKeys() map[int32][]byte {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+type PlayerDisconnected struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Addr          string                 `protobuf:"bytes,1,opt,name=addr,proto3" json:"addr,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlayerDisconnected) Reset() {
+	*x = PlayerDisconnected{}
+	mi := &file_proto_service_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlayerDisconnected) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayerDisconnected) ProtoMessage() {}
+
+func (x *PlayerDisconnected) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_service_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayerDisconnected.ProtoReflect.Descriptor instead.
+func (*PlayerDisconnected) Descriptor() ([]byte, []int) {
+	return file_proto_service_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *PlayerDisconnected) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+type HandAction struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Addr          string                 `protobuf:"bytes,1,opt,name=addr,proto3" json:"addr,omitempty"`
+	Action        Action                 `protobuf:"varint,2,opt,name=action,proto3,enum=Action" json:"action,omitempty"`
+	Amount        int64                  `protobuf:"varint,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	Show          bool                   `protobuf:"varint,4,opt,name=show,proto3" json:"show,omitempty"`
+	Muck          bool                   `protobuf:"varint,5,opt,name=muck,proto3" json:"muck,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HandAction) Reset() {
+	*x = HandAction{}
+	mi := &file_proto_service_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HandAction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandAction) ProtoMessage() {}
+
+func (x *HandAction) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_service_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HandAction.ProtoReflect.Descriptor instead.
+func (*HandAction) Descriptor() ([]byte, []int) {
+	return file_proto_service_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *HandAction) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *HandAction) GetAction() Action {
+	if x != nil {
+		return x.Action
+	}
+	return Action_ACTION_NONE
+}
+
+func (x *HandAction) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *HandAction) GetShow() bool {
+	if x != nil {
+		return x.Show
+	}
+	return false
+}
+
+func (x *HandAction) GetMuck() bool {
+	if x != nil {
+		return x.Muck
+	}
+	return false
+}
+
+// StateSync catches up a player that reconnected.
+type StateSync struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seats         map[string]int32       `protobuf:"bytes,1,rep,name=seats,proto3" json:"seats,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	Status        GameStatus             `protobuf:"varint,2,opt,name=status,proto3,enum=GameStatus" json:"status,omitempty"`
+	HandNumber    int64                  `protobuf:"varint,3,opt,name=hand_number,json=handNumber,proto3" json:"hand_number,omitempty"`
+	Actions       []*HandAction          `protobuf:"bytes,4,rep,name=actions,proto3" json:"actions,omitempty"`
+	Keys          map[int32][]byte       `protobuf:"bytes,5,rep,name=keys,proto3" json:"keys,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StateSync) Reset() {
+	*x = StateSync{}
+	mi := &file_proto_service_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StateSync) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StateSync) ProtoMessage() {}
+
+func (x *StateSync) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_service_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StateSync.ProtoReflect.Descriptor instead.
+func (*StateSync) Descriptor() ([]byte, []int) {
+	return file_proto_service_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *StateSync) GetSeats() map[string]int32 {
+	if x != nil {
+		return x.Seats
+	}
+	return nil
+}
+
+func (x *StateSync) GetStatus() GameStatus {
+	if x != nil {
+		return x.Status
+	}
+	return GameStatus_GAME_STATUS_CONNECTED
+}
+
+func (x *StateSync) GetHandNumber() int64 {
+	if x != nil {
+		return x.HandNumber
+	}
+	return 0
+}
+
+func (x *StateSync) GetActions() []*HandAction {
+	if x != nil {
+		return x.Actions
+	}
+	return nil
+}
+
+func (x *StateSync) GetKeys() map[int32][]byte {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+type TimerStarted struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Addr          string                 `protobuf:"bytes,1,opt,name=addr,proto3" json:"addr,omitempty"`
+	Started       *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=started,proto3" json:"started,omitempty"`
+	Timeout       *durationpb.Duration   `protobuf:"bytes,3,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	TimeBank      *durationpb.Duration   `protobuf:"bytes,4,opt,name=time_bank,json=timeBank,proto3" json:"time_bank,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TimerStarted) Reset() {
+	*x = TimerStarted{}
+	mi := &file_proto_service_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TimerStarted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimerStarted) ProtoMessage() {}
+
+func (x *TimerStarted) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_service_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TimerStarted.ProtoReflect.Descriptor instead.
+func (*TimerStarted) Descriptor() ([]byte, []int) {
+	return file_proto_service_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *TimerStarted) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *TimerStarted) GetStarted() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Started
+	}
+	return nil
+}
+
+func (x *TimerStarted) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+func (x *TimerStarted) GetTimeBank() *durationpb.Duration {
+	if x != nil {
+		return x.TimeBank
+	}
+	return nil
+}
+
+type TimerStopped struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Addr          string                 `protobuf:"bytes,1,opt,name=addr,proto3" json:"addr,omitempty"`
+	TimeBankUsed  *durationpb.Duration   `protobuf:"bytes,2,opt,name=time_bank_used,json=timeBankUsed,proto3" json:"time_bank_used,omitempty"`
+	TimedOut      bool                   `protobuf:"varint,3,opt,name=timed_out,json=timedOut,proto3" json:"timed_out,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TimerStopped) Reset() {
+	*x = TimerStopped{}
+	mi := &file_proto_service_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TimerStopped) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimerStopped) ProtoMessage() {}
+
+func (x *TimerStopped) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_service_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TimerStopped.ProtoReflect.Descriptor instead.
+func (*TimerStopped) Descriptor() ([]byte, []int) {
+	return file_proto_service_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *TimerStopped) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *TimerStopped) GetTimeBankUsed() *durationpb.Duration {
+	if x != nil {
+		return x.TimeBankUsed
+	}
+	return nil
+}
+
+func (x *TimerStopped) GetTimedOut() bool {
+	if x != nil {
+		return x.TimedOut
+	}
+	return false
+}
+
+type DealProposed struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DealProposed) Reset() {
+	*x = DealProposed{}
+	mi := &file_proto_service_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DealProposed) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DealProposed) ProtoMessage() {}
+
+func (x *DealProposed) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_service_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DealProposed.ProtoReflect.Descriptor instead.
+func (*DealProposed) Descriptor() ([]byte, []int) {
+	return file_proto_service_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *DealProposed) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+type DealAnswer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Accept        bool                   `protobuf:"varint,1,opt,name=accept,proto3" json:"accept,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DealAnswer) Reset() {
+	*x = DealAnswer{}
+	mi := &file_proto_service_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DealAnswer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DealAnswer) ProtoMessage() {}
+
+func (x *DealAnswer) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_service_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DealAnswer.ProtoReflect.Descriptor instead.
+func (*DealAnswer) Descriptor() ([]byte, []int) {
+	return file_proto_service_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *DealAnswer) GetAccept() bool {
+	if x != nil {
+		return x.Accept
+	}
+	return false
+}
+
+var File_proto_service_proto protoreflect.FileDescriptor
+
+var file_proto_service_proto_rawDesc = string([]byte{
+	0x0a, 0x13, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x9a, 0x07, 0x0a, 0x08, 0x45, 0x6e, 0x76, 0x65, 0x6c,
+	0x6f, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61,
+	0x74, 0x75, 0x72, 0x65, 0x18, 0x12, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e,
+	0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x6e, 0x64, 0x18, 0x13, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x04, 0x68, 0x61, 0x6e, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x65, 0x71,
+	0x18, 0x14, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x73, 0x65, 0x71, 0x12, 0x2a, 0x0a, 0x09, 0x68,
+	0x61, 0x6e, 0x64, 0x73, 0x68, 0x61, 0x6b, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a,
+	0x2e, 0x48, 0x61, 0x6e, 0x64, 0x73, 0x68, 0x61, 0x6b, 0x65, 0x48, 0x00, 0x52, 0x09, 0x68, 0x61,
+	0x6e, 0x64, 0x73, 0x68, 0x61, 0x6b, 0x65, 0x12, 0x28, 0x0a, 0x09, 0x70, 0x65, 0x65, 0x72, 0x5f,
+	0x6c, 0x69, 0x73, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x50, 0x65, 0x65,
+	0x72, 0x4c, 0x69, 0x73, 0x74, 0x48, 0x00, 0x52, 0x08, 0x70, 0x65, 0x65, 0x72, 0x4c, 0x69, 0x73,
+	0x74, 0x12, 0x25, 0x0a, 0x08, 0x65, 0x6e, 0x63, 0x5f, 0x64, 0x65, 0x63, 0x6b, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x45, 0x6e, 0x63, 0x44, 0x65, 0x63, 0x6b, 0x48, 0x00, 0x52,
+	0x07, 0x65, 0x6e, 0x63, 0x44, 0x65, 0x63, 0x6b, 0x12, 0x1e, 0x0a, 0x05, 0x72, 0x65, 0x61, 0x64,
+	0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x79, 0x48,
+	0x00, 0x52, 0x05, 0x72, 0x65, 0x61, 0x64, 0x79, 0x12, 0x22, 0x0a, 0x07, 0x73, 0x69, 0x74, 0x5f,
+	0x6f, 0x75, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x07, 0x2e, 0x53, 0x69, 0x74, 0x4f,
+	0x75, 0x74, 0x48, 0x00, 0x52, 0x06, 0x73, 0x69, 0x74, 0x4f, 0x75, 0x74, 0x12, 0x1f, 0x0a, 0x06,
+	0x73, 0x69, 0x74, 0x5f, 0x69, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x53,
+	0x69, 0x74, 0x49, 0x6e, 0x48, 0x00, 0x52, 0x05, 0x73, 0x69, 0x74, 0x49, 0x6e, 0x12, 0x2e, 0x0a,
+	0x0b, 0x63, 0x68, 0x69, 0x70, 0x73, 0x5f, 0x61, 0x64, 0x64, 0x65, 0x64, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x43, 0x68, 0x69, 0x70, 0x73, 0x41, 0x64, 0x64, 0x65, 0x64, 0x48,
+	0x00, 0x52, 0x0a, 0x63, 0x68, 0x69, 0x70, 0x73, 0x41, 0x64, 0x64, 0x65, 0x64, 0x12, 0x25, 0x0a,
+	0x08, 0x70, 0x72, 0x65, 0x5f, 0x66, 0x6c, 0x6f, 0x70, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x08, 0x2e, 0x50, 0x72, 0x65, 0x46, 0x6c, 0x6f, 0x70, 0x48, 0x00, 0x52, 0x07, 0x70, 0x72, 0x65,
+	0x46, 0x6c, 0x6f, 0x70, 0x12, 0x34, 0x0a, 0x0d, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x5f, 0x61,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x50, 0x6c,
+	0x61, 0x79, 0x65, 0x72, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52, 0x0c, 0x70, 0x6c,
+	0x61, 0x79, 0x65, 0x72, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x28, 0x0a, 0x09, 0x73, 0x68,
+	0x6f, 0x77, 0x5f, 0x68, 0x61, 0x6e, 0x64, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x09, 0x2e,
+	0x53, 0x68, 0x6f, 0x77, 0x48, 0x61, 0x6e, 0x64, 0x48, 0x00, 0x52, 0x08, 0x73, 0x68, 0x6f, 0x77,
+	0x48, 0x61, 0x6e, 0x64, 0x12, 0x21, 0x0a, 0x06, 0x64, 0x65, 0x61, 0x6c, 0x65, 0x72, 0x18, 0x0c,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x07, 0x2e, 0x44, 0x65, 0x61, 0x6c, 0x65, 0x72, 0x48, 0x00, 0x52,
+	0x06, 0x64, 0x65, 0x61, 0x6c, 0x65, 0x72, 0x12, 0x28, 0x0a, 0x09, 0x63, 0x61, 0x72, 0x64, 0x5f,
+	0x6b, 0x65, 0x79, 0x73, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x43, 0x61, 0x72,
+	0x64, 0x4b, 0x65, 0x79, 0x73, 0x48, 0x00, 0x52, 0x08, 0x63, 0x61, 0x72, 0x64, 0x4b, 0x65, 0x79,
+	0x73, 0x12, 0x46, 0x0a, 0x13, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x5f, 0x64, 0x69, 0x73, 0x63,
+	0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65, 0x64, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13,
+	0x2e, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x44, 0x69, 0x73, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63,
+	0x74, 0x65, 0x64, 0x48, 0x00, 0x52, 0x12, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x44, 0x69, 0x73,
+	0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65, 0x64, 0x12, 0x2b, 0x0a, 0x0a, 0x73, 0x74, 0x61,
+	0x74, 0x65, 0x5f, 0x73, 0x79, 0x6e, 0x63, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x53, 0x79, 0x6e, 0x63, 0x48, 0x00, 0x52, 0x09, 0x73, 0x74, 0x61,
+	0x74, 0x65, 0x53, 0x79, 0x6e, 0x63, 0x12, 0x34, 0x0a, 0x0d, 0x74, 0x69, 0x6d, 0x65, 0x72, 0x5f,
+	0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x72, 0x53, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x48, 0x00, 0x52, 0x0c,
+	0x74, 0x69, 0x6d, 0x65, 0x72, 0x53, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x12, 0x34, 0x0a, 0x0d,
+	0x74, 0x69, 0x6d, 0x65, 0x72, 0x5f, 0x73, 0x74, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x18, 0x11, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x72, 0x53, 0x74, 0x6f, 0x70, 0x70,
+	0x65, 0x64, 0x48, 0x00, 0x52, 0x0c, 0x74, 0x69, 0x6d, 0x65, 0x72, 0x53, 0x74, 0x6f, 0x70, 0x70,
+	0x65, 0x64, 0x12, 0x34, 0x0a, 0x0d, 0x64, 0x65, 0x61, 0x6c, 0x5f, 0x70, 0x72, 0x6f, 0x70, 0x6f,
+	0x73, 0x65, 0x64, 0x18, 0x15, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x44, 0x65, 0x61, 0x6c,
+	0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65, 0x64, 0x48, 0x00, 0x52, 0x0c, 0x64, 0x65, 0x61, 0x6c,
+	0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65, 0x64, 0x12, 0x2e, 0x0a, 0x0b, 0x64, 0x65, 0x61, 0x6c,
+	0x5f, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x18, 0x16, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e,
+	0x44, 0x65, 0x61, 0x6c, 0x41, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x48, 0x00, 0x52, 0x0a, 0x64, 0x65,
+	0x61, 0x6c, 0x41, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x42, 0x09, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c,
+	0x6f, 0x61, 0x64, 0x22, 0xa5, 0x01, 0x0a, 0x09, 0x48, 0x61, 0x6e, 0x64, 0x73, 0x68, 0x61, 0x6b,
+	0x65, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x2f, 0x0a, 0x0c, 0x67,
+	0x61, 0x6d, 0x65, 0x5f, 0x76, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x0c, 0x2e, 0x47, 0x61, 0x6d, 0x65, 0x56, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x74, 0x52,
+	0x0b, 0x67, 0x61, 0x6d, 0x65, 0x56, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x74, 0x12, 0x2c, 0x0a, 0x0b,
+	0x67, 0x61, 0x6d, 0x65, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x0b, 0x2e, 0x47, 0x61, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x0a,
+	0x67, 0x61, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x6c, 0x69,
+	0x73, 0x74, 0x65, 0x6e, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0a, 0x6c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x41, 0x64, 0x64, 0x72, 0x22, 0x20, 0x0a, 0x08, 0x50,
+	0x65, 0x65, 0x72, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x65, 0x65, 0x72, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x70, 0x65, 0x65, 0x72, 0x73, 0x22, 0x35, 0x0a,
+	0x07, 0x45, 0x6e, 0x63, 0x44, 0x65, 0x63, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x65, 0x63, 0x6b,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x65, 0x63, 0x6b, 0x12, 0x16, 0x0a, 0x06,
+	0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x6c, 0x6f,
+	0x63, 0x6b, 0x65, 0x64, 0x22, 0x07, 0x0a, 0x05, 0x52, 0x65, 0x61, 0x64, 0x79, 0x22, 0x2e, 0x0a,
+	0x06, 0x53, 0x69, 0x74, 0x4f, 0x75, 0x74, 0x12, 0x24, 0x0a, 0x0e, 0x6e, 0x65, 0x78, 0x74, 0x5f,
+	0x62, 0x69, 0x67, 0x5f, 0x62, 0x6c, 0x69, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x0c, 0x6e, 0x65, 0x78, 0x74, 0x42, 0x69, 0x67, 0x42, 0x6c, 0x69, 0x6e, 0x64, 0x22, 0x34, 0x0a,
+	0x05, 0x53, 0x69, 0x74, 0x49, 0x6e, 0x12, 0x2b, 0x0a, 0x12, 0x77, 0x61, 0x69, 0x74, 0x5f, 0x66,
+	0x6f, 0x72, 0x5f, 0x62, 0x69, 0x67, 0x5f, 0x62, 0x6c, 0x69, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0f, 0x77, 0x61, 0x69, 0x74, 0x46, 0x6f, 0x72, 0x42, 0x69, 0x67, 0x42, 0x6c,
+	0x69, 0x6e, 0x64, 0x22, 0x3c, 0x0a, 0x0a, 0x43, 0x68, 0x69, 0x70, 0x73, 0x41, 0x64, 0x64, 0x65,
+	0x64, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61,
+	0x73, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f,
+	0x6e, 0x22, 0x1d, 0x0a, 0x07, 0x50, 0x72, 0x65, 0x46, 0x6c, 0x6f, 0x70, 0x12, 0x12, 0x0a, 0x04,
+	0x64, 0x65, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x65, 0x63, 0x6b,
+	0x22, 0x9f, 0x01, 0x0a, 0x0c, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x41, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x3b, 0x0a, 0x13, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x67, 0x61, 0x6d,
+	0x65, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0b,
+	0x2e, 0x47, 0x61, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x11, 0x63, 0x75, 0x72,
+	0x72, 0x65, 0x6e, 0x74, 0x47, 0x61, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1f,
+	0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x07,
+	0x2e, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x64, 0x5f, 0x6f,
+	0x75, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x64, 0x4f,
+	0x75, 0x74, 0x22, 0x1e, 0x0a, 0x08, 0x53, 0x68, 0x6f, 0x77, 0x48, 0x61, 0x6e, 0x64, 0x12, 0x12,
+	0x0a, 0x04, 0x6d, 0x75, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x6d, 0x75,
+	0x63, 0x6b, 0x22, 0x41, 0x0a, 0x06, 0x44, 0x65, 0x61, 0x6c, 0x65, 0x72, 0x12, 0x1f, 0x0a, 0x0b,
+	0x68, 0x61, 0x6e, 0x64, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0a, 0x68, 0x61, 0x6e, 0x64, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x16, 0x0a,
+	0x06, 0x64, 0x65, 0x61, 0x6c, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64,
+	0x65, 0x61, 0x6c, 0x65, 0x72, 0x22, 0x8d, 0x01, 0x0a, 0x08, 0x43, 0x61, 0x72, 0x64, 0x4b, 0x65,
+	0x79, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x68, 0x61, 0x6e, 0x64, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65,
+	0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x68, 0x61, 0x6e, 0x64, 0x4e, 0x75, 0x6d,
+	0x62, 0x65, 0x72, 0x12, 0x27, 0x0a, 0x04, 0x6b, 0x65, 0x79, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x13, 0x2e, 0x43, 0x61, 0x72, 0x64, 0x4b, 0x65, 0x79, 0x73, 0x2e, 0x4b, 0x65, 0x79,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x04, 0x6b, 0x65, 0x79, 0x73, 0x1a, 0x37, 0x0a, 0x09,
+	0x4b, 0x65, 0x79, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x28, 0x0a, 0x12, 0x50, 0x6c, 0x61, 0x79, 0x65, 0x72, 0x44,
+	0x69, 0x73, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x61,
+	0x64, 0x64, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x61, 0x64, 0x64, 0x72, 0x22,
+	0x81, 0x01, 0x0a, 0x0a, 0x48, 0x61, 0x6e, 0x64, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12,
+	0x0a, 0x04, 0x61, 0x64, 0x64, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x61, 0x64,
+	0x64, 0x72, 0x12, 0x1f, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x07, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x61, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73,
+	0x68, 0x6f, 0x77, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x73, 0x68, 0x6f, 0x77, 0x12,
+	0x12, 0x0a, 0x04, 0x6d, 0x75, 0x63, 0x6b, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x6d,
+	0x75, 0x63, 0x6b, 0x22, 0xc2, 0x02, 0x0a, 0x09, 0x53, 0x74, 0x61, 0x74, 0x65, 0x53, 0x79, 0x6e,
+	0x63, 0x12, 0x2b, 0x0a, 0x05, 0x73, 0x65, 0x61, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x15, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x53, 0x79, 0x6e, 0x63, 0x2e, 0x53, 0x65, 0x61,
+	0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x05, 0x73, 0x65, 0x61, 0x74, 0x73, 0x12, 0x23,
+	0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0b,
+	0x2e, 0x47, 0x61, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x68, 0x61, 0x6e, 0x64, 0x5f, 0x6e, 0x75, 0x6d, 0x62,
+	0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x68, 0x61, 0x6e, 0x64, 0x4e, 0x75,
+	0x6d, 0x62, 0x65, 0x72, 0x12, 0x25, 0x0a, 0x07, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x48, 0x61, 0x6e, 0x64, 0x41, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x07, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x28, 0x0a, 0x04, 0x6b,
+	0x65, 0x79, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x53, 0x79, 0x6e, 0x63, 0x2e, 0x4b, 0x65, 0x79, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
+	0x04, 0x6b, 0x65, 0x79, 0x73, 0x1a, 0x38, 0x0a, 0x0a, 0x53, 0x65, 0x61, 0x74, 0x73, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a,
+	0x37, 0x0a, 0x09, 0x4b, 0x65, 0x79, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xc5, 0x01, 0x0a, 0x0c, 0x54, 0x69, 0x6d,
+	0x65, 0x72, 0x53, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x64, 0x64,
+	0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x61, 0x64, 0x64, 0x72, 0x12, 0x34, 0x0a,
+	0x07, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x07, 0x73, 0x74, 0x61, 0x72,
+	0x74, 0x65, 0x64, 0x12, 0x33, 0x0a, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x36, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65,
+	0x5f, 0x62, 0x61, 0x6e, 0x6b, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x42, 0x61, 0x6e, 0x6b,
+	0x22, 0x80, 0x01, 0x0a, 0x0c, 0x54, 0x69, 0x6d, 0x65, 0x72, 0x53, 0x74, 0x6f, 0x70, 0x70, 0x65,
+	0x64, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x64, 0x64, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x61, 0x64, 0x64, 0x72, 0x12, 0x3f, 0x0a, 0x0e, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x62, 0x61,
+	0x6e, 0x6b, 0x5f, 0x75, 0x73, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x74, 0x69, 0x6d, 0x65, 0x42, 0x61,
+	0x6e, 0x6b, 0x55, 0x73, 0x65, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x64, 0x5f,
+	0x6f, 0x75, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x64,
+	0x4f, 0x75, 0x74, 0x22, 0x22, 0x0a, 0x0c, 0x44, 0x65, 0x61, 0x6c, 0x50, 0x72, 0x6f, 0x70, 0x6f,
+	0x73, 0x65, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x22, 0x24, 0x0a, 0x0a, 0x44, 0x65, 0x61, 0x6c, 0x41,
+	0x6e, 0x73, 0x77, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x2a, 0x44, 0x0a,
+	0x0b, 0x47, 0x61, 0x6d, 0x65, 0x56, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x74, 0x12, 0x1d, 0x0a, 0x19,
+	0x47, 0x41, 0x4d, 0x45, 0x5f, 0x56, 0x41, 0x52, 0x49, 0x41, 0x4e, 0x54, 0x5f, 0x54, 0x45, 0x58,
+	0x41, 0x53, 0x5f, 0x48, 0x4f, 0x4c, 0x44, 0x45, 0x4d, 0x10, 0x00, 0x12, 0x16, 0x0a, 0x12, 0x47,
+	0x41, 0x4d, 0x45, 0x5f, 0x56, 0x41, 0x52, 0x49, 0x41, 0x4e, 0x54, 0x5f, 0x4f, 0x54, 0x48, 0x45,
+	0x52, 0x10, 0x01, 0x2a, 0xd5, 0x01, 0x0a, 0x0a, 0x47, 0x61, 0x6d, 0x65, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x12, 0x19, 0x0a, 0x15, 0x47, 0x41, 0x4d, 0x45, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55,
+	0x53, 0x5f, 0x43, 0x4f, 0x4e, 0x4e, 0x45, 0x43, 0x54, 0x45, 0x44, 0x10, 0x00, 0x12, 0x1c, 0x0a,
+	0x18, 0x47, 0x41, 0x4d, 0x45, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x50, 0x4c, 0x41,
+	0x59, 0x45, 0x52, 0x5f, 0x52, 0x45, 0x41, 0x44, 0x59, 0x10, 0x01, 0x12, 0x17, 0x0a, 0x13, 0x47,
+	0x41, 0x4d, 0x45, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x44, 0x45, 0x41, 0x4c, 0x49,
+	0x4e, 0x47, 0x10, 0x02, 0x12, 0x18, 0x0a, 0x14, 0x47, 0x41, 0x4d, 0x45, 0x5f, 0x53, 0x54, 0x41,
+	0x54, 0x55, 0x53, 0x5f, 0x50, 0x52, 0x45, 0x5f, 0x46, 0x4c, 0x4f, 0x50, 0x10, 0x03, 0x12, 0x14,
+	0x0a, 0x10, 0x47, 0x41, 0x4d, 0x45, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x46, 0x4c,
+	0x4f, 0x50, 0x10, 0x04, 0x12, 0x14, 0x0a, 0x10, 0x47, 0x41, 0x4d, 0x45, 0x5f, 0x53, 0x54, 0x41,
+	0x54, 0x55, 0x53, 0x5f, 0x54, 0x55, 0x52, 0x4e, 0x10, 0x05, 0x12, 0x15, 0x0a, 0x11, 0x47, 0x41,
+	0x4d, 0x45, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x52, 0x49, 0x56, 0x45, 0x52, 0x10,
+	0x06, 0x12, 0x18, 0x0a, 0x14, 0x47, 0x41, 0x4d, 0x45, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53,
+	0x5f, 0x53, 0x48, 0x4f, 0x57, 0x44, 0x4f, 0x57, 0x4e, 0x10, 0x07, 0x2a, 0x6f, 0x0a, 0x06, 0x41,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0f, 0x0a, 0x0b, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f,
+	0x4e, 0x4f, 0x4e, 0x45, 0x10, 0x00, 0x12, 0x0f, 0x0a, 0x0b, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e,
+	0x5f, 0x46, 0x4f, 0x4c, 0x44, 0x10, 0x01, 0x12, 0x10, 0x0a, 0x0c, 0x41, 0x43, 0x54, 0x49, 0x4f,
+	0x4e, 0x5f, 0x43, 0x48, 0x45, 0x43, 0x4b, 0x10, 0x02, 0x12, 0x0f, 0x0a, 0x0b, 0x41, 0x43, 0x54,
+	0x49, 0x4f, 0x4e, 0x5f, 0x43, 0x41, 0x4c, 0x4c, 0x10, 0x03, 0x12, 0x0e, 0x0a, 0x0a, 0x41, 0x43,
+	0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x42, 0x45, 0x54, 0x10, 0x04, 0x12, 0x10, 0x0a, 0x0c, 0x41, 0x43,
+	0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x52, 0x41, 0x49, 0x53, 0x45, 0x10, 0x05, 0x32, 0x32, 0x0a, 0x0c,
+	0x47, 0x6f, 0x73, 0x73, 0x69, 0x70, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x12, 0x22, 0x0a, 0x06,
+	0x47, 0x6f, 0x73, 0x73, 0x69, 0x70, 0x12, 0x09, 0x2e, 0x45, 0x6e, 0x76, 0x65, 0x6c, 0x6f, 0x70,
+	0x65, 0x1a, 0x09, 0x2e, 0x45, 0x6e, 0x76, 0x65, 0x6c, 0x6f, 0x70, 0x65, 0x28, 0x01, 0x30, 0x01,
+	0x42, 0x21, 0x5a, 0x1f, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6b,
+	0x6f, 0x73, 0x68, 0x69, 0x71, 0x2f, 0x67, 0x67, 0x70, 0x6f, 0x6b, 0x65, 0x72, 0x2f, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+})
+
+var (
+	file_proto_service_proto_rawDescOnce sync.Once
+	file_proto_service_proto_rawDescData []byte
+)
+
+func file_proto_service_proto_rawDescGZIP() []byte {
+	file_proto_service_proto_rawDescOnce.Do(func() {
+		file_proto_service_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_service_proto_rawDesc), len(file_proto_service_proto_rawDesc)))
+	})
+	return file_proto_service_proto_rawDescData
+}
+
+var file_proto_service_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_proto_service_proto_msgTypes = make([]protoimpl.MessageInfo, 23)
+var file_proto_service_proto_goTypes = []any{
+	(GameVariant)(0),              // 0: GameVariant
+	(GameStatus)(0),               // 1: GameStatus
+	(Action)(0),                   // 2: Action
+	(*Envelope)(nil),              // 3: Envelope
+	(*Handshake)(nil),             // 4: Handshake
+	(*PeerList)(nil),              // 5: PeerList
+	(*EncDeck)(nil),               // 6: EncDeck
+	(*Ready)(nil),                 // 7: Ready
+	(*SitOut)(nil),                // 8: SitOut
+	(*SitIn)(nil),                 // 9: SitIn
+	(*ChipsAdded)(nil),            // 10: ChipsAdded
+	(*PreFlop)(nil),               // 11: PreFlop
+	(*PlayerAction)(nil),          // 12: PlayerAction
+	(*ShowHand)(nil),              // 13: ShowHand
+	(*Dealer)(nil),                // 14: Dealer
+	(*CardKeys)(nil),              // 15: CardKeys
+	(*PlayerDisconnected)(nil),    // 16: PlayerDisconnected
+	(*HandAction)(nil),            // 17: HandAction
+	(*StateSync)(nil),             // 18: StateSync
+	(*TimerStarted)(nil),          // 19: TimerStarted
+	(*TimerStopped)(nil),          // 20: TimerStopped
+	(*DealProposed)(nil),          // 21: DealProposed
+	(*DealAnswer)(nil),            // 22: DealAnswer
+	nil,                           // 23: CardKeys.KeysEntry
+	nil,                           // 24: StateSync.SeatsEntry
+	nil,                           // 25: StateSync.KeysEntry
+	(*timestamppb.Timestamp)(nil), // 26: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),   // 27: google.protobuf.Duration
+}
+var file_proto_service_proto_depIdxs = []int32{
+	4,  // 0: Envelope.handshake:type_name -> Handshake
+	5,  // 1: Envelope.peer_list:type_name -> PeerList
+	6,  // 2: Envelope.enc_deck:type_name -> EncDeck
+	7,  // 3: Envelope.ready:type_name -> Ready
+	8,  // 4: Envelope.sit_out:type_name -> SitOut
+	9,  // 5: Envelope.sit_in:type_name -> SitIn
+	10, // 6: Envelope.chips_added:type_name -> ChipsAdded
+	11, // 7: Envelope.pre_flop:type_name -> PreFlop
+	12, // 8: Envelope.player_action:type_name -> PlayerAction
+	13, // 9: Envelope.show_hand:type_name -> ShowHand
+	14, // 10: Envelope.dealer:type_name -> Dealer
+	15, // 11: Envelope.card_keys:type_name -> CardKeys
+	16, // 12: Envelope.player_disconnected:type_name -> PlayerDisconnected
+	18, // 13: Envelope.state_sync:type_name -> StateSync
+	19, // 14: Envelope.timer_started:type_name -> TimerStarted
+	20, // 15: Envelope.timer_stopped:type_name -> TimerStopped
+	21, // 16: Envelope.deal_proposed:type_name -> DealProposed
+	22, // 17: Envelope.deal_answer:type_name -> DealAnswer
+	0,  // 18: Handshake.game_variant:type_name -> GameVariant
+	1,  // 19: Handshake.game_status:type_name -> GameStatus
+	1,  // 20: PlayerAction.current_game_status:type_name -> GameStatus
+	2,  // 21: PlayerAction.action:type_name -> Action
+	23, // 22: CardKeys.keys:type_name -> CardKeys.KeysEntry
+	2,  // 23: HandAction.action:type_name -> Action
+	24, // 24: StateSync.seats:type_name -> StateSync.SeatsEntry
+	1,  // 25: StateSync.status:type_name -> GameStatus
+	17, // 26: StateSync.actions:type_name -> HandAction
+	25, // 27: StateSync.keys:type_name -> StateSync.KeysEntry
+	26, // 28: TimerStarted.started:type_name -> google.protobuf.Timestamp
+	27, // 29: TimerStarted.timeout:type_name -> google.protobuf.Duration
+	27, // 30: TimerStarted.time_bank:type_name -> google.protobuf.Duration
+	27, // 31: TimerStopped.time_bank_used:type_name -> google.protobuf.Duration
+	3,  // 32: GossipServer.Gossip:input_type -> Envelope
+	3,  // 33: GossipServer.Gossip:output_type -> Envelope
+	33, // [33:34] is the sub-list for method output_type
+	32, // [32:33] is the sub-list for method input_type
+	32, // [32:32] is the sub-list for extension type_name
+	32, // [32:32] is the sub-list for extension extendee
+	0,  // [0:32] is the sub-list for field type_name
+}
 
-var fileDescriptor_c33392ef2c1961ba = []byte{
-	// 94 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x12, 0x2e, 0x28, 0xca, 0x2f,
-	0xc9, 0xd7, 0x2f, 0x4e, 0x2d, 0x2a, 0xcb, 0x4c, 0x4e, 0xd5, 0x03, 0xf3, 0x8c, 0xf8, 0xb8, 0x78,
-	0xdc, 0xf3, 0x8b, 0x8b, 0x33, 0x0b, 0x82, 0x53, 0x8b, 0xca, 0x52, 0x8b, 0x9c, 0x14, 0xa3, 0xe4,
-	0xd3, 0x33, 0x4b, 0x32, 0x4a, 0x93, 0xf4, 0x92, 0xf3, 0x73, 0xf5, 0x13, 0xf3, 0x4a, 0x32, 0x52,
-	0x72, 0xf5, 0xd3, 0xd3, 0x0b, 0xf2, 0xb3, 0x53, 0x8b, 0xf4, 0xc1, 0x5a, 0x92, 0xd8, 0xc0, 0x94,
-	0x31, 0x20, 0x00, 0x00, 0xff, 0xff, 0x10, 0x92, 0xa5, 0xad, 0x50, 0x00, 0x00, 0x00,
+func init() { file_proto_service_proto_init() }
+func file_proto_service_proto_init() {
+	if File_proto_service_proto != nil {
+		return
+	}
+	file_proto_service_proto_msgTypes[0].OneofWrappers = []any{
+		(*Envelope_Handshake)(nil),
+		(*Envelope_PeerList)(nil),
+		(*Envelope_EncDeck)(nil),
+		(*Envelope_Ready)(nil),
+		(*Envelope_SitOut)(nil),
+		(*Envelope_SitIn)(nil),
+		(*Envelope_ChipsAdded)(nil),
+		(*Envelope_PreFlop)(nil),
+		(*Envelope_PlayerAction)(nil),
+		(*Envelope_ShowHand)(nil),
+		(*Envelope_Dealer)(nil),
+		(*Envelope_CardKeys)(nil),
+		(*Envelope_PlayerDisconnected)(nil),
+		(*Envelope_StateSync)(nil),
+		(*Envelope_TimerStarted)(nil),
+		(*Envelope_TimerStopped)(nil),
+		(*Envelope_DealProposed)(nil),
+		(*Envelope_DealAnswer)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_service_proto_rawDesc), len(file_proto_service_proto_rawDesc)),
+			NumEnums:      3,
+			NumMessages:   23,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_service_proto_goTypes,
+		DependencyIndexes: file_proto_service_proto_depIdxs,
+		EnumInfos:         file_proto_service_proto_enumTypes,
+		MessageInfos:      file_proto_service_proto_msgTypes,
+	}.Build()
+	File_proto_service_proto = out.File
+	file_proto_service_proto_goTypes = nil
+	file_proto_service_proto_depIdxs = nil
 }
@@ -1,24 +1,35 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
 // versions:
-// - protoc-gen-go-grpc v1.2.0
-// - protoc             v3.6.1
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
 // source: proto/service.proto
 
 package proto
 
 import (
+	context "context"
 	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
 )
 
 // This is a compile-time assertion to ensure that this generated file
 // is compatible with the grpc package it is being compiled against.
-// Requires gRPC-Go v1.32.0 or later.
-const _ = grpc.SupportPackageIsVersion7
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	GossipServer_Gossip_FullMethodName = "/GossipServer/Gossip"
+)
 
 // GossipServerClient is the client API for GossipServer service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// GossipServer connects the players of a table. Every peer opens a single
+// stream, the first message on it is the handshake.
 type GossipServerClient interface {
+	Gossip(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[Envelope, Envelope], error)
 }
 
 type gossipServerClient struct {
@@ -29,18 +40,42 @@ func NewGossipServerClient(cc grpc.ClientConnInterface) GossipServerClient {
 	return &gossipServerClient{cc}
 }
 
+func (c *gossipServerClient) Gossip(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[Envelope, Envelope], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GossipServer_ServiceDesc.Streams[0], GossipServer_Gossip_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[Envelope, Envelope]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GossipServer_GossipClient = grpc.BidiStreamingClient[Envelope, Envelope]
+
 // GossipServerServer is the server API for GossipServer service.
 // All implementations must embed UnimplementedGossipServerServer
-// for forward compatibility
+// for forward compatibility.
+//
+// GossipServer connects the players of a table. Every peer opens a single
+// stream, the first message on it is the handshake.
 type GossipServerServer interface {
+	Gossip(grpc.BidiStreamingServer[Envelope, Envelope]) error
 	mustEmbedUnimplementedGossipServerServer()
 }
 
-// UnimplementedGossipServerServer must be embedded to have forward compatible implementations.
-type UnimplementedGossipServerServer struct {
-}
+// UnimplementedGossipServerServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedGossipServerServer struct{}
 
+func (UnimplementedGossipServerServer) Gossip(grpc.BidiStreamingServer[Envelope, Envelope]) error {
+	return status.Errorf(codes.Unimplemented, "method Gossip not implemented")
+}
 func (UnimplementedGossipServerServer) mustEmbedUnimplementedGossipServerServer() {}
+func (UnimplementedGossipServerServer) testEmbeddedByValue()                      {}
 
 // UnsafeGossipServerServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to GossipServerServer will
@@ -50,9 +85,23 @@ type UnsafeGossipServerServer interface {
 }
 
 func RegisterGossipServerServer(s grpc.ServiceRegistrar, srv GossipServerServer) {
+	// If the following call pancis, it indicates UnimplementedGossipServerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
 	s.RegisterService(&GossipServer_ServiceDesc, srv)
 }
 
+func _GossipServer_Gossip_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GossipServerServer).Gossip(&grpc.GenericServerStream[Envelope, Envelope]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GossipServer_GossipServer = grpc.BidiStreamingServer[Envelope, Envelope]
+
 // GossipServer_ServiceDesc is the grpc.ServiceDesc for GossipServer service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -60,6 +109,13 @@ var GossipServer_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "GossipServer",
 	HandlerType: (*GossipServerServer)(nil),
 	Methods:     []grpc.MethodDesc{},
-	Streams:     []grpc.StreamDesc{},
-	Metadata:    "proto/service.proto",
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Gossip",
+			Handler:       _GossipServer_Gossip_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "proto/service.proto",
 }
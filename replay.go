@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/koshiq/ggpoker/p2p"
+)
+
+// runReplay replays the hand record stored in the given JSON file and writes
+// the state after every step to stdout, one JSON object per line. Files in
+// the Open Hand History format are checked hand by hand instead.
+func runReplay(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: ggpoker replay <hand.json>")
+	}
+
+	b, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	if p2p.IsOpenHandHistory(b) {
+		return replayOpenHandHistories(b)
+	}
+
+	rec := &p2p.HandRecord{}
+	if err := json.Unmarshal(b, rec); err != nil {
+		return err
+	}
+
+	result, err := p2p.ReplayHand(rec)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	for _, step := range result.Steps {
+		if err := enc.Encode(step); err != nil {
+			return err
+		}
+	}
+
+	if result.Diverged() {
+		for _, d := range result.Divergences {
+			fmt.Fprintln(os.Stderr, "divergence:", d)
+		}
+		return fmt.Errorf("replay diverged from the recorded hand")
+	}
+
+	return nil
+}
+
+func replayOpenHandHistories(b []byte) error {
+	hands, err := p2p.ParseOpenHandHistories(bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, h := range hands {
+		result, err := p2p.ReplayOpenHandHistory(h)
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "hand %s: %s\n", h.OHH.GameNumber, err)
+			continue
+		}
+		for _, d := range result.Divergences {
+			fmt.Fprintf(os.Stderr, "hand %s: divergence: %s\n", h.OHH.GameNumber, d)
+		}
+		if result.Diverged() {
+			failed++
+		}
+	}
+
+	fmt.Printf("%d hands replayed, %d failed\n", len(hands), failed)
+	if failed > 0 {
+		return fmt.Errorf("%d hands diverged from their history", failed)
+	}
+
+	return nil
+}
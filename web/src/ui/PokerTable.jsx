@@ -13,7 +13,7 @@ const PokerTable = ({ gameState, onAction, playerAddress }) => {
     );
   }
 
-  const { players, communityCards, pot, currentBet, minRaise, currentRound, handNumber } = gameState;
+  const { players, communityCards, pots, currentBet, minRaise, currentRound, handNumber } = gameState;
   const currentPlayer = players[playerAddress];
 
   const handleAction = (action, amount = 0) => {
@@ -24,9 +24,9 @@ const PokerTable = ({ gameState, onAction, playerAddress }) => {
     }
   };
 
-  const canCheck = currentPlayer && currentPlayer.totalBet >= currentBet;
-  const canCall = currentPlayer && currentPlayer.totalBet < currentBet;
-  const callAmount = currentBet - (currentPlayer?.totalBet || 0);
+  const canCheck = currentPlayer && currentPlayer.bet >= currentBet;
+  const canCall = currentPlayer && currentPlayer.bet < currentBet;
+  const callAmount = currentBet - (currentPlayer?.bet || 0);
 
   const renderCard = (card) => {
     if (!card) return null;
@@ -68,7 +68,7 @@ const PokerTable = ({ gameState, onAction, playerAddress }) => {
           {player.isBigBlind && <div className="player-status bb">BB</div>}
         </div>
         
-        {isCurrentPlayer && player.holeCards && (
+        {player.holeCards && player.holeCards.length > 0 && (
           <div className="hole-cards">
             {player.holeCards.map((card, index) => (
               <div key={index} className="hole-card">
@@ -88,7 +88,7 @@ const PokerTable = ({ gameState, onAction, playerAddress }) => {
         <div className="current-round">{currentRound}</div>
         <div className="pot-info">
           <div className="pot-label">Pot:</div>
-          <div className="pot-amount">${pot.reduce((sum, p) => sum + p.Amount, 0)}</div>
+          <div className="pot-amount">${pots.reduce((sum, p) => sum + p.amount, 0)}</div>
         </div>
         {currentBet > 0 && (
           <div className="current-bet">Current Bet: ${currentBet}</div>